@@ -0,0 +1,173 @@
+// Copyright (c) 2024-2025 Thomas Maurice
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package benchmarks
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/thomas-maurice/glua/pkg/glua"
+	"github.com/thomas-maurice/glua/pkg/modules/kubernetes"
+	lua "github.com/yuin/gopher-lua"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// webhookScript: a mutation like the webhook examples, adding an annotation patch
+const webhookScript = `
+	local k8s = require("kubernetes")
+	pod = k8s.init_defaults(pod)
+	pod = k8s.add_annotation(pod, "glua.mutated", "true")
+	table.insert(patches, {
+		op = "add",
+		path = "/metadata/annotations/glua.mutated",
+		value = pod.metadata.annotations["glua.mutated"],
+	})
+`
+
+// webhookPod: the pod sent with every request
+func webhookPod() *corev1.Pod {
+	return &corev1.Pod{
+		TypeMeta: metav1.TypeMeta{Kind: "Pod", APIVersion: "v1"},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "benchmark-pod",
+			Namespace: "default",
+			Labels:    map[string]string{"app": "test"},
+		},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{{Name: "nginx", Image: "nginx:1.21"}},
+		},
+	}
+}
+
+// runWebhookScript: sets the request globals, runs the script and reads the patches back
+func runWebhookScript(L *lua.LState, translator *glua.Translator, pod *corev1.Pod, run func() error) error {
+	podTable, err := translator.ToLua(L, pod)
+	if err != nil {
+		return err
+	}
+	L.SetGlobal("pod", podTable)
+	L.SetGlobal("patches", L.NewTable())
+
+	if err := run(); err != nil {
+		return err
+	}
+
+	var patches []map[string]interface{}
+	if err := translator.FromLua(L, L.GetGlobal("patches"), &patches); err != nil {
+		return err
+	}
+	if len(patches) != 1 {
+		return fmt.Errorf("expected 1 patch, got %d", len(patches))
+	}
+	return nil
+}
+
+// BenchmarkWebhookNewState: benchmarks a request creating its own state and compiling the script,
+// as the webhook examples used to
+func BenchmarkWebhookNewState(b *testing.B) {
+	translator := glua.NewTranslator()
+	pod := webhookPod()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		L := lua.NewState()
+		L.PreloadModule("kubernetes", kubernetes.Loader)
+		err := runWebhookScript(L, translator, pod, func() error {
+			return L.DoString(webhookScript)
+		})
+		L.Close()
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkWebhookStatePool: benchmarks a request borrowing a prepared state and running the
+// precompiled script
+func BenchmarkWebhookStatePool(b *testing.B) {
+	translator := glua.NewTranslator()
+	pod := webhookPod()
+
+	pool, err := glua.NewStatePool(glua.PoolOptions{
+		Modules: map[string]lua.LGFunction{"kubernetes": kubernetes.Loader},
+	})
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer pool.Close()
+
+	script, err := glua.CompileString(webhookScript, "webhook.lua")
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		L, err := pool.Get()
+		if err != nil {
+			b.Fatal(err)
+		}
+		err = runWebhookScript(L, translator, pod, func() error {
+			return glua.DoProto(L, script)
+		})
+		pool.Put(L)
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkWebhookStatePoolParallel: benchmarks concurrent requests sharing a pool
+func BenchmarkWebhookStatePoolParallel(b *testing.B) {
+	translator := glua.NewTranslator()
+	pod := webhookPod()
+
+	pool, err := glua.NewStatePool(glua.PoolOptions{
+		Modules: map[string]lua.LGFunction{"kubernetes": kubernetes.Loader},
+	})
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer pool.Close()
+
+	script, err := glua.CompileString(webhookScript, "webhook.lua")
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			err := pool.Do(func(L *lua.LState) error {
+				return runWebhookScript(L, translator, pod, func() error {
+					return glua.DoProto(L, script)
+				})
+			})
+			if err != nil {
+				b.Error(err)
+			}
+		}
+	})
+}
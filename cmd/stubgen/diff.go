@@ -0,0 +1,103 @@
+// Copyright (c) 2024-2025 Thomas Maurice
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/thomas-maurice/glua/pkg/glua"
+)
+
+// runDiff: compares a registry snapshot against a checked-in baseline.
+// Prints every change and returns 1 if any of them is breaking, 2 on usage or I/O errors.
+//
+// Usage:
+//
+//	stubgen diff -baseline api.snapshot.json -current library/kubernetes.snapshot.json
+func runDiff(args []string) int {
+	flags := flag.NewFlagSet("diff", flag.ContinueOnError)
+	var (
+		baseline = flags.String("baseline", "", "Baseline snapshot file (checked in)")
+		current  = flags.String("current", "", "Snapshot file generated from the current types")
+	)
+
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	if *baseline == "" || *current == "" {
+		fmt.Fprintf(os.Stderr, "Error: diff requires -baseline and -current\n")
+		flags.Usage()
+		return 2
+	}
+
+	oldSnapshot, err := readSnapshot(*baseline)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 2
+	}
+
+	newSnapshot, err := readSnapshot(*current)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 2
+	}
+
+	changes := glua.DiffSnapshots(oldSnapshot, newSnapshot)
+	if len(changes) == 0 {
+		fmt.Println("No changes")
+		return 0
+	}
+
+	breaking := 0
+	for _, change := range changes {
+		if change.Breaking {
+			breaking++
+			fmt.Printf("BREAKING: %s\n", change)
+		} else {
+			fmt.Printf("          %s\n", change)
+		}
+	}
+
+	fmt.Printf("\n%d change(s), %d breaking\n", len(changes), breaking)
+	if breaking > 0 {
+		return 1
+	}
+
+	return 0
+}
+
+// readSnapshot: reads and parses a registry snapshot file
+func readSnapshot(path string) (*glua.RegistrySnapshot, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read snapshot: %w", err)
+	}
+
+	snapshot, err := glua.UnmarshalSnapshot(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	return snapshot, nil
+}
@@ -24,64 +24,384 @@ import (
 	"flag"
 	"fmt"
 	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
 
 	"github.com/thomas-maurice/glua/pkg/stubgen"
 )
 
+// stringList: a flag that can be repeated, collecting its values
+type stringList []string
+
+// String: returns the values separated by commas
+func (l *stringList) String() string {
+	return strings.Join(*l, ",")
+}
+
+// Set: adds a value
+func (l *stringList) Set(value string) error {
+	*l = append(*l, value)
+	return nil
+}
+
 // main: entry point for the stubgen command-line tool
 func main() {
+	// Subcommands
+	if len(os.Args) > 1 && os.Args[1] == "diff" {
+		os.Exit(runDiff(os.Args[2:]))
+	}
+
+	var excludes, globals stringList
+	flag.Var(&excludes, "exclude", "Glob of files or directories to skip, may be repeated (e.g. '**/stubgen', 'pkg/modules/k8sclient')")
+	flag.Var(&globals, "global", "Global injected into scripts, declared in the .luarc.json written by -luarc, may be repeated")
+
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: stubgen [flags] [patterns...]\n\n")
+		fmt.Fprintf(flag.CommandLine.Output(), "Patterns are directories, scanned recursively when ending with /... (e.g. ./pkg/modules/...).\n")
+		fmt.Fprintf(flag.CommandLine.Output(), "Without patterns, -dir is scanned recursively.\n\nFlags:\n")
+		flag.PrintDefaults()
+	}
+
 	var (
-		dir       = flag.String("dir", ".", "Directory to scan for Go module files")
+		dir       = flag.String("dir", ".", "Directory to scan recursively for Go module files, when no pattern is given")
 		output    = flag.String("output", "module_stubs.gen.lua", "Output file for generated Lua stubs")
 		outputDir = flag.String("output-dir", "", "Output directory for per-module stub files (recommended for LSP)")
+		teal      = flag.Bool("teal", false, "Also generate Teal declaration files (<module>.d.tl), requires -output-dir")
+		format    = flag.String("format", "lua", "Output format: lua (LSP stubs), markdown or html (API reference, requires -output-dir)")
+		check     = flag.Bool("check", false, "Check that exported functions match their annotations instead of generating stubs")
+		typecheck = flag.Bool("typecheck", false, "Cross-check annotations against the Go implementations instead of generating stubs")
+		strict    = flag.Bool("strict", false, "Fail when annotations are malformed instead of skipping them with a warning")
+		luarc     = flag.Bool("luarc", false, "Also create or update a LuaLS .luarc.json using the generated stubs")
+		luarcFile = flag.String("luarc-file", ".luarc.json", "Path of the .luarc.json written by -luarc")
+		snapshot  = flag.String("globals-from", "", "Registry snapshot whose globals are declared in the .luarc.json written by -luarc")
+		watch     = flag.Bool("watch", false, "Keep running, regenerating the stubs of the modules whose Go files change")
+		interval  = flag.Duration("interval", 500*time.Millisecond, "How often -watch polls the Go files for changes")
+		bindings  = flag.Bool("bindings", false, "Generate the LGFunction bindings ("+stubgen.BindingsFile+") of the functions annotated with @luaexport, and the stubs if -output or -output-dir is set")
 	)
 
 	flag.Parse()
 
+	patterns := flag.Args()
+	if len(patterns) == 0 {
+		patterns = []string{filepath.Join(*dir, "...")}
+	}
+
+	if *teal && *outputDir == "" {
+		fmt.Fprintf(os.Stderr, "Error: -teal requires -output-dir\n")
+		os.Exit(1)
+	}
+
+	if *luarc && (*format != "lua" || *check || *typecheck) {
+		fmt.Fprintf(os.Stderr, "Error: -luarc only applies when generating Lua stubs\n")
+		os.Exit(1)
+	}
+	if *watch && (*format != "lua" || *check || *typecheck) {
+		fmt.Fprintf(os.Stderr, "Error: -watch only applies when generating Lua stubs\n")
+		os.Exit(1)
+	}
+
+	if *bindings {
+		if err := writeBindings(patterns, excludes, *strict); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		// Stubs are only generated when asked for, e.g. not from a go:generate directive
+		if !flagSet("output") && !flagSet("output-dir") && !*watch {
+			return
+		}
+	}
+
 	analyzer := stubgen.NewAnalyzer()
 
-	if err := analyzer.ScanDirectory(*dir); err != nil {
+	if *format != "lua" {
+		docFormat, err := stubgen.ParseDocFormat(*format)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		if *outputDir == "" {
+			fmt.Fprintf(os.Stderr, "Error: -format %s requires -output-dir\n", *format)
+			os.Exit(1)
+		}
+		if err := writeDocs(analyzer, patterns, excludes, *outputDir, docFormat, *strict); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	if *check || *typecheck {
+		os.Exit(runChecks(analyzer, patterns, excludes, *check, *typecheck))
+	}
+
+	if err := scan(analyzer, patterns, excludes, *strict); err != nil {
 		fmt.Fprintf(os.Stderr, "Error scanning directory: %v\n", err)
 		os.Exit(1)
 	}
 
-	// If output-dir is specified, generate per-module files
-	if *outputDir != "" {
-		if err := os.MkdirAll(*outputDir, 0755); err != nil {
-			fmt.Fprintf(os.Stderr, "Error creating output directory: %v\n", err)
+	opts := stubOptions{output: *output, outputDir: *outputDir, teal: *teal}
+	if err := writeStubs(analyzer, opts, nil); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	if *luarc {
+		library := *output
+		if *outputDir != "" {
+			library = *outputDir
+		}
+		if err := writeLuarc(*luarcFile, library, globals, *snapshot); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 			os.Exit(1)
 		}
+	}
+
+	if *watch {
+		os.Exit(runWatch(analyzer, patterns, excludes, opts, *interval, *bindings, *strict))
+	}
+}
+
+// stubOptions: where the Lua stubs are written
+type stubOptions struct {
+	output    string // Combined stub file, used when outputDir is empty
+	outputDir string // Directory of the per-module stub files
+	teal      bool   // Also write Teal declarations in outputDir
+}
+
+// writeStubs: writes the stubs of the given modules (all of them if nil) to the per-module files of
+// the output directory, or all the modules to the combined file
+func writeStubs(analyzer *stubgen.Analyzer, opts stubOptions, modules []string) error {
+	if opts.outputDir == "" {
+		stubs, err := analyzer.GenerateStubs()
+		if err != nil {
+			return fmt.Errorf("generating stubs: %w", err)
+		}
+
+		if err := os.WriteFile(opts.output, []byte(stubs), 0644); err != nil {
+			return fmt.Errorf("writing output file: %w", err)
+		}
+
+		fmt.Printf("Generated Lua stubs for %d module(s) in %s\n", analyzer.ModuleCount(), opts.output)
+		return nil
+	}
+
+	if err := os.MkdirAll(opts.outputDir, 0755); err != nil {
+		return fmt.Errorf("creating output directory: %w", err)
+	}
+
+	all := modules == nil
+	if all {
+		modules = sortedModules(analyzer)
+	}
+
+	for _, moduleName := range modules {
+		stub, err := analyzer.GenerateModuleStub(moduleName)
+		if err != nil {
+			return fmt.Errorf("generating stub for %s: %w", moduleName, err)
+		}
+
+		outputFile := fmt.Sprintf("%s/%s.gen.lua", opts.outputDir, moduleName)
+		if err := os.WriteFile(outputFile, []byte(stub), 0644); err != nil {
+			return fmt.Errorf("writing %s: %w", outputFile, err)
+		}
+		fmt.Printf("Generated %s\n", outputFile)
 
-		for moduleName := range analyzer.GetModules() {
-			stub, err := analyzer.GenerateModuleStub(moduleName)
+		if opts.teal {
+			decls, err := analyzer.GenerateModuleTeal(moduleName)
 			if err != nil {
-				fmt.Fprintf(os.Stderr, "Error generating stub for %s: %v\n", moduleName, err)
-				os.Exit(1)
+				return fmt.Errorf("generating Teal declarations for %s: %w", moduleName, err)
 			}
 
-			outputFile := fmt.Sprintf("%s/%s.gen.lua", *outputDir, moduleName)
-			if err := os.WriteFile(outputFile, []byte(stub), 0644); err != nil {
-				fmt.Fprintf(os.Stderr, "Error writing %s: %v\n", outputFile, err)
-				os.Exit(1)
+			tealFile := fmt.Sprintf("%s/%s.d.tl", opts.outputDir, moduleName)
+			if err := os.WriteFile(tealFile, []byte(decls), 0644); err != nil {
+				return fmt.Errorf("writing %s: %w", tealFile, err)
 			}
-			fmt.Printf("Generated %s\n", outputFile)
+			fmt.Printf("Generated %s\n", tealFile)
 		}
+	}
 
-		fmt.Printf("Generated Lua stubs for %d module(s) in %s/\n", analyzer.ModuleCount(), *outputDir)
-		return
+	if all {
+		fmt.Printf("Generated Lua stubs for %d module(s) in %s/\n", analyzer.ModuleCount(), opts.outputDir)
+	}
+	return nil
+}
+
+// writeLuarc: creates or updates the .luarc.json at path, adding the generated stubs to the library
+// and declaring the globals, plus those of the snapshot file if set
+func writeLuarc(path, library string, globals []string, snapshotFile string) error {
+	// LuaLS resolves the library relative to the .luarc.json
+	if rel, err := filepath.Rel(filepath.Dir(path), library); err == nil {
+		library = filepath.ToSlash(rel)
+	}
+
+	if snapshotFile != "" {
+		snapshot, err := readSnapshot(snapshotFile)
+		if err != nil {
+			return err
+		}
+
+		names := make([]string, 0, len(snapshot.Globals))
+		for name := range snapshot.Globals {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		globals = append(globals, names...)
+	}
+
+	if err := stubgen.WriteLuarc(path, stubgen.LuarcConfig{
+		Library: []string{library},
+		Globals: globals,
+	}); err != nil {
+		return fmt.Errorf("writing %s: %w", path, err)
 	}
 
-	// Otherwise, generate combined file
-	stubs, err := analyzer.GenerateStubs()
+	fmt.Printf("Updated %s\n", path)
+	return nil
+}
+
+// scan: scans the patterns and prints the diagnostics of malformed annotations.
+// They are warnings, unless strict is set.
+func scan(analyzer *stubgen.Analyzer, patterns, excludes []string, strict bool) error {
+	diagnostics, err := analyzer.ScanPatterns(patterns, excludes)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error generating stubs: %v\n", err)
-		os.Exit(1)
+		return err
 	}
 
-	if err := os.WriteFile(*output, []byte(stubs), 0644); err != nil {
-		fmt.Fprintf(os.Stderr, "Error writing output file: %v\n", err)
-		os.Exit(1)
+	level := "warning"
+	if strict {
+		level = "error"
+	}
+
+	for _, d := range diagnostics {
+		fmt.Fprintf(os.Stderr, "%s: %s: %s\n", d.Pos, level, d.Message)
+	}
+
+	if strict && len(diagnostics) > 0 {
+		return fmt.Errorf("%d malformed annotation(s) found", len(diagnostics))
+	}
+
+	return nil
+}
+
+// writeBindings: writes the bindings of the packages matched by the patterns and prints the diagnostics
+// of the functions that cannot be bound. They are warnings, unless strict is set.
+func writeBindings(patterns, excludes []string, strict bool) error {
+	written, diagnostics, err := stubgen.WriteBindings(patterns, excludes)
+	if err != nil {
+		return err
+	}
+
+	level := "warning"
+	if strict {
+		level = "error"
+	}
+
+	for _, d := range diagnostics {
+		fmt.Fprintf(os.Stderr, "%s: %s: %s\n", d.Pos, level, d.Message)
+	}
+
+	if strict && len(diagnostics) > 0 {
+		return fmt.Errorf("%d function(s) cannot be exported", len(diagnostics))
+	}
+
+	for _, path := range written {
+		fmt.Printf("Generated %s\n", path)
+	}
+	return nil
+}
+
+// flagSet: checks whether a flag was set on the command line
+func flagSet(name string) bool {
+	set := false
+	flag.Visit(func(f *flag.Flag) {
+		if f.Name == name {
+			set = true
+		}
+	})
+	return set
+}
+
+// sortedModules: returns the names of the discovered modules in sorted order
+func sortedModules(analyzer *stubgen.Analyzer) []string {
+	names := make([]string, 0, analyzer.ModuleCount())
+	for name := range analyzer.GetModules() {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// runChecks: reports annotation problems found in the packages matched by the patterns and
+// returns the exit status (0 if none were found, 1 if some were, 2 on error)
+func runChecks(analyzer *stubgen.Analyzer, patterns, excludes []string, exports, signatures bool) int {
+	var diagnostics []stubgen.Diagnostic
+
+	if exports {
+		found, err := analyzer.CheckExportsPatterns(patterns, excludes)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error checking exports: %v\n", err)
+			return 2
+		}
+		diagnostics = append(diagnostics, found...)
+	}
+
+	if signatures {
+		found, err := analyzer.CheckSignaturesPatterns(patterns, excludes)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error checking signatures: %v\n", err)
+			return 2
+		}
+		diagnostics = append(diagnostics, found...)
+	}
+
+	for _, d := range diagnostics {
+		fmt.Fprintln(os.Stderr, d)
+	}
+
+	if len(diagnostics) > 0 {
+		fmt.Fprintf(os.Stderr, "\n%d annotation problem(s) found\n", len(diagnostics))
+		return 1
+	}
+
+	return 0
+}
+
+// writeDocs: writes the API reference of every module, plus an index page, to outputDir
+func writeDocs(analyzer *stubgen.Analyzer, patterns, excludes []string, outputDir string, format stubgen.DocFormat, strict bool) error {
+	if err := scan(analyzer, patterns, excludes, strict); err != nil {
+		return fmt.Errorf("scanning directory: %w", err)
+	}
+
+	if err := os.MkdirAll(outputDir, 0755); err != nil {
+		return fmt.Errorf("creating output directory: %w", err)
+	}
+
+	for _, moduleName := range sortedModules(analyzer) {
+		page, err := analyzer.GenerateModuleDocs(moduleName, format)
+		if err != nil {
+			return fmt.Errorf("generating docs for %s: %w", moduleName, err)
+		}
+
+		outputFile := filepath.Join(outputDir, moduleName+format.Extension())
+		if err := os.WriteFile(outputFile, []byte(page), 0644); err != nil {
+			return fmt.Errorf("writing %s: %w", outputFile, err)
+		}
+		fmt.Printf("Generated %s\n", outputFile)
+	}
+
+	index, err := analyzer.GenerateDocsIndex(format)
+	if err != nil {
+		return fmt.Errorf("generating index: %w", err)
+	}
+
+	indexFile := filepath.Join(outputDir, "index"+format.Extension())
+	if err := os.WriteFile(indexFile, []byte(index), 0644); err != nil {
+		return fmt.Errorf("writing %s: %w", indexFile, err)
 	}
 
-	fmt.Printf("Generated Lua stubs for %d module(s) in %s\n", analyzer.ModuleCount(), *output)
+	fmt.Printf("Generated %s reference for %d module(s) in %s/\n", format, analyzer.ModuleCount(), outputDir)
+	return nil
 }
@@ -0,0 +1,122 @@
+// Copyright (c) 2024-2025 Thomas Maurice
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/thomas-maurice/glua/pkg/stubgen"
+)
+
+// runWatch: polls the Go files matched by the patterns until interrupted, and after every change
+// regenerates the stubs of the modules whose annotations changed (and the bindings if requested),
+// printing the functions added and removed. analyzer holds the modules of the initial generation.
+// Returns the exit status.
+func runWatch(analyzer *stubgen.Analyzer, patterns, excludes []string, opts stubOptions, interval time.Duration, bindings, strict bool) int {
+	watcher, err := stubgen.NewWatcher(patterns, excludes)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	fmt.Printf("Watching %s for changes (Ctrl+C to stop)\n", strings.Join(patterns, " "))
+
+	previous := analyzer
+	err = watcher.Run(ctx, interval, func(changed []string) {
+		if len(changed) == 1 {
+			fmt.Printf("%s %s changed\n", time.Now().Format("15:04:05"), changed[0])
+		} else {
+			fmt.Printf("%s %d files changed\n", time.Now().Format("15:04:05"), len(changed))
+		}
+
+		if bindings {
+			// Rewritten bindings are picked up by the next poll
+			if err := writeBindings(patterns, excludes, strict); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				return
+			}
+		}
+
+		// Previous modules are kept until the annotations are valid again
+		current := stubgen.NewAnalyzer()
+		if err := scan(current, patterns, excludes, strict); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return
+		}
+
+		if err := regenerate(previous, current, opts); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return
+		}
+		previous = current
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	return 0
+}
+
+// regenerate: prints how the modules changed and writes the stubs of the changed modules,
+// removing those of the deleted ones. The combined stub file is rewritten on any change.
+func regenerate(previous, current *stubgen.Analyzer, opts stubOptions) error {
+	changes := stubgen.DiffModules(previous, current)
+	if len(changes) == 0 {
+		fmt.Println("  stubs unchanged")
+		return nil
+	}
+
+	modules := make([]string, 0, len(changes))
+	for _, change := range changes {
+		fmt.Printf("  %s\n", change)
+
+		if change.Kind != "removed" {
+			modules = append(modules, change.Module)
+			continue
+		}
+
+		if opts.outputDir != "" {
+			exts := []string{".gen.lua"}
+			if opts.teal {
+				exts = append(exts, ".d.tl")
+			}
+			for _, ext := range exts {
+				path := filepath.Join(opts.outputDir, change.Module+ext)
+				if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+					return err
+				}
+			}
+		}
+	}
+
+	return writeStubs(current, opts, modules)
+}
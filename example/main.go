@@ -131,6 +131,13 @@ func generateStubs() error {
 		}
 	}
 
+	// Declare the globals injected into scripts
+	for _, name := range []string{"myPod", "originalPod"} {
+		if err := treg.RegisterGlobal(name, &corev1.Pod{}); err != nil {
+			return fmt.Errorf("failed to register global: %w", err)
+		}
+	}
+
 	if err := treg.Process(); err != nil {
 		return fmt.Errorf("failed to process types: %w", err)
 	}
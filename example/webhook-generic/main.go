@@ -21,6 +21,7 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
 	"flag"
 	"fmt"
@@ -28,6 +29,7 @@ import (
 	"net/http"
 	"os"
 	"path/filepath"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/thomas-maurice/glua/pkg/glua"
@@ -40,19 +42,23 @@ import (
 
 // Config: holds the webhook server configuration
 type Config struct {
-	Address     string
-	CertFile    string
-	KeyFile     string
-	ScriptsDir  string
-	EnableNodes bool
-	EnablePods  bool
+	Address        string
+	CertFile       string
+	KeyFile        string
+	ScriptsDir     string
+	EnableNodes    bool
+	EnablePods     bool
+	ReloadInterval time.Duration // How often the scripts are checked for changes, 0 to disable
 }
 
 // WebhookServer: represents the generic mutating webhook server
 type WebhookServer struct {
-	config *Config
-	logger *slog.Logger
-	engine *gin.Engine
+	config     *Config
+	logger     *slog.Logger
+	engine     *gin.Engine
+	pool       *glua.StatePool   // Lua states with the kubernetes module preloaded
+	scripts    *glua.ScriptCache // Mutation scripts, compiled once and reloaded when they change
+	translator *glua.Translator
 }
 
 // NewWebhookServer: creates a new generic webhook server instance
@@ -61,14 +67,39 @@ func NewWebhookServer(cfg *Config) (*WebhookServer, error) {
 		Level: slog.LevelInfo,
 	}))
 
+	// Compile the scripts of the enabled kinds once, and share prepared states between requests
+	scripts := glua.NewScriptCache()
+	for name, enabled := range map[string]bool{
+		"mutate_pod.lua":  cfg.EnablePods,
+		"mutate_node.lua": cfg.EnableNodes,
+	} {
+		if !enabled {
+			continue
+		}
+
+		if _, err := scripts.Load(filepath.Join(cfg.ScriptsDir, name)); err != nil {
+			return nil, fmt.Errorf("failed to compile lua script: %w", err)
+		}
+	}
+
+	pool, err := glua.NewStatePool(glua.PoolOptions{
+		Modules: map[string]lua.LGFunction{"kubernetes": kubernetes.Loader},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create lua state pool: %w", err)
+	}
+
 	gin.SetMode(gin.ReleaseMode)
 	engine := gin.New()
 	engine.Use(gin.Recovery())
 
 	ws := &WebhookServer{
-		config: cfg,
-		logger: logger,
-		engine: engine,
+		config:     cfg,
+		logger:     logger,
+		engine:     engine,
+		pool:       pool,
+		scripts:    scripts,
+		translator: glua.NewTranslator(),
 	}
 
 	// Register routes
@@ -158,8 +189,7 @@ func (ws *WebhookServer) mutatePod(req *admissionv1.AdmissionRequest, response *
 		"name", pod.Name,
 	)
 
-	scriptPath := filepath.Join(ws.config.ScriptsDir, "mutate_pod.lua")
-	patches, err := ws.runLuaMutation(scriptPath, "pod", pod)
+	patches, err := ws.runLuaMutation("mutate_pod.lua", "pod", pod)
 	if err != nil {
 		ws.logger.Error("pod mutation failed", "error", err)
 		return response
@@ -180,8 +210,7 @@ func (ws *WebhookServer) mutateNode(req *admissionv1.AdmissionRequest, response
 		"name", node.Name,
 	)
 
-	scriptPath := filepath.Join(ws.config.ScriptsDir, "mutate_node.lua")
-	patches, err := ws.runLuaMutation(scriptPath, "node", node)
+	patches, err := ws.runLuaMutation("mutate_node.lua", "node", node)
 	if err != nil {
 		ws.logger.Error("node mutation failed", "error", err)
 		return response
@@ -191,17 +220,16 @@ func (ws *WebhookServer) mutateNode(req *admissionv1.AdmissionRequest, response
 }
 
 // runLuaMutation: executes the Lua script to generate JSON patches (generic implementation)
-func (ws *WebhookServer) runLuaMutation(scriptPath, globalName string, object interface{}) ([]map[string]interface{}, error) {
-	L := lua.NewState()
-	defer L.Close()
-
-	translator := glua.NewTranslator()
-
-	// Preload kubernetes module for Lua scripts
-	L.PreloadModule("kubernetes", kubernetes.Loader)
+func (ws *WebhookServer) runLuaMutation(scriptName, globalName string, object interface{}) ([]map[string]interface{}, error) {
+	// Borrow a state, its globals are reset when it is returned
+	L, err := ws.pool.Get()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get lua state: %w", err)
+	}
+	defer ws.pool.Put(L)
 
 	// Convert object to Lua table
-	objectTable, err := translator.ToLua(L, object)
+	objectTable, err := ws.translator.ToLua(L, object)
 	if err != nil {
 		return nil, fmt.Errorf("failed to convert %s to lua: %w", globalName, err)
 	}
@@ -213,7 +241,7 @@ func (ws *WebhookServer) runLuaMutation(scriptPath, globalName string, object in
 	L.SetGlobal("patches", L.NewTable())
 
 	// Execute the Lua script
-	if err := L.DoFile(scriptPath); err != nil {
+	if err := ws.scripts.Run(L, filepath.Join(ws.config.ScriptsDir, scriptName)); err != nil {
 		return nil, fmt.Errorf("failed to execute lua script: %w", err)
 	}
 
@@ -225,7 +253,7 @@ func (ws *WebhookServer) runLuaMutation(scriptPath, globalName string, object in
 
 	// Convert Lua table to Go slice
 	var patches []map[string]interface{}
-	if err := translator.FromLua(L, patchesValue, &patches); err != nil {
+	if err := ws.translator.FromLua(L, patchesValue, &patches); err != nil {
 		return nil, fmt.Errorf("failed to convert patches from lua: %w", err)
 	}
 
@@ -257,12 +285,30 @@ func (ws *WebhookServer) buildPatchResponse(response *admissionv1.AdmissionRespo
 	return response
 }
 
+// logReload: logs a script reload, a failed one keeps the previous version
+func (ws *WebhookServer) logReload(reload glua.ScriptReload) {
+	if reload.Err != nil {
+		ws.logger.Error("failed to reload lua script, keeping the previous version",
+			"script", reload.Path,
+			"error", reload.Err,
+		)
+		return
+	}
+
+	ws.logger.Info("reloaded lua script", "script", reload.Path)
+}
+
 // Run: starts the webhook server
 func (ws *WebhookServer) Run() error {
 	ws.logger.Info("starting webhook server",
 		"address", ws.config.Address,
 	)
 
+	// Pick up edits of the scripts, e.g. an updated ConfigMap, without a restart
+	if ws.config.ReloadInterval > 0 {
+		go ws.scripts.Watch(context.Background(), ws.config.ReloadInterval, ws.logReload)
+	}
+
 	if ws.config.CertFile != "" && ws.config.KeyFile != "" {
 		return ws.engine.RunTLS(ws.config.Address, ws.config.CertFile, ws.config.KeyFile)
 	}
@@ -273,22 +319,24 @@ func (ws *WebhookServer) Run() error {
 
 func main() {
 	var (
-		address     = flag.String("address", ":8443", "Address to listen on")
-		certFile    = flag.String("cert", "/etc/webhook/certs/tls.crt", "Path to TLS certificate")
-		keyFile     = flag.String("key", "/etc/webhook/certs/tls.key", "Path to TLS private key")
-		scriptsDir  = flag.String("scripts", "/etc/webhook/scripts", "Path to Lua scripts directory")
-		enableNodes = flag.Bool("enable-nodes", true, "Enable node mutations")
-		enablePods  = flag.Bool("enable-pods", true, "Enable pod mutations")
+		address        = flag.String("address", ":8443", "Address to listen on")
+		certFile       = flag.String("cert", "/etc/webhook/certs/tls.crt", "Path to TLS certificate")
+		keyFile        = flag.String("key", "/etc/webhook/certs/tls.key", "Path to TLS private key")
+		scriptsDir     = flag.String("scripts", "/etc/webhook/scripts", "Path to Lua scripts directory")
+		enableNodes    = flag.Bool("enable-nodes", true, "Enable node mutations")
+		enablePods     = flag.Bool("enable-pods", true, "Enable pod mutations")
+		reloadInterval = flag.Duration("reload-interval", 5*time.Second, "How often the scripts are checked for changes (0 to disable)")
 	)
 	flag.Parse()
 
 	config := &Config{
-		Address:     *address,
-		CertFile:    *certFile,
-		KeyFile:     *keyFile,
-		ScriptsDir:  *scriptsDir,
-		EnableNodes: *enableNodes,
-		EnablePods:  *enablePods,
+		Address:        *address,
+		CertFile:       *certFile,
+		KeyFile:        *keyFile,
+		ScriptsDir:     *scriptsDir,
+		EnableNodes:    *enableNodes,
+		EnablePods:     *enablePods,
+		ReloadInterval: *reloadInterval,
 	}
 
 	server, err := NewWebhookServer(config)
@@ -21,12 +21,14 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
 	"flag"
 	"fmt"
 	"log/slog"
 	"net/http"
 	"os"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/thomas-maurice/glua/pkg/glua"
@@ -38,17 +40,21 @@ import (
 
 // Config: holds the webhook server configuration
 type Config struct {
-	Address    string
-	CertFile   string
-	KeyFile    string
-	ScriptPath string
+	Address        string
+	CertFile       string
+	KeyFile        string
+	ScriptPath     string
+	ReloadInterval time.Duration // How often the script is checked for changes, 0 to disable
 }
 
 // WebhookServer: represents the mutating webhook server instance
 type WebhookServer struct {
-	config *Config
-	logger *slog.Logger
-	engine *gin.Engine
+	config     *Config
+	logger     *slog.Logger
+	engine     *gin.Engine
+	pool       *glua.StatePool   // Lua states with the kubernetes module preloaded
+	scripts    *glua.ScriptCache // Mutation script, compiled once and reloaded when it changes
+	translator *glua.Translator
 }
 
 // NewWebhookServer: creates a new webhook server instance
@@ -57,14 +63,30 @@ func NewWebhookServer(cfg *Config) (*WebhookServer, error) {
 		Level: slog.LevelInfo,
 	}))
 
+	// Compile the script once, and share prepared states between requests
+	scripts := glua.NewScriptCache()
+	if _, err := scripts.Load(cfg.ScriptPath); err != nil {
+		return nil, fmt.Errorf("failed to compile lua script: %w", err)
+	}
+
+	pool, err := glua.NewStatePool(glua.PoolOptions{
+		Modules: map[string]lua.LGFunction{"kubernetes": kubernetes.Loader},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create lua state pool: %w", err)
+	}
+
 	gin.SetMode(gin.ReleaseMode)
 	engine := gin.New()
 	engine.Use(gin.Recovery())
 
 	ws := &WebhookServer{
-		config: cfg,
-		logger: logger,
-		engine: engine,
+		config:     cfg,
+		logger:     logger,
+		engine:     engine,
+		pool:       pool,
+		scripts:    scripts,
+		translator: glua.NewTranslator(),
 	}
 
 	// Register routes
@@ -164,16 +186,15 @@ func (ws *WebhookServer) mutate(req *admissionv1.AdmissionRequest) *admissionv1.
 
 // runLuaMutation: executes the Lua script to generate JSON patches
 func (ws *WebhookServer) runLuaMutation(pod *corev1.Pod) ([]map[string]interface{}, error) {
-	L := lua.NewState()
-	defer L.Close()
-
-	translator := glua.NewTranslator()
-
-	// Preload kubernetes module for Lua scripts
-	L.PreloadModule("kubernetes", kubernetes.Loader)
+	// Borrow a state, its globals are reset when it is returned
+	L, err := ws.pool.Get()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get lua state: %w", err)
+	}
+	defer ws.pool.Put(L)
 
 	// Convert pod to Lua table
-	podTable, err := translator.ToLua(L, pod)
+	podTable, err := ws.translator.ToLua(L, pod)
 	if err != nil {
 		return nil, fmt.Errorf("failed to convert pod to lua: %w", err)
 	}
@@ -185,7 +206,7 @@ func (ws *WebhookServer) runLuaMutation(pod *corev1.Pod) ([]map[string]interface
 	L.SetGlobal("patches", L.NewTable())
 
 	// Execute the Lua script
-	if err := L.DoFile(ws.config.ScriptPath); err != nil {
+	if err := ws.scripts.Run(L, ws.config.ScriptPath); err != nil {
 		return nil, fmt.Errorf("failed to execute lua script: %w", err)
 	}
 
@@ -197,13 +218,26 @@ func (ws *WebhookServer) runLuaMutation(pod *corev1.Pod) ([]map[string]interface
 
 	// Convert Lua table to Go slice
 	var patches []map[string]interface{}
-	if err := translator.FromLua(L, patchesValue, &patches); err != nil {
+	if err := ws.translator.FromLua(L, patchesValue, &patches); err != nil {
 		return nil, fmt.Errorf("failed to convert patches from lua: %w", err)
 	}
 
 	return patches, nil
 }
 
+// logReload: logs a script reload, a failed one keeps the previous version
+func (ws *WebhookServer) logReload(reload glua.ScriptReload) {
+	if reload.Err != nil {
+		ws.logger.Error("failed to reload lua script, keeping the previous version",
+			"script", reload.Path,
+			"error", reload.Err,
+		)
+		return
+	}
+
+	ws.logger.Info("reloaded lua script", "script", reload.Path)
+}
+
 // Serve: starts the webhook server with TLS
 func (ws *WebhookServer) Serve() error {
 	ws.logger.Info("starting webhook server",
@@ -211,6 +245,11 @@ func (ws *WebhookServer) Serve() error {
 		"script", ws.config.ScriptPath,
 	)
 
+	// Pick up edits of the script, e.g. an updated ConfigMap, without a restart
+	if ws.config.ReloadInterval > 0 {
+		go ws.scripts.Watch(context.Background(), ws.config.ReloadInterval, ws.logReload)
+	}
+
 	return ws.engine.RunTLS(ws.config.Address, ws.config.CertFile, ws.config.KeyFile)
 }
 
@@ -225,6 +264,7 @@ func main() {
 			"/etc/webhook/scripts/mutate.lua",
 			"Lua mutation script path",
 		)
+		reloadInterval = flag.Duration("reload-interval", 5*time.Second, "How often the script is checked for changes (0 to disable)")
 	)
 	flag.Parse()
 
@@ -235,10 +275,11 @@ func main() {
 	}
 
 	cfg := &Config{
-		Address:    *address,
-		CertFile:   *certFile,
-		KeyFile:    *keyFile,
-		ScriptPath: *scriptPath,
+		Address:        *address,
+		CertFile:       *certFile,
+		KeyFile:        *keyFile,
+		ScriptPath:     *scriptPath,
+		ReloadInterval: *reloadInterval,
 	}
 
 	server, err := NewWebhookServer(cfg)
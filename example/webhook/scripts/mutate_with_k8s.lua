@@ -78,7 +78,7 @@ if k8sclient then
 	local config_name = "webhook-config"
 
 	-- Try to get the ConfigMap using the client
-	local config, err = client:get(cm_gvk, namespace, config_name)
+	local config, err = client.get(cm_gvk, namespace, config_name)
 
 	if config and not err then
 		print(string.format("Found ConfigMap %s in namespace %s", config_name, namespace))
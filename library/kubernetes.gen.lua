@@ -2,6 +2,8 @@
 ---@alias v1.Time string
 ---@alias v1.MicroTime string
 
+---@alias corev1.ResourceList table<string, resource.Quantity>
+
 ---@class kubernetes.GVKMatcher
 ---@field group string
 ---@field kind string
@@ -26,25 +28,24 @@
 ---@field timeoutSeconds number
 
 ---@class admissionregistrationv1.MutatingWebhookConfiguration
----@field TypeMeta v1.TypeMeta
+---@field apiVersion string
+---@field kind string
 ---@field metadata v1.ObjectMeta
 ---@field webhooks admissionregistrationv1.MutatingWebhook[]
 
 ---@class admissionregistrationv1.MutatingWebhookConfigurationList
----@field TypeMeta v1.TypeMeta
+---@field apiVersion string
 ---@field items admissionregistrationv1.MutatingWebhookConfiguration[]
+---@field kind string
 ---@field metadata v1.ListMeta
 
----@class admissionregistrationv1.Rule
+---@class admissionregistrationv1.RuleWithOperations
 ---@field apiGroups string[]
 ---@field apiVersions string[]
+---@field operations string[]
 ---@field resources string[]
 ---@field scope string
 
----@class admissionregistrationv1.RuleWithOperations
----@field Rule admissionregistrationv1.Rule
----@field operations string[]
-
 ---@class admissionregistrationv1.ServiceReference
 ---@field name string
 ---@field namespace string
@@ -65,13 +66,15 @@
 ---@field timeoutSeconds number
 
 ---@class admissionregistrationv1.ValidatingWebhookConfiguration
----@field TypeMeta v1.TypeMeta
+---@field apiVersion string
+---@field kind string
 ---@field metadata v1.ObjectMeta
 ---@field webhooks admissionregistrationv1.ValidatingWebhook[]
 
 ---@class admissionregistrationv1.ValidatingWebhookConfigurationList
----@field TypeMeta v1.TypeMeta
+---@field apiVersion string
 ---@field items admissionregistrationv1.ValidatingWebhookConfiguration[]
+---@field kind string
 ---@field metadata v1.ListMeta
 
 ---@class admissionregistrationv1.WebhookClientConfig
@@ -80,7 +83,8 @@
 ---@field url string
 
 ---@class appsv1.DaemonSet
----@field TypeMeta v1.TypeMeta
+---@field apiVersion string
+---@field kind string
 ---@field metadata v1.ObjectMeta
 ---@field spec appsv1.DaemonSetSpec
 ---@field status appsv1.DaemonSetStatus
@@ -93,8 +97,9 @@
 ---@field type string
 
 ---@class appsv1.DaemonSetList
----@field TypeMeta v1.TypeMeta
+---@field apiVersion string
 ---@field items appsv1.DaemonSet[]
+---@field kind string
 ---@field metadata v1.ListMeta
 
 ---@class appsv1.DaemonSetSpec
@@ -121,7 +126,8 @@
 ---@field type string
 
 ---@class appsv1.Deployment
----@field TypeMeta v1.TypeMeta
+---@field apiVersion string
+---@field kind string
 ---@field metadata v1.ObjectMeta
 ---@field spec appsv1.DeploymentSpec
 ---@field status appsv1.DeploymentStatus
@@ -135,8 +141,9 @@
 ---@field type string
 
 ---@class appsv1.DeploymentList
----@field TypeMeta v1.TypeMeta
+---@field apiVersion string
 ---@field items appsv1.Deployment[]
+---@field kind string
 ---@field metadata v1.ListMeta
 
 ---@class appsv1.DeploymentSpec
@@ -165,7 +172,8 @@
 ---@field type string
 
 ---@class appsv1.ReplicaSet
----@field TypeMeta v1.TypeMeta
+---@field apiVersion string
+---@field kind string
 ---@field metadata v1.ObjectMeta
 ---@field spec appsv1.ReplicaSetSpec
 ---@field status appsv1.ReplicaSetStatus
@@ -178,8 +186,9 @@
 ---@field type string
 
 ---@class appsv1.ReplicaSetList
----@field TypeMeta v1.TypeMeta
+---@field apiVersion string
 ---@field items appsv1.ReplicaSet[]
+---@field kind string
 ---@field metadata v1.ListMeta
 
 ---@class appsv1.ReplicaSetSpec
@@ -210,7 +219,8 @@
 ---@field partition number
 
 ---@class appsv1.StatefulSet
----@field TypeMeta v1.TypeMeta
+---@field apiVersion string
+---@field kind string
 ---@field metadata v1.ObjectMeta
 ---@field spec appsv1.StatefulSetSpec
 ---@field status appsv1.StatefulSetStatus
@@ -223,8 +233,9 @@
 ---@field type string
 
 ---@class appsv1.StatefulSetList
----@field TypeMeta v1.TypeMeta
+---@field apiVersion string
 ---@field items appsv1.StatefulSet[]
+---@field kind string
 ---@field metadata v1.ListMeta
 
 ---@class appsv1.StatefulSetOrdinals
@@ -298,7 +309,8 @@
 ---@field tolerance resource.Quantity
 
 ---@class autoscalingv2.HorizontalPodAutoscaler
----@field TypeMeta v1.TypeMeta
+---@field apiVersion string
+---@field kind string
 ---@field metadata v1.ObjectMeta
 ---@field spec autoscalingv2.HorizontalPodAutoscalerSpec
 ---@field status autoscalingv2.HorizontalPodAutoscalerStatus
@@ -315,8 +327,9 @@
 ---@field type string
 
 ---@class autoscalingv2.HorizontalPodAutoscalerList
----@field TypeMeta v1.TypeMeta
+---@field apiVersion string
 ---@field items autoscalingv2.HorizontalPodAutoscaler[]
+---@field kind string
 ---@field metadata v1.ListMeta
 
 ---@class autoscalingv2.HorizontalPodAutoscalerSpec
@@ -392,14 +405,16 @@
 ---@field name string
 
 ---@class batchv1.CronJob
----@field TypeMeta v1.TypeMeta
+---@field apiVersion string
+---@field kind string
 ---@field metadata v1.ObjectMeta
 ---@field spec batchv1.CronJobSpec
 ---@field status batchv1.CronJobStatus
 
 ---@class batchv1.CronJobList
----@field TypeMeta v1.TypeMeta
+---@field apiVersion string
 ---@field items batchv1.CronJob[]
+---@field kind string
 ---@field metadata v1.ListMeta
 
 ---@class batchv1.CronJobSpec
@@ -418,7 +433,8 @@
 ---@field lastSuccessfulTime v1.Time
 
 ---@class batchv1.Job
----@field TypeMeta v1.TypeMeta
+---@field apiVersion string
+---@field kind string
 ---@field metadata v1.ObjectMeta
 ---@field spec batchv1.JobSpec
 ---@field status batchv1.JobStatus
@@ -432,8 +448,9 @@
 ---@field type string
 
 ---@class batchv1.JobList
----@field TypeMeta v1.TypeMeta
+---@field apiVersion string
 ---@field items batchv1.Job[]
+---@field kind string
 ---@field metadata v1.ListMeta
 
 ---@class batchv1.JobSpec
@@ -500,13 +517,15 @@
 ---@field succeeded string[]
 
 ---@class coordinationv1.Lease
----@field TypeMeta v1.TypeMeta
+---@field apiVersion string
+---@field kind string
 ---@field metadata v1.ObjectMeta
 ---@field spec coordinationv1.LeaseSpec
 
 ---@class coordinationv1.LeaseList
----@field TypeMeta v1.TypeMeta
+---@field apiVersion string
 ---@field items coordinationv1.Lease[]
+---@field kind string
 ---@field metadata v1.ListMeta
 
 ---@class coordinationv1.LeaseSpec
@@ -618,24 +637,26 @@
 ---@field signerName string
 
 ---@class corev1.ConfigMap
----@field TypeMeta v1.TypeMeta
+---@field apiVersion string
 ---@field binaryData table<string, number[]>
 ---@field data table<string, string>
 ---@field immutable boolean
+---@field kind string
 ---@field metadata v1.ObjectMeta
 
 ---@class corev1.ConfigMapEnvSource
----@field LocalObjectReference corev1.LocalObjectReference
+---@field name string
 ---@field optional boolean
 
 ---@class corev1.ConfigMapKeySelector
----@field LocalObjectReference corev1.LocalObjectReference
 ---@field key string
+---@field name string
 ---@field optional boolean
 
 ---@class corev1.ConfigMapList
----@field TypeMeta v1.TypeMeta
+---@field apiVersion string
 ---@field items corev1.ConfigMap[]
+---@field kind string
 ---@field metadata v1.ListMeta
 
 ---@class corev1.ConfigMapNodeConfigSource
@@ -646,14 +667,14 @@
 ---@field uid string
 
 ---@class corev1.ConfigMapProjection
----@field LocalObjectReference corev1.LocalObjectReference
 ---@field items corev1.KeyToPath[]
+---@field name string
 ---@field optional boolean
 
 ---@class corev1.ConfigMapVolumeSource
----@field LocalObjectReference corev1.LocalObjectReference
 ---@field defaultMode number
 ---@field items corev1.KeyToPath[]
+---@field name string
 ---@field optional boolean
 
 ---@class corev1.Container
@@ -733,7 +754,7 @@
 ---@field reason string
 
 ---@class corev1.ContainerStatus
----@field allocatedResources table<string, resource.Quantity>
+---@field allocatedResources corev1.ResourceList
 ---@field allocatedResourcesStatus corev1.ResourceStatus[]
 ---@field containerID string
 ---@field image string
@@ -790,10 +811,6 @@
 ---@field secretKeyRef corev1.SecretKeySelector
 
 ---@class corev1.EphemeralContainer
----@field EphemeralContainerCommon corev1.EphemeralContainerCommon
----@field targetContainerName string
-
----@class corev1.EphemeralContainerCommon
 ---@field args string[]
 ---@field command string[]
 ---@field env corev1.EnvVar[]
@@ -813,6 +830,7 @@
 ---@field startupProbe corev1.Probe
 ---@field stdin boolean
 ---@field stdinOnce boolean
+---@field targetContainerName string
 ---@field terminationMessagePath string
 ---@field terminationMessagePolicy string
 ---@field tty boolean
@@ -986,7 +1004,8 @@
 ---@field server string
 
 ---@class corev1.Namespace
----@field TypeMeta v1.TypeMeta
+---@field apiVersion string
+---@field kind string
 ---@field metadata v1.ObjectMeta
 ---@field spec corev1.NamespaceSpec
 ---@field status corev1.NamespaceStatus
@@ -999,8 +1018,9 @@
 ---@field type string
 
 ---@class corev1.NamespaceList
----@field TypeMeta v1.TypeMeta
+---@field apiVersion string
 ---@field items corev1.Namespace[]
+---@field kind string
 ---@field metadata v1.ListMeta
 
 ---@class corev1.NamespaceSpec
@@ -1011,7 +1031,8 @@
 ---@field phase string
 
 ---@class corev1.Node
----@field TypeMeta v1.TypeMeta
+---@field apiVersion string
+---@field kind string
 ---@field metadata v1.ObjectMeta
 ---@field spec corev1.NodeSpec
 ---@field status corev1.NodeStatus
@@ -1048,8 +1069,9 @@
 ---@field supplementalGroupsPolicy boolean
 
 ---@class corev1.NodeList
----@field TypeMeta v1.TypeMeta
+---@field apiVersion string
 ---@field items corev1.Node[]
+---@field kind string
 ---@field metadata v1.ListMeta
 
 ---@class corev1.NodeRuntimeHandler
@@ -1083,8 +1105,8 @@
 
 ---@class corev1.NodeStatus
 ---@field addresses corev1.NodeAddress[]
----@field allocatable table<string, resource.Quantity>
----@field capacity table<string, resource.Quantity>
+---@field allocatable corev1.ResourceList
+---@field capacity corev1.ResourceList
 ---@field conditions corev1.NodeCondition[]
 ---@field config corev1.NodeConfigStatus
 ---@field daemonEndpoints corev1.NodeDaemonEndpoints
@@ -1126,13 +1148,15 @@
 ---@field uid string
 
 ---@class corev1.PersistentVolume
----@field TypeMeta v1.TypeMeta
+---@field apiVersion string
+---@field kind string
 ---@field metadata v1.ObjectMeta
 ---@field spec corev1.PersistentVolumeSpec
 ---@field status corev1.PersistentVolumeStatus
 
 ---@class corev1.PersistentVolumeClaim
----@field TypeMeta v1.TypeMeta
+---@field apiVersion string
+---@field kind string
 ---@field metadata v1.ObjectMeta
 ---@field spec corev1.PersistentVolumeClaimSpec
 ---@field status corev1.PersistentVolumeClaimStatus
@@ -1146,8 +1170,9 @@
 ---@field type string
 
 ---@class corev1.PersistentVolumeClaimList
----@field TypeMeta v1.TypeMeta
+---@field apiVersion string
 ---@field items corev1.PersistentVolumeClaim[]
+---@field kind string
 ---@field metadata v1.ListMeta
 
 ---@class corev1.PersistentVolumeClaimSpec
@@ -1164,8 +1189,8 @@
 ---@class corev1.PersistentVolumeClaimStatus
 ---@field accessModes string[]
 ---@field allocatedResourceStatuses table<string, string>
----@field allocatedResources table<string, resource.Quantity>
----@field capacity table<string, resource.Quantity>
+---@field allocatedResources corev1.ResourceList
+---@field capacity corev1.ResourceList
 ---@field conditions corev1.PersistentVolumeClaimCondition[]
 ---@field currentVolumeAttributesClassName string
 ---@field modifyVolumeStatus corev1.ModifyVolumeStatus
@@ -1180,16 +1205,20 @@
 ---@field readOnly boolean
 
 ---@class corev1.PersistentVolumeList
----@field TypeMeta v1.TypeMeta
+---@field apiVersion string
 ---@field items corev1.PersistentVolume[]
+---@field kind string
 ---@field metadata v1.ListMeta
 
----@class corev1.PersistentVolumeSource
+---@class corev1.PersistentVolumeSpec
+---@field accessModes string[]
 ---@field awsElasticBlockStore corev1.AWSElasticBlockStoreVolumeSource
 ---@field azureDisk corev1.AzureDiskVolumeSource
 ---@field azureFile corev1.AzureFilePersistentVolumeSource
+---@field capacity corev1.ResourceList
 ---@field cephfs corev1.CephFSPersistentVolumeSource
 ---@field cinder corev1.CinderPersistentVolumeSource
+---@field claimRef corev1.ObjectReference
 ---@field csi corev1.CSIPersistentVolumeSource
 ---@field fc corev1.FCVolumeSource
 ---@field flexVolume corev1.FlexPersistentVolumeSource
@@ -1198,27 +1227,21 @@
 ---@field glusterfs corev1.GlusterfsPersistentVolumeSource
 ---@field hostPath corev1.HostPathVolumeSource
 ---@field iscsi corev1.ISCSIPersistentVolumeSource
----@field local corev1.LocalVolumeSource
+---@field ["local"] corev1.LocalVolumeSource
+---@field mountOptions string[]
 ---@field nfs corev1.NFSVolumeSource
+---@field nodeAffinity corev1.VolumeNodeAffinity
+---@field persistentVolumeReclaimPolicy string
 ---@field photonPersistentDisk corev1.PhotonPersistentDiskVolumeSource
 ---@field portworxVolume corev1.PortworxVolumeSource
 ---@field quobyte corev1.QuobyteVolumeSource
 ---@field rbd corev1.RBDPersistentVolumeSource
 ---@field scaleIO corev1.ScaleIOPersistentVolumeSource
----@field storageos corev1.StorageOSPersistentVolumeSource
----@field vsphereVolume corev1.VsphereVirtualDiskVolumeSource
-
----@class corev1.PersistentVolumeSpec
----@field PersistentVolumeSource corev1.PersistentVolumeSource
----@field accessModes string[]
----@field capacity table<string, resource.Quantity>
----@field claimRef corev1.ObjectReference
----@field mountOptions string[]
----@field nodeAffinity corev1.VolumeNodeAffinity
----@field persistentVolumeReclaimPolicy string
 ---@field storageClassName string
+---@field storageos corev1.StorageOSPersistentVolumeSource
 ---@field volumeAttributesClassName string
 ---@field volumeMode string
+---@field vsphereVolume corev1.VsphereVirtualDiskVolumeSource
 
 ---@class corev1.PersistentVolumeStatus
 ---@field lastPhaseTransitionTime v1.Time
@@ -1231,7 +1254,8 @@
 ---@field pdID string
 
 ---@class corev1.Pod
----@field TypeMeta v1.TypeMeta
+---@field apiVersion string
+---@field kind string
 ---@field metadata v1.ObjectMeta
 ---@field spec corev1.PodSpec
 ---@field status corev1.PodStatus
@@ -1286,8 +1310,9 @@
 ---@field ip string
 
 ---@class corev1.PodList
----@field TypeMeta v1.TypeMeta
+---@field apiVersion string
 ---@field items corev1.Pod[]
+---@field kind string
 ---@field metadata v1.ListMeta
 
 ---@class corev1.PodOS
@@ -1344,7 +1369,7 @@
 ---@field nodeName string
 ---@field nodeSelector table<string, string>
 ---@field os corev1.PodOS
----@field overhead table<string, resource.Quantity>
+---@field overhead corev1.ResourceList
 ---@field preemptionPolicy string
 ---@field priority number
 ---@field priorityClassName string
@@ -1405,20 +1430,17 @@
 ---@field weight number
 
 ---@class corev1.Probe
----@field ProbeHandler corev1.ProbeHandler
+---@field exec corev1.ExecAction
 ---@field failureThreshold number
+---@field grpc corev1.GRPCAction
+---@field httpGet corev1.HTTPGetAction
 ---@field initialDelaySeconds number
 ---@field periodSeconds number
 ---@field successThreshold number
+---@field tcpSocket corev1.TCPSocketAction
 ---@field terminationGracePeriodSeconds number
 ---@field timeoutSeconds number
 
----@class corev1.ProbeHandler
----@field exec corev1.ExecAction
----@field grpc corev1.GRPCAction
----@field httpGet corev1.HTTPGetAction
----@field tcpSocket corev1.TCPSocketAction
-
 ---@class corev1.ProjectedVolumeSource
 ---@field defaultMode number
 ---@field sources corev1.VolumeProjection[]
@@ -1466,8 +1488,8 @@
 
 ---@class corev1.ResourceRequirements
 ---@field claims corev1.ResourceClaim[]
----@field limits table<string, resource.Quantity>
----@field requests table<string, resource.Quantity>
+---@field limits corev1.ResourceList
+---@field requests corev1.ResourceList
 
 ---@class corev1.ResourceStatus
 ---@field name string
@@ -1508,30 +1530,32 @@
 ---@field type string
 
 ---@class corev1.Secret
----@field TypeMeta v1.TypeMeta
+---@field apiVersion string
 ---@field data table<string, number[]>
 ---@field immutable boolean
+---@field kind string
 ---@field metadata v1.ObjectMeta
 ---@field stringData table<string, string>
 ---@field type string
 
 ---@class corev1.SecretEnvSource
----@field LocalObjectReference corev1.LocalObjectReference
+---@field name string
 ---@field optional boolean
 
 ---@class corev1.SecretKeySelector
----@field LocalObjectReference corev1.LocalObjectReference
 ---@field key string
+---@field name string
 ---@field optional boolean
 
 ---@class corev1.SecretList
----@field TypeMeta v1.TypeMeta
+---@field apiVersion string
 ---@field items corev1.Secret[]
+---@field kind string
 ---@field metadata v1.ListMeta
 
 ---@class corev1.SecretProjection
----@field LocalObjectReference corev1.LocalObjectReference
 ---@field items corev1.KeyToPath[]
+---@field name string
 ---@field optional boolean
 
 ---@class corev1.SecretReference
@@ -1559,21 +1583,24 @@
 ---@field windowsOptions corev1.WindowsSecurityContextOptions
 
 ---@class corev1.Service
----@field TypeMeta v1.TypeMeta
+---@field apiVersion string
+---@field kind string
 ---@field metadata v1.ObjectMeta
 ---@field spec corev1.ServiceSpec
 ---@field status corev1.ServiceStatus
 
 ---@class corev1.ServiceAccount
----@field TypeMeta v1.TypeMeta
+---@field apiVersion string
 ---@field automountServiceAccountToken boolean
 ---@field imagePullSecrets corev1.LocalObjectReference[]
+---@field kind string
 ---@field metadata v1.ObjectMeta
 ---@field secrets corev1.ObjectReference[]
 
 ---@class corev1.ServiceAccountList
----@field TypeMeta v1.TypeMeta
+---@field apiVersion string
 ---@field items corev1.ServiceAccount[]
+---@field kind string
 ---@field metadata v1.ListMeta
 
 ---@class corev1.ServiceAccountTokenProjection
@@ -1582,8 +1609,9 @@
 ---@field path string
 
 ---@class corev1.ServiceList
----@field TypeMeta v1.TypeMeta
+---@field apiVersion string
 ---@field items corev1.Service[]
+---@field kind string
 ---@field metadata v1.ListMeta
 
 ---@class corev1.ServicePort
@@ -1690,8 +1718,37 @@
 ---@field namespace string
 
 ---@class corev1.Volume
----@field VolumeSource corev1.VolumeSource
+---@field awsElasticBlockStore corev1.AWSElasticBlockStoreVolumeSource
+---@field azureDisk corev1.AzureDiskVolumeSource
+---@field azureFile corev1.AzureFileVolumeSource
+---@field cephfs corev1.CephFSVolumeSource
+---@field cinder corev1.CinderVolumeSource
+---@field configMap corev1.ConfigMapVolumeSource
+---@field csi corev1.CSIVolumeSource
+---@field downwardAPI corev1.DownwardAPIVolumeSource
+---@field emptyDir corev1.EmptyDirVolumeSource
+---@field ephemeral corev1.EphemeralVolumeSource
+---@field fc corev1.FCVolumeSource
+---@field flexVolume corev1.FlexVolumeSource
+---@field flocker corev1.FlockerVolumeSource
+---@field gcePersistentDisk corev1.GCEPersistentDiskVolumeSource
+---@field gitRepo corev1.GitRepoVolumeSource
+---@field glusterfs corev1.GlusterfsVolumeSource
+---@field hostPath corev1.HostPathVolumeSource
+---@field image corev1.ImageVolumeSource
+---@field iscsi corev1.ISCSIVolumeSource
 ---@field name string
+---@field nfs corev1.NFSVolumeSource
+---@field persistentVolumeClaim corev1.PersistentVolumeClaimVolumeSource
+---@field photonPersistentDisk corev1.PhotonPersistentDiskVolumeSource
+---@field portworxVolume corev1.PortworxVolumeSource
+---@field projected corev1.ProjectedVolumeSource
+---@field quobyte corev1.QuobyteVolumeSource
+---@field rbd corev1.RBDVolumeSource
+---@field scaleIO corev1.ScaleIOVolumeSource
+---@field secret corev1.SecretVolumeSource
+---@field storageos corev1.StorageOSVolumeSource
+---@field vsphereVolume corev1.VsphereVirtualDiskVolumeSource
 
 ---@class corev1.VolumeDevice
 ---@field devicePath string
@@ -1724,40 +1781,8 @@
 ---@field serviceAccountToken corev1.ServiceAccountTokenProjection
 
 ---@class corev1.VolumeResourceRequirements
----@field limits table<string, resource.Quantity>
----@field requests table<string, resource.Quantity>
-
----@class corev1.VolumeSource
----@field awsElasticBlockStore corev1.AWSElasticBlockStoreVolumeSource
----@field azureDisk corev1.AzureDiskVolumeSource
----@field azureFile corev1.AzureFileVolumeSource
----@field cephfs corev1.CephFSVolumeSource
----@field cinder corev1.CinderVolumeSource
----@field configMap corev1.ConfigMapVolumeSource
----@field csi corev1.CSIVolumeSource
----@field downwardAPI corev1.DownwardAPIVolumeSource
----@field emptyDir corev1.EmptyDirVolumeSource
----@field ephemeral corev1.EphemeralVolumeSource
----@field fc corev1.FCVolumeSource
----@field flexVolume corev1.FlexVolumeSource
----@field flocker corev1.FlockerVolumeSource
----@field gcePersistentDisk corev1.GCEPersistentDiskVolumeSource
----@field gitRepo corev1.GitRepoVolumeSource
----@field glusterfs corev1.GlusterfsVolumeSource
----@field hostPath corev1.HostPathVolumeSource
----@field image corev1.ImageVolumeSource
----@field iscsi corev1.ISCSIVolumeSource
----@field nfs corev1.NFSVolumeSource
----@field persistentVolumeClaim corev1.PersistentVolumeClaimVolumeSource
----@field photonPersistentDisk corev1.PhotonPersistentDiskVolumeSource
----@field portworxVolume corev1.PortworxVolumeSource
----@field projected corev1.ProjectedVolumeSource
----@field quobyte corev1.QuobyteVolumeSource
----@field rbd corev1.RBDVolumeSource
----@field scaleIO corev1.ScaleIOVolumeSource
----@field secret corev1.SecretVolumeSource
----@field storageos corev1.StorageOSVolumeSource
----@field vsphereVolume corev1.VsphereVirtualDiskVolumeSource
+---@field limits corev1.ResourceList
+---@field requests corev1.ResourceList
 
 ---@class corev1.VsphereVirtualDiskVolumeSource
 ---@field fsType string
@@ -1801,15 +1826,17 @@
 ---@field protocol string
 
 ---@class discoveryv1.EndpointSlice
----@field TypeMeta v1.TypeMeta
 ---@field addressType string
+---@field apiVersion string
 ---@field endpoints discoveryv1.Endpoint[]
+---@field kind string
 ---@field metadata v1.ObjectMeta
 ---@field ports discoveryv1.EndpointPort[]
 
 ---@class discoveryv1.EndpointSliceList
----@field TypeMeta v1.TypeMeta
+---@field apiVersion string
 ---@field items discoveryv1.EndpointSlice[]
+---@field kind string
 ---@field metadata v1.ListMeta
 
 ---@class discoveryv1.ForNode
@@ -1819,13 +1846,14 @@
 ---@field name string
 
 ---@class eventsv1.Event
----@field TypeMeta v1.TypeMeta
 ---@field action string
+---@field apiVersion string
 ---@field deprecatedCount number
 ---@field deprecatedFirstTimestamp v1.Time
 ---@field deprecatedLastTimestamp v1.Time
 ---@field deprecatedSource corev1.EventSource
 ---@field eventTime v1.MicroTime
+---@field kind string
 ---@field metadata v1.ObjectMeta
 ---@field note string
 ---@field reason string
@@ -1837,8 +1865,9 @@
 ---@field type string
 
 ---@class eventsv1.EventList
----@field TypeMeta v1.TypeMeta
+---@field apiVersion string
 ---@field items eventsv1.Event[]
+---@field kind string
 ---@field metadata v1.ListMeta
 
 ---@class eventsv1.EventSeries
@@ -1858,7 +1887,8 @@
 ---@field except string[]
 
 ---@class networkingv1.Ingress
----@field TypeMeta v1.TypeMeta
+---@field apiVersion string
+---@field kind string
 ---@field metadata v1.ObjectMeta
 ---@field spec networkingv1.IngressSpec
 ---@field status networkingv1.IngressStatus
@@ -1868,8 +1898,9 @@
 ---@field service networkingv1.IngressServiceBackend
 
 ---@class networkingv1.IngressList
----@field TypeMeta v1.TypeMeta
+---@field apiVersion string
 ---@field items networkingv1.Ingress[]
+---@field kind string
 ---@field metadata v1.ListMeta
 
 ---@class networkingv1.IngressLoadBalancerIngress
@@ -1886,10 +1917,7 @@
 ---@field protocol string
 
 ---@class networkingv1.IngressRule
----@field IngressRuleValue networkingv1.IngressRuleValue
 ---@field host string
-
----@class networkingv1.IngressRuleValue
 ---@field http networkingv1.HTTPIngressRuleValue
 
 ---@class networkingv1.IngressServiceBackend
@@ -1910,7 +1938,8 @@
 ---@field secretName string
 
 ---@class networkingv1.NetworkPolicy
----@field TypeMeta v1.TypeMeta
+---@field apiVersion string
+---@field kind string
 ---@field metadata v1.ObjectMeta
 ---@field spec networkingv1.NetworkPolicySpec
 
@@ -1923,8 +1952,9 @@
 ---@field ports networkingv1.NetworkPolicyPort[]
 
 ---@class networkingv1.NetworkPolicyList
----@field TypeMeta v1.TypeMeta
+---@field apiVersion string
 ---@field items networkingv1.NetworkPolicy[]
+---@field kind string
 ---@field metadata v1.ListMeta
 
 ---@class networkingv1.NetworkPolicyPeer
@@ -1948,14 +1978,16 @@
 ---@field number number
 
 ---@class policyv1.PodDisruptionBudget
----@field TypeMeta v1.TypeMeta
+---@field apiVersion string
+---@field kind string
 ---@field metadata v1.ObjectMeta
 ---@field spec policyv1.PodDisruptionBudgetSpec
 ---@field status policyv1.PodDisruptionBudgetStatus
 
 ---@class policyv1.PodDisruptionBudgetList
----@field TypeMeta v1.TypeMeta
+---@field apiVersion string
 ---@field items policyv1.PodDisruptionBudget[]
+---@field kind string
 ---@field metadata v1.ListMeta
 
 ---@class policyv1.PodDisruptionBudgetSpec
@@ -1977,25 +2009,29 @@
 ---@field clusterRoleSelectors v1.LabelSelector[]
 
 ---@class rbacv1.ClusterRole
----@field TypeMeta v1.TypeMeta
 ---@field aggregationRule rbacv1.AggregationRule
+---@field apiVersion string
+---@field kind string
 ---@field metadata v1.ObjectMeta
 ---@field rules rbacv1.PolicyRule[]
 
 ---@class rbacv1.ClusterRoleBinding
----@field TypeMeta v1.TypeMeta
+---@field apiVersion string
+---@field kind string
 ---@field metadata v1.ObjectMeta
 ---@field roleRef rbacv1.RoleRef
 ---@field subjects rbacv1.Subject[]
 
 ---@class rbacv1.ClusterRoleBindingList
----@field TypeMeta v1.TypeMeta
+---@field apiVersion string
 ---@field items rbacv1.ClusterRoleBinding[]
+---@field kind string
 ---@field metadata v1.ListMeta
 
 ---@class rbacv1.ClusterRoleList
----@field TypeMeta v1.TypeMeta
+---@field apiVersion string
 ---@field items rbacv1.ClusterRole[]
+---@field kind string
 ---@field metadata v1.ListMeta
 
 ---@class rbacv1.PolicyRule
@@ -2006,24 +2042,28 @@
 ---@field verbs string[]
 
 ---@class rbacv1.Role
----@field TypeMeta v1.TypeMeta
+---@field apiVersion string
+---@field kind string
 ---@field metadata v1.ObjectMeta
 ---@field rules rbacv1.PolicyRule[]
 
 ---@class rbacv1.RoleBinding
----@field TypeMeta v1.TypeMeta
+---@field apiVersion string
+---@field kind string
 ---@field metadata v1.ObjectMeta
 ---@field roleRef rbacv1.RoleRef
 ---@field subjects rbacv1.Subject[]
 
 ---@class rbacv1.RoleBindingList
----@field TypeMeta v1.TypeMeta
+---@field apiVersion string
 ---@field items rbacv1.RoleBinding[]
+---@field kind string
 ---@field metadata v1.ListMeta
 
 ---@class rbacv1.RoleList
----@field TypeMeta v1.TypeMeta
+---@field apiVersion string
 ---@field items rbacv1.Role[]
+---@field kind string
 ---@field metadata v1.ListMeta
 
 ---@class rbacv1.RoleRef
@@ -2038,9 +2078,10 @@
 ---@field namespace string
 
 ---@class storagev1.StorageClass
----@field TypeMeta v1.TypeMeta
 ---@field allowVolumeExpansion boolean
 ---@field allowedTopologies corev1.TopologySelectorTerm[]
+---@field apiVersion string
+---@field kind string
 ---@field metadata v1.ObjectMeta
 ---@field mountOptions string[]
 ---@field parameters table<string, string>
@@ -2049,19 +2090,22 @@
 ---@field volumeBindingMode string
 
 ---@class storagev1.StorageClassList
----@field TypeMeta v1.TypeMeta
+---@field apiVersion string
 ---@field items storagev1.StorageClass[]
+---@field kind string
 ---@field metadata v1.ListMeta
 
 ---@class storagev1.VolumeAttachment
----@field TypeMeta v1.TypeMeta
+---@field apiVersion string
+---@field kind string
 ---@field metadata v1.ObjectMeta
 ---@field spec storagev1.VolumeAttachmentSpec
 ---@field status storagev1.VolumeAttachmentStatus
 
 ---@class storagev1.VolumeAttachmentList
----@field TypeMeta v1.TypeMeta
+---@field apiVersion string
 ---@field items storagev1.VolumeAttachment[]
+---@field kind string
 ---@field metadata v1.ListMeta
 
 ---@class storagev1.VolumeAttachmentSource
@@ -2142,9 +2186,10 @@
 ---@field uid string
 
 ---@class v1.Status
----@field TypeMeta v1.TypeMeta
+---@field apiVersion string
 ---@field code number
 ---@field details v1.StatusDetails
+---@field kind string
 ---@field message string
 ---@field metadata v1.ListMeta
 ---@field reason string
@@ -2168,7 +2213,8 @@
 ---@field kind string
 
 ---@class v1.APIService
----@field TypeMeta v1.TypeMeta
+---@field apiVersion string
+---@field kind string
 ---@field metadata v1.ObjectMeta
 ---@field spec v1.APIServiceSpec
 ---@field status v1.APIServiceStatus
@@ -2181,8 +2227,9 @@
 ---@field type string
 
 ---@class v1.APIServiceList
----@field TypeMeta v1.TypeMeta
+---@field apiVersion string
 ---@field items v1.APIService[]
+---@field kind string
 ---@field metadata v1.ListMeta
 
 ---@class v1.APIServiceSpec
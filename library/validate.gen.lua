@@ -0,0 +1,17 @@
+---@meta validate
+
+---@class validate
+local validate = {}
+
+---@param value any The value to validate
+---@param type_name string The registered type name (e.g., "corev1.Pod")
+---@return boolean ok Whether the value matches the type
+---@return string[]|nil errs The problems found, each prefixed with its path
+function validate.check(value, type_name) end
+
+---@param value any The value to validate
+---@param type_name string The registered type name (e.g., "corev1.Pod")
+---@return any value The value, unchanged
+function validate.assert(value, type_name) end
+
+return validate
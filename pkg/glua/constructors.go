@@ -0,0 +1,233 @@
+// Copyright (c) 2024-2025 Thomas Maurice
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package glua
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	lua "github.com/yuin/gopher-lua"
+)
+
+// TypeMetatableField: metatable field holding the registered type name of tables built by constructors
+const TypeMetatableField = "__type"
+
+// RegisterDefault: registers a type and the value its constructor starts from.
+// Fields set in obj are used as defaults by ConstructorsLoader, instead of the zero value.
+//
+// Example:
+//
+//	registry.RegisterDefault(&corev1.Container{ImagePullPolicy: corev1.PullIfNotPresent})
+func (r *TypeRegistry) RegisterDefault(obj interface{}) error {
+	if err := r.Register(obj); err != nil {
+		return err
+	}
+
+	t := r.unwrapPointer(reflect.TypeOf(obj))
+	if t.Kind() != reflect.Struct || t.Name() == "" {
+		return fmt.Errorf("cannot register default for non-struct type %s", t)
+	}
+
+	r.defaults[r.getTypeKey(t)] = obj
+	return nil
+}
+
+// ConstructorsLoader: returns a loader for a Lua module exposing a constructor for each
+// registered struct, grouped by package (e.g., types.corev1.Container).
+// Constructors start from the JSON encoding of the registered default (or zero value),
+// override it with the given fields, raise an error listing unknown and wrongly typed
+// fields, and mark the table with its type so FromLua can check it.
+// Call Process() before loading the module. Loading it raises an error if types of
+// different packages share a Lua name (two packages both imported as v1, for instance).
+//
+// Example:
+//
+//	L.PreloadModule("types", registry.ConstructorsLoader())
+//
+// Lua usage:
+//
+//	local types = require("types")
+//	local c = types.corev1.Container{ name = "nginx", image = "nginx:latest" }
+func (r *TypeRegistry) ConstructorsLoader() lua.LGFunction {
+	return func(L *lua.LState) int {
+		types, err := r.constructorTypes()
+		if err != nil {
+			L.RaiseError("%s", err.Error())
+			return 0
+		}
+
+		mod := L.NewTable()
+		for _, typeInfo := range types {
+			pkgName, typeName := splitTypeName(typeInfo.Name)
+
+			pkg, ok := mod.RawGetString(pkgName).(*lua.LTable)
+			if !ok {
+				pkg = L.NewTable()
+				mod.RawSetString(pkgName, pkg)
+			}
+
+			pkg.RawSetString(typeName, L.NewFunction(r.newConstructor(L, typeInfo)))
+		}
+
+		L.Push(mod)
+		return 1
+	}
+}
+
+// newConstructor: builds the constructor of a registered struct type
+func (r *TypeRegistry) newConstructor(L *lua.LState, typeInfo *TypeInfo) lua.LGFunction {
+	translator := NewTranslator()
+	metatable := L.NewTable()
+	metatable.RawSetString(TypeMetatableField, lua.LString(typeInfo.Name))
+
+	return func(L *lua.LState) int {
+		fields := L.OptTable(1, L.NewTable())
+
+		obj, ok := r.defaults[r.getTypeKey(typeInfo.GoType)]
+		if !ok {
+			obj = reflect.New(typeInfo.GoType).Interface()
+		}
+
+		value, err := translator.ToLua(L, obj)
+		if err != nil {
+			L.RaiseError("failed to build default %s: %v", typeInfo.Name, err)
+			return 0
+		}
+
+		tbl, ok := value.(*lua.LTable)
+		if !ok {
+			L.RaiseError("default %s is not a table", typeInfo.Name)
+			return 0
+		}
+
+		fields.ForEach(func(key, val lua.LValue) {
+			tbl.RawSet(key, val)
+		})
+
+		// Required fields are not enforced, the defaults may leave them empty
+		if err := r.validate(tbl, typeInfo.GoType, false); err != nil {
+			L.RaiseError("invalid %s: %v", typeInfo.Name, err)
+			return 0
+		}
+
+		L.SetMetatable(tbl, metatable)
+		L.Push(tbl)
+		return 1
+	}
+}
+
+// constructorTypes: returns the registered named structs, sorted by name.
+// Returns an error if types of different packages share a Lua name, as their
+// constructors would replace each other.
+func (r *TypeRegistry) constructorTypes() ([]*TypeInfo, error) {
+	types := make([]*TypeInfo, 0, len(r.types))
+	keys := make(map[string][]string)
+	for key, typeInfo := range r.types {
+		if typeInfo.GoType == nil || typeInfo.GoType.Kind() != reflect.Struct || r.customSchema(typeInfo.GoType) != nil {
+			continue
+		}
+		if !strings.Contains(typeInfo.Name, ".") {
+			continue
+		}
+		types = append(types, typeInfo)
+		keys[typeInfo.Name] = append(keys[typeInfo.Name], key)
+	}
+
+	var ambiguous []string
+	for _, name := range sortedKeys(keys) {
+		if len(keys[name]) > 1 {
+			sort.Strings(keys[name])
+			ambiguous = append(ambiguous, fmt.Sprintf("ambiguous type %q, registered for %s", name, strings.Join(keys[name], ", ")))
+		}
+	}
+	if len(ambiguous) > 0 {
+		return nil, fmt.Errorf("%s", strings.Join(ambiguous, "; "))
+	}
+
+	sort.Slice(types, func(i, j int) bool { return types[i].Name < types[j].Name })
+	return types, nil
+}
+
+// GenerateConstructorStubs: generates the LSP stubs of the module returned by
+// ConstructorsLoader, to be written next to the GenerateStubs output.
+// Constructors accept any table so omitted fields are not reported as missing,
+// field names are still completed from the class.
+//
+// Example output:
+//
+//	---@class types.corev1
+//	---@field Container fun(fields?: corev1.Container|table): corev1.Container
+func (r *TypeRegistry) GenerateConstructorStubs(moduleName string) (string, error) {
+	if !IsLuaIdentifier(moduleName) {
+		return "", fmt.Errorf("invalid Lua module name %q", moduleName)
+	}
+
+	types, err := r.constructorTypes()
+	if err != nil {
+		return "", err
+	}
+
+	packages := make(map[string][]*TypeInfo)
+	for _, typeInfo := range types {
+		pkgName, _ := splitTypeName(typeInfo.Name)
+		packages[pkgName] = append(packages[pkgName], typeInfo)
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("---@meta %s\n\n", moduleName))
+
+	sb.WriteString(fmt.Sprintf("---@class %s\n", moduleName))
+	for _, pkgName := range sortedKeys(packages) {
+		sb.WriteString(fmt.Sprintf("---@field %s %s.%s\n", pkgName, moduleName, pkgName))
+	}
+	sb.WriteString(fmt.Sprintf("local %s = {}\n\n", moduleName))
+
+	for _, pkgName := range sortedKeys(packages) {
+		sb.WriteString(fmt.Sprintf("---@class %s.%s\n", moduleName, pkgName))
+		for _, typeInfo := range packages[pkgName] {
+			_, typeName := splitTypeName(typeInfo.Name)
+			sb.WriteString(fmt.Sprintf("---@field %s fun(fields?: %s|table): %s\n", typeName, typeInfo.Name, typeInfo.Name))
+		}
+		sb.WriteString("\n")
+	}
+
+	sb.WriteString(fmt.Sprintf("return %s\n", moduleName))
+
+	return sb.String(), nil
+}
+
+// MarkedType: returns the type name a constructor marked a Lua table with
+func MarkedType(lv lua.LValue) (string, bool) {
+	tbl, ok := lv.(*lua.LTable)
+	if !ok {
+		return "", false
+	}
+
+	metatable, ok := tbl.Metatable.(*lua.LTable)
+	if !ok {
+		return "", false
+	}
+
+	name, ok := metatable.RawGetString(TypeMetatableField).(lua.LString)
+	return string(name), ok
+}
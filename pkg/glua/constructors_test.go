@@ -0,0 +1,193 @@
+// Copyright (c) 2024-2025 Thomas Maurice
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package glua
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	lua "github.com/yuin/gopher-lua"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// newConstructorsState: creates a Lua state with the types module of a Pod registry preloaded
+func newConstructorsState(t *testing.T) (*lua.LState, *TypeRegistry) {
+	t.Helper()
+
+	registry := NewTypeRegistry()
+	if err := registry.Register(&corev1.Pod{}); err != nil {
+		t.Fatalf("Register failed: %v", err)
+	}
+	if err := registry.RegisterDefault(&corev1.Container{ImagePullPolicy: corev1.PullIfNotPresent}); err != nil {
+		t.Fatalf("RegisterDefault failed: %v", err)
+	}
+	if err := registry.Process(); err != nil {
+		t.Fatalf("Process failed: %v", err)
+	}
+
+	L := lua.NewState()
+	L.PreloadModule("types", registry.ConstructorsLoader())
+
+	return L, registry
+}
+
+func TestTypeRegistry_Constructors(t *testing.T) {
+	L, _ := newConstructorsState(t)
+	defer L.Close()
+
+	script := `
+		local types = require("types")
+
+		local c = types.corev1.Container{ name = "nginx", image = "nginx:latest" }
+		assert(c.name == "nginx", "name not set")
+		assert(c.image == "nginx:latest", "image not set")
+		assert(c.imagePullPolicy == "IfNotPresent", "registered default not applied")
+		assert(type(c.resources) == "table", "zero value default not applied")
+		assert(getmetatable(c).__type == "corev1.Container", "table not marked")
+
+		local always = types.corev1.Container{ name = "app", imagePullPolicy = "Always" }
+		assert(always.imagePullPolicy == "Always", "default not overridden")
+
+		local port = types.corev1.ContainerPort{ containerPort = 80 }
+		assert(port.containerPort == 80, "containerPort not set")
+
+		local empty = types.corev1.PodSpec()
+		assert(getmetatable(empty).__type == "corev1.PodSpec", "empty constructor not marked")
+
+		local ok, err = pcall(types.corev1.Container, { name = "x", imagee = "typo" })
+		assert(not ok, "unknown field accepted")
+		assert(string.find(err, "imagee: unknown field of corev1.Container", 1, true), err)
+
+		ok, err = pcall(types.corev1.ContainerPort, { containerPort = "80" })
+		assert(not ok, "wrong type accepted")
+		assert(string.find(err, "invalid corev1.ContainerPort", 1, true), err)
+	`
+
+	if err := L.DoString(script); err != nil {
+		t.Fatalf("Lua script failed: %v", err)
+	}
+}
+
+func TestTypeRegistry_ConstructorsAmbiguousNames(t *testing.T) {
+	L, registry := newConstructorsState(t)
+	defer L.Close()
+
+	// Two packages whose types get the same Lua name
+	registry.types["example.com/core/v1.Container"] = &TypeInfo{Name: "corev1.Container", GoType: reflect.TypeOf(struct{}{})}
+	expected := `ambiguous type "corev1.Container", registered for example.com/core/v1.Container, k8s.io/api/core/v1.Container`
+
+	err := L.DoString(`require("types")`)
+	if err == nil || !strings.Contains(err.Error(), expected) {
+		t.Errorf("Expected loading the module to fail with %q, got %v", expected, err)
+	}
+
+	if _, err := registry.GenerateConstructorStubs("types"); err == nil || err.Error() != expected {
+		t.Errorf("Expected %q, got %v", expected, err)
+	}
+}
+
+func TestTranslator_FromLuaMarkedTables(t *testing.T) {
+	L, registry := newConstructorsState(t)
+	defer L.Close()
+
+	if err := L.DoString(`
+		local types = require("types")
+		container = types.corev1.Container{ name = "nginx", image = "nginx:latest" }
+		broken = types.corev1.Container{ name = "nginx" }
+		broken.ports = "80"
+	`); err != nil {
+		t.Fatalf("Lua script failed: %v", err)
+	}
+
+	translator := NewTranslatorWithRegistry(registry)
+
+	var container corev1.Container
+	if err := translator.FromLua(L, L.GetGlobal("container"), &container); err != nil {
+		t.Fatalf("FromLua failed: %v", err)
+	}
+	if container.Image != "nginx:latest" || container.ImagePullPolicy != corev1.PullIfNotPresent {
+		t.Errorf("Unexpected container: %+v", container)
+	}
+
+	var pod corev1.Pod
+	err := translator.FromLua(L, L.GetGlobal("container"), &pod)
+	if err == nil || !strings.Contains(err.Error(), "cannot convert corev1.Container table to corev1.Pod") {
+		t.Errorf("Expected type mismatch error, got: %v", err)
+	}
+
+	err = translator.FromLua(L, L.GetGlobal("broken"), &container)
+	if err == nil || !strings.Contains(err.Error(), "ports: expected array, got string") {
+		t.Errorf("Expected validation error, got: %v", err)
+	}
+
+	// Without a registry, marked tables are converted as before
+	var generic map[string]interface{}
+	if err := NewTranslator().FromLua(L, L.GetGlobal("container"), &generic); err != nil {
+		t.Errorf("FromLua without registry failed: %v", err)
+	}
+}
+
+func TestTypeRegistry_GenerateConstructorStubs(t *testing.T) {
+	type Settings struct {
+		Name string `json:"name"`
+	}
+
+	registry := NewTypeRegistry()
+	if err := registry.Register(&Settings{}); err != nil {
+		t.Fatalf("Register failed: %v", err)
+	}
+	if err := registry.Register(&corev1.ContainerPort{}); err != nil {
+		t.Fatalf("Register failed: %v", err)
+	}
+	if err := registry.Process(); err != nil {
+		t.Fatalf("Process failed: %v", err)
+	}
+
+	stubs, err := registry.GenerateConstructorStubs("types")
+	if err != nil {
+		t.Fatalf("GenerateConstructorStubs failed: %v", err)
+	}
+
+	expected := `---@meta types
+
+---@class types
+---@field corev1 types.corev1
+---@field glua types.glua
+local types = {}
+
+---@class types.corev1
+---@field ContainerPort fun(fields?: corev1.ContainerPort|table): corev1.ContainerPort
+
+---@class types.glua
+---@field Settings fun(fields?: glua.Settings|table): glua.Settings
+
+return types
+`
+
+	if stubs != expected {
+		t.Errorf("Unexpected stubs.\nExpected:\n%s\nGot:\n%s", expected, stubs)
+	}
+
+	if _, err := registry.GenerateConstructorStubs("not-valid"); err == nil {
+		t.Error("Expected error for invalid module name, got nil")
+	}
+}
@@ -0,0 +1,232 @@
+// Copyright (c) 2024-2025 Thomas Maurice
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package glua
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strings"
+	"unicode"
+
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+)
+
+// RegisterCRD: registers the types described by a CustomResourceDefinition.
+// Every served version with an openAPIV3Schema generates a class named after the
+// group and version (e.g., group example.com, version v1, kind Widget gives
+// examplecomv1.Widget), and nested objects generate classes named after their
+// path (e.g., examplecomv1.WidgetSpec). Descriptions are kept and enums become
+// literal unions. metadata always uses the ObjectMeta class.
+//
+// Example:
+//
+//	var crd apiextensionsv1.CustomResourceDefinition
+//	registry.RegisterCRD(&crd)
+//	registry.Process()
+func (r *TypeRegistry) RegisterCRD(crd *apiextensionsv1.CustomResourceDefinition) error {
+	if crd == nil {
+		return fmt.Errorf("cannot register nil CRD")
+	}
+
+	kind := crd.Spec.Names.Kind
+	if kind == "" {
+		return fmt.Errorf("CRD %s has no kind", crd.Name)
+	}
+
+	registered := 0
+	for _, version := range crd.Spec.Versions {
+		if !version.Served || version.Schema == nil || version.Schema.OpenAPIV3Schema == nil {
+			continue
+		}
+
+		prefix := crdPackageName(crd.Spec.Group, version.Name)
+		keyPrefix := crd.Spec.Group + "/" + version.Name
+		r.processCRDRoot(prefix, keyPrefix, kind, version.Schema.OpenAPIV3Schema)
+		registered++
+	}
+
+	if registered == 0 {
+		return fmt.Errorf("CRD %s has no served version with an openAPIV3Schema", crd.Name)
+	}
+
+	return nil
+}
+
+// RegisterCRDYAML: decodes one or more CustomResourceDefinition documents
+// (YAML or JSON, separated by ---) and registers them with RegisterCRD.
+func (r *TypeRegistry) RegisterCRDYAML(data []byte) error {
+	decoder := utilyaml.NewYAMLOrJSONDecoder(bytes.NewReader(data), 4096)
+
+	for {
+		var crd apiextensionsv1.CustomResourceDefinition
+		if err := decoder.Decode(&crd); err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return fmt.Errorf("failed to decode CRD: %w", err)
+		}
+
+		// Skip empty documents
+		if crd.Kind == "" && crd.Name == "" {
+			continue
+		}
+
+		if crd.Kind != "CustomResourceDefinition" {
+			return fmt.Errorf("expected kind CustomResourceDefinition, got %q", crd.Kind)
+		}
+
+		if err := r.RegisterCRD(&crd); err != nil {
+			return err
+		}
+	}
+}
+
+// processCRDRoot: registers the class of a custom resource. The apiVersion, kind and
+// metadata fields are always present since the API server serves them for every resource.
+func (r *TypeRegistry) processCRDRoot(prefix, keyPrefix, kind string, schema *apiextensionsv1.JSONSchemaProps) {
+	typeInfo := r.processCRDObject(prefix, keyPrefix, kind, schema)
+
+	for _, name := range []string{"apiVersion", "kind"} {
+		if _, exists := typeInfo.Fields[name]; !exists {
+			typeInfo.Fields[name] = &FieldInfo{Name: name, TypeKey: "string", Optional: true}
+		}
+	}
+
+	metaType := reflect.TypeOf(metav1.ObjectMeta{})
+	metadata := &FieldInfo{Name: "metadata", TypeKey: r.processType(metaType), GoType: metaType, Optional: true}
+	if existing, exists := typeInfo.Fields["metadata"]; exists {
+		metadata.Description = existing.Description
+	}
+	typeInfo.Fields["metadata"] = metadata
+}
+
+// processCRDObject: registers a class for an object schema with properties
+func (r *TypeRegistry) processCRDObject(prefix, keyPrefix, name string, schema *apiextensionsv1.JSONSchemaProps) *TypeInfo {
+	typeInfo := &TypeInfo{
+		Name:        prefix + "." + name,
+		Description: schema.Description,
+		Fields:      make(map[string]*FieldInfo),
+	}
+	r.types[keyPrefix+"."+name] = typeInfo
+
+	required := make(map[string]bool)
+	for _, fieldName := range schema.Required {
+		required[fieldName] = true
+	}
+
+	propNames := make([]string, 0, len(schema.Properties))
+	for propName := range schema.Properties {
+		propNames = append(propNames, propName)
+	}
+	sort.Strings(propNames)
+
+	for _, propName := range propNames {
+		prop := schema.Properties[propName]
+		typeInfo.Fields[propName] = &FieldInfo{
+			Name:        propName,
+			TypeKey:     r.processCRDSchema(prefix, keyPrefix, name+crdTypeSuffix(propName), &prop),
+			Description: prop.Description,
+			Optional:    !required[propName],
+		}
+	}
+
+	return typeInfo
+}
+
+// processCRDSchema: returns the Lua type annotation of a schema, registering classes
+// for nested objects under the given name
+func (r *TypeRegistry) processCRDSchema(prefix, keyPrefix, name string, schema *apiextensionsv1.JSONSchemaProps) string {
+	if schema.XIntOrString {
+		return "number|string"
+	}
+
+	if len(schema.Enum) > 0 {
+		values := make([]string, len(schema.Enum))
+		for i, value := range schema.Enum {
+			values[i] = string(value.Raw)
+		}
+		return strings.Join(values, "|")
+	}
+
+	switch schema.Type {
+	case "string":
+		return "string"
+	case "integer", "number":
+		return "number"
+	case "boolean":
+		return "boolean"
+	case "array":
+		if schema.Items == nil || schema.Items.Schema == nil {
+			return "any[]"
+		}
+		elemKey := r.processCRDSchema(prefix, keyPrefix, name, schema.Items.Schema)
+		if strings.Contains(elemKey, "|") {
+			elemKey = "(" + elemKey + ")"
+		}
+		return elemKey + "[]"
+	case "object":
+		if len(schema.Properties) > 0 {
+			return r.processCRDObject(prefix, keyPrefix, name, schema).Name
+		}
+		if schema.AdditionalProperties != nil && schema.AdditionalProperties.Schema != nil {
+			return "table<string, " + r.processCRDSchema(prefix, keyPrefix, name, schema.AdditionalProperties.Schema) + ">"
+		}
+		return "table<string, any>"
+	}
+
+	return "any"
+}
+
+// crdPackageName: builds the Lua package prefix of a CRD version, following the
+// corev1 convention of core types (e.g., example.com + v1 gives examplecomv1)
+func crdPackageName(group, version string) string {
+	var sb strings.Builder
+	for _, ch := range strings.ToLower(group + version) {
+		if unicode.IsLetter(ch) || unicode.IsDigit(ch) {
+			sb.WriteRune(ch)
+		}
+	}
+	return sb.String()
+}
+
+// crdTypeSuffix: converts a property name to the suffix of a nested class name
+// (e.g., "spec" gives "Spec", "node-selector" gives "NodeSelector")
+func crdTypeSuffix(propName string) string {
+	var sb strings.Builder
+	upper := true
+	for _, ch := range propName {
+		if !unicode.IsLetter(ch) && !unicode.IsDigit(ch) {
+			upper = true
+			continue
+		}
+		if upper {
+			ch = unicode.ToUpper(ch)
+			upper = false
+		}
+		sb.WriteRune(ch)
+	}
+	return sb.String()
+}
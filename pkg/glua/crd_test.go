@@ -0,0 +1,206 @@
+// Copyright (c) 2024-2025 Thomas Maurice
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package glua
+
+import (
+	"strings"
+	"testing"
+)
+
+// widgetCRD: a CustomResourceDefinition with nested objects, enums and two versions
+const widgetCRD = `
+apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: widgets.example.com
+spec:
+  group: example.com
+  names:
+    kind: Widget
+    plural: widgets
+  scope: Namespaced
+  versions:
+    - name: v1
+      served: true
+      storage: true
+      schema:
+        openAPIV3Schema:
+          description: Widget is a configurable widget.
+          type: object
+          properties:
+            apiVersion:
+              type: string
+            kind:
+              type: string
+            metadata:
+              type: object
+            spec:
+              description: Desired state of the widget.
+              type: object
+              required: [size]
+              properties:
+                size:
+                  description: Number of replicas.
+                  type: integer
+                color:
+                  type: string
+                  enum: [red, green, blue]
+                max-surge:
+                  type: integer
+                port:
+                  x-kubernetes-int-or-string: true
+                labels:
+                  type: object
+                  additionalProperties:
+                    type: string
+                config:
+                  type: object
+                  x-kubernetes-preserve-unknown-fields: true
+                parts:
+                  type: array
+                  items:
+                    type: object
+                    properties:
+                      name:
+                        type: string
+                      weight:
+                        type: number
+            status:
+              type: object
+              properties:
+                ready:
+                  type: boolean
+    - name: v1alpha1
+      served: false
+      storage: false
+      schema:
+        openAPIV3Schema:
+          type: object
+`
+
+func TestTypeRegistry_RegisterCRDYAML(t *testing.T) {
+	registry := NewTypeRegistry()
+
+	if err := registry.RegisterCRDYAML([]byte(widgetCRD)); err != nil {
+		t.Fatalf("RegisterCRDYAML failed: %v", err)
+	}
+
+	if err := registry.Process(); err != nil {
+		t.Fatalf("Process failed: %v", err)
+	}
+
+	stubs, err := registry.GenerateStubs()
+	if err != nil {
+		t.Fatalf("GenerateStubs failed: %v", err)
+	}
+
+	expectedStrings := []string{
+		"--- Widget is a configurable widget.\n---@class examplecomv1.Widget\n",
+		"---@field apiVersion string\n",
+		"---@field metadata v1.ObjectMeta\n",
+		"---@field spec examplecomv1.WidgetSpec Desired state of the widget.\n",
+		"---@field status examplecomv1.WidgetStatus\n",
+		"---@class examplecomv1.WidgetSpec\n",
+		"---@field size number Number of replicas.\n",
+		`---@field color "red"|"green"|"blue"` + "\n",
+		"---@field port number|string\n",
+		`---@field ["max-surge"] number` + "\n",
+		"---@field labels table<string, string>\n",
+		"---@field config table<string, any>\n",
+		"---@field parts examplecomv1.WidgetSpecParts[]\n",
+		"---@class examplecomv1.WidgetSpecParts\n---@field name string\n---@field weight number\n",
+		"---@class examplecomv1.WidgetStatus\n---@field ready boolean\n",
+		"---@class v1.ObjectMeta\n",
+	}
+
+	for _, expected := range expectedStrings {
+		if !strings.Contains(stubs, expected) {
+			t.Errorf("Expected stubs to contain %q, got:\n%s", expected, stubs)
+		}
+	}
+
+	if strings.Contains(stubs, "examplecomv1alpha1") {
+		t.Error("Expected versions that are not served to be skipped")
+	}
+
+	spec := registry.types["example.com/v1.WidgetSpec"]
+	if spec == nil {
+		t.Fatal("Expected WidgetSpec to be registered")
+	}
+	if spec.Fields["size"].Optional {
+		t.Error("Expected required field size not to be optional")
+	}
+	if !spec.Fields["color"].Optional {
+		t.Error("Expected field color to be optional")
+	}
+}
+
+func TestTypeRegistry_RegisterCRDErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		yaml string
+	}{
+		{
+			name: "not a CRD",
+			yaml: "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: test\n",
+		},
+		{
+			name: "no served schema",
+			yaml: "apiVersion: apiextensions.k8s.io/v1\nkind: CustomResourceDefinition\nmetadata:\n  name: things.example.com\nspec:\n  group: example.com\n  names:\n    kind: Thing\n  versions:\n    - name: v1\n      served: true\n",
+		},
+		{
+			name: "invalid YAML",
+			yaml: "kind: [",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			registry := NewTypeRegistry()
+			if err := registry.RegisterCRDYAML([]byte(tt.yaml)); err == nil {
+				t.Error("Expected error, got nil")
+			}
+		})
+	}
+
+	registry := NewTypeRegistry()
+	if err := registry.RegisterCRD(nil); err == nil {
+		t.Error("Expected error for nil CRD, got nil")
+	}
+}
+
+func TestCRDPackageName(t *testing.T) {
+	tests := []struct {
+		group    string
+		version  string
+		expected string
+	}{
+		{"example.com", "v1", "examplecomv1"},
+		{"cert-manager.io", "v1", "certmanageriov1"},
+		{"Apps.Example.Com", "v1beta1", "appsexamplecomv1beta1"},
+	}
+
+	for _, tt := range tests {
+		if result := crdPackageName(tt.group, tt.version); result != tt.expected {
+			t.Errorf("crdPackageName(%q, %q) = %q, want %q", tt.group, tt.version, result, tt.expected)
+		}
+	}
+}
@@ -0,0 +1,259 @@
+// Copyright (c) 2024-2025 Thomas Maurice
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package glua
+
+import (
+	"fmt"
+	"go/ast"
+	"go/build"
+	"go/constant"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// packageConstants: cache of the constants declared in each Go package, by package path.
+// Each entry maps a type name to its constant values in declaration order.
+var packageConstants sync.Map
+
+// SetEnumDiscovery: enables or disables the discovery of constants in the source of
+// the packages declaring named primitive types (disabled by default). Discovered
+// constants become a closed set, which is wrong for types accepting other values,
+// such as corev1.ResourceName ("cpu", "memory" or any extended resource).
+// Discovery needs the Go toolchain and the package sources, types are emitted as
+// their primitive type when they cannot be found.
+func (r *TypeRegistry) SetEnumDiscovery(enabled bool) {
+	r.discoverEnums = enabled
+}
+
+// processEnumType: returns the alias name of a named primitive type with known
+// constants, or an empty string if the type has none.
+// Constants supplied with RegisterEnum take precedence over discovered ones.
+// The alias is declared the first time the type is seen; only Process() and the
+// Register functions get here, so the generators and Validate never write to the registry.
+func (r *TypeRegistry) processEnumType(t reflect.Type) string {
+	if t.Name() == "" || t.PkgPath() == "" || t.Kind() == reflect.Interface || r.customSchema(t) != nil {
+		return ""
+	}
+
+	typeKey := r.getTypeKey(t)
+	if _, ok := r.enums[typeKey]; !ok {
+		if _, discovered := r.constants[typeKey]; !discovered && r.discoverEnums {
+			r.constants[typeKey] = discoverConstants(t)
+		}
+		if len(r.constants[typeKey]) == 0 {
+			return ""
+		}
+	}
+
+	if _, exists := r.aliases[typeKey]; !exists {
+		r.aliases[typeKey] = &AliasInfo{Name: r.getTypeName(t), Definition: enumAlias(r.enumValues(typeKey))}
+	}
+	return r.aliases[typeKey].Name
+}
+
+// enumValues: returns the constants of an enum type, preferring RegisterEnum values
+func (r *TypeRegistry) enumValues(typeKey string) []interface{} {
+	if values, ok := r.enums[typeKey]; ok {
+		return values
+	}
+	return r.constants[typeKey]
+}
+
+// enumAlias: formats the values of an enum type as a Lua literal union (e.g., "Always"|"Never")
+func enumAlias(values []interface{}) string {
+	literals := make([]string, len(values))
+	for i, value := range values {
+		if str, ok := value.(string); ok {
+			literals[i] = fmt.Sprintf("%q", str)
+		} else {
+			literals[i] = fmt.Sprint(value)
+		}
+	}
+	return strings.Join(literals, "|")
+}
+
+// discoverConstants: returns the constants declared with type t in its package
+func discoverConstants(t reflect.Type) []interface{} {
+	cached, ok := packageConstants.Load(t.PkgPath())
+	if !ok {
+		cached, _ = packageConstants.LoadOrStore(t.PkgPath(), loadPackageConstants(t.PkgPath()))
+	}
+
+	var values []interface{}
+	seen := make(map[interface{}]bool)
+	for _, value := range cached.(map[string][]constant.Value)[t.Name()] {
+		if v := constantValue(value, t.Kind()); v != nil && !seen[v] {
+			seen[v] = true
+			values = append(values, v)
+		}
+	}
+
+	return values
+}
+
+// loadPackageConstants: parses the sources of a package and collects its typed constants.
+// Generated protobuf and deepcopy files are skipped since they never declare constants.
+// Returns an empty map if the package sources cannot be found.
+func loadPackageConstants(pkgPath string) map[string][]constant.Value {
+	constants := make(map[string][]constant.Value)
+
+	wd, err := os.Getwd()
+	if err != nil {
+		return constants
+	}
+
+	pkg, err := build.Default.Import(pkgPath, wd, 0)
+	if err != nil {
+		return constants
+	}
+
+	fset := token.NewFileSet()
+	for _, name := range pkg.GoFiles {
+		if strings.HasSuffix(name, ".pb.go") || strings.HasPrefix(name, "zz_generated") {
+			continue
+		}
+
+		file, err := parser.ParseFile(fset, filepath.Join(pkg.Dir, name), nil, parser.SkipObjectResolution)
+		if err != nil {
+			continue
+		}
+
+		for _, decl := range file.Decls {
+			if gen, ok := decl.(*ast.GenDecl); ok && gen.Tok == token.CONST {
+				collectConstants(gen, constants)
+			}
+		}
+	}
+
+	return constants
+}
+
+// collectConstants: evaluates a const declaration and records the typed constants,
+// handling iota and the implicit repetition of the previous type and expression
+func collectConstants(gen *ast.GenDecl, constants map[string][]constant.Value) {
+	var typeName string
+	var exprs []ast.Expr
+
+	for iota, spec := range gen.Specs {
+		valueSpec := spec.(*ast.ValueSpec)
+
+		if valueSpec.Type != nil || len(valueSpec.Values) > 0 {
+			typeName = ""
+			if ident, ok := valueSpec.Type.(*ast.Ident); ok {
+				typeName = ident.Name
+			}
+			exprs = valueSpec.Values
+		}
+
+		for i, name := range valueSpec.Names {
+			if i >= len(exprs) || name.Name == "_" {
+				continue
+			}
+
+			expr, exprType := exprs[i], typeName
+			// Untyped constants converted explicitly, e.g. const Foo = Kind("foo")
+			if call, ok := expr.(*ast.CallExpr); ok && exprType == "" && len(call.Args) == 1 {
+				if ident, ok := call.Fun.(*ast.Ident); ok {
+					exprType, expr = ident.Name, call.Args[0]
+				}
+			}
+			if exprType == "" {
+				continue
+			}
+
+			if value := evalConstant(expr, int64(iota)); value != nil {
+				constants[exprType] = append(constants[exprType], value)
+			}
+		}
+	}
+}
+
+// evalConstant: evaluates a constant expression made of literals, iota and operators.
+// Returns nil for expressions referencing other identifiers.
+func evalConstant(expr ast.Expr, iota int64) constant.Value {
+	switch e := expr.(type) {
+	case *ast.BasicLit:
+		return constant.MakeFromLiteral(e.Value, e.Kind, 0)
+	case *ast.Ident:
+		if e.Name == "iota" {
+			return constant.MakeInt64(iota)
+		}
+	case *ast.ParenExpr:
+		return evalConstant(e.X, iota)
+	case *ast.UnaryExpr:
+		if x := evalConstant(e.X, iota); x != nil && e.Op != token.NOT {
+			return constant.UnaryOp(e.Op, x, 0)
+		}
+	case *ast.BinaryExpr:
+		x, y := evalConstant(e.X, iota), evalConstant(e.Y, iota)
+		if x == nil || y == nil {
+			return nil
+		}
+		switch e.Op {
+		case token.SHL, token.SHR:
+			if s, ok := constant.Uint64Val(y); ok {
+				return constant.Shift(x, e.Op, uint(s))
+			}
+		case token.ADD, token.SUB, token.MUL, token.QUO, token.REM, token.AND, token.OR, token.XOR, token.AND_NOT:
+			if e.Op == token.QUO && x.Kind() == constant.Int && y.Kind() == constant.Int {
+				return constant.BinaryOp(x, token.QUO_ASSIGN, y)
+			}
+			return constant.BinaryOp(x, e.Op, y)
+		}
+	}
+
+	return nil
+}
+
+// constantValue: converts a constant to the value RegisterEnum would store for a type kind.
+// Returns nil if the constant does not fit the kind.
+func constantValue(value constant.Value, kind reflect.Kind) interface{} {
+	switch kind {
+	case reflect.String:
+		if value.Kind() == constant.String {
+			return constant.StringVal(value)
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if v, exact := constant.Int64Val(constant.ToInt(value)); exact {
+			return v
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if v, exact := constant.Uint64Val(constant.ToInt(value)); exact {
+			return v
+		}
+	case reflect.Float32, reflect.Float64:
+		if value.Kind() == constant.Int || value.Kind() == constant.Float {
+			v, _ := constant.Float64Val(constant.ToFloat(value))
+			return v
+		}
+	case reflect.Bool:
+		if value.Kind() == constant.Bool {
+			return constant.BoolVal(value)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,261 @@
+// Copyright (c) 2024-2025 Thomas Maurice
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package glua
+
+import (
+	"go/ast"
+	"go/constant"
+	"go/parser"
+	"go/token"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	lua "github.com/yuin/gopher-lua"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestTypeRegistry_DiscoveredEnumAliases(t *testing.T) {
+	registry := NewTypeRegistry()
+	registry.SetEnumDiscovery(true)
+	if err := registry.Register(&corev1.Container{}); err != nil {
+		t.Fatalf("Register failed: %v", err)
+	}
+	if err := registry.Process(); err != nil {
+		t.Fatalf("Process failed: %v", err)
+	}
+
+	stubs, err := registry.GenerateStubs()
+	if err != nil {
+		t.Fatalf("GenerateStubs failed: %v", err)
+	}
+
+	expectedStrings := []string{
+		`---@alias corev1.PullPolicy "Always"|"Never"|"IfNotPresent"` + "\n",
+		`---@alias corev1.Protocol "TCP"|"UDP"|"SCTP"` + "\n",
+		"---@field imagePullPolicy corev1.PullPolicy\n",
+		"---@field protocol corev1.Protocol\n",
+	}
+
+	for _, expected := range expectedStrings {
+		if !strings.Contains(stubs, expected) {
+			t.Errorf("Expected stubs to contain %q, got:\n%s", expected, stubs)
+		}
+	}
+
+	// Aliases are declared before the classes that reference them
+	if strings.Index(stubs, "---@alias") > strings.Index(stubs, "---@class") {
+		t.Error("Expected aliases to be declared before classes")
+	}
+}
+
+func TestTypeRegistry_RegisterEnumAlias(t *testing.T) {
+	type Settings struct {
+		Mode  Mode   `json:"mode"`
+		Other string `json:"other"`
+	}
+
+	registry := NewTypeRegistry()
+	if err := registry.RegisterEnum(ModeFast, ModeFast, ModeSafe); err != nil {
+		t.Fatalf("RegisterEnum failed: %v", err)
+	}
+	if err := registry.Register(&Settings{}); err != nil {
+		t.Fatalf("Register failed: %v", err)
+	}
+	if err := registry.Process(); err != nil {
+		t.Fatalf("Process failed: %v", err)
+	}
+
+	stubs, err := registry.GenerateStubs()
+	if err != nil {
+		t.Fatalf("GenerateStubs failed: %v", err)
+	}
+
+	expected := "---@alias glua.Mode \"fast\"|\"safe\"\n\n---@class glua.Settings\n---@field mode glua.Mode\n---@field other string\n"
+	if !strings.Contains(stubs, expected) {
+		t.Errorf("Expected stubs to contain %q, got:\n%s", expected, stubs)
+	}
+}
+
+func TestTypeRegistry_EnumDiscoveryDisabled(t *testing.T) {
+	// Discovery is opt-in
+	registry := NewTypeRegistry()
+
+	if err := registry.Register(&corev1.Container{}); err != nil {
+		t.Fatalf("Register failed: %v", err)
+	}
+	if err := registry.Process(); err != nil {
+		t.Fatalf("Process failed: %v", err)
+	}
+
+	stubs, err := registry.GenerateStubs()
+	if err != nil {
+		t.Fatalf("GenerateStubs failed: %v", err)
+	}
+
+	if strings.Contains(stubs, "---@alias corev1.PullPolicy") {
+		t.Errorf("Expected no enum aliases with discovery disabled, got:\n%s", stubs)
+	}
+
+	if !strings.Contains(stubs, "---@field imagePullPolicy string\n") {
+		t.Errorf("Expected imagePullPolicy to fall back to string, got:\n%s", stubs)
+	}
+}
+
+func TestCollectConstants(t *testing.T) {
+	src := `package test
+
+type Color string
+type Level int
+type Flag uint
+
+const (
+	Red   Color = "red"
+	Green Color = "green"
+	Other       = "untyped"
+)
+
+const Blue = Color("blue")
+
+const (
+	Debug Level = iota
+	Info
+	_
+	Error
+)
+
+const (
+	FlagA Flag = 1 << iota
+	FlagB
+	FlagC
+)
+
+const Ref Level = Debug + 10
+`
+
+	file, err := parser.ParseFile(token.NewFileSet(), "test.go", src, 0)
+	if err != nil {
+		t.Fatalf("Failed to parse source: %v", err)
+	}
+
+	constants := make(map[string][]constant.Value)
+	for _, decl := range file.Decls {
+		if gen, ok := decl.(*ast.GenDecl); ok && gen.Tok == token.CONST {
+			collectConstants(gen, constants)
+		}
+	}
+
+	tests := []struct {
+		typeName string
+		kind     reflect.Kind
+		expected []interface{}
+	}{
+		{"Color", reflect.String, []interface{}{"red", "green", "blue"}},
+		{"Level", reflect.Int, []interface{}{int64(0), int64(1), int64(3)}},
+		{"Flag", reflect.Uint, []interface{}{uint64(1), uint64(2), uint64(4)}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.typeName, func(t *testing.T) {
+			var values []interface{}
+			for _, value := range constants[tt.typeName] {
+				values = append(values, constantValue(value, tt.kind))
+			}
+
+			if !reflect.DeepEqual(values, tt.expected) {
+				t.Errorf("Expected %v, got %v", tt.expected, values)
+			}
+		})
+	}
+}
+
+func TestTypeRegistry_EnumAliasesReadOnlyAfterProcess(t *testing.T) {
+	type Settings struct {
+		Mode    Mode `json:"mode"`
+		Profile struct {
+			Mode Mode `json:"mode"`
+		} `json:"profile"`
+	}
+
+	registry := NewTypeRegistry()
+	if err := registry.RegisterEnum(ModeFast, ModeFast); err != nil {
+		t.Fatalf("RegisterEnum failed: %v", err)
+	}
+	if err := registry.Register(&Settings{}); err != nil {
+		t.Fatalf("Register failed: %v", err)
+	}
+	if err := registry.Process(); err != nil {
+		t.Fatalf("Process failed: %v", err)
+	}
+
+	// Generators and Validate only read the registry, so they can run concurrently
+	var wg sync.WaitGroup
+	errs := make(chan error, 32)
+	for i := 0; i < 8; i++ {
+		wg.Add(4)
+		go func() {
+			defer wg.Done()
+			_, err := registry.GenerateJSONSchema(Settings{})
+			errs <- err
+		}()
+		go func() {
+			defer wg.Done()
+			_, err := registry.GenerateTealDeclarations()
+			errs <- err
+		}()
+		go func() {
+			defer wg.Done()
+			_, err := registry.GenerateStubs()
+			errs <- err
+		}()
+		go func() {
+			defer wg.Done()
+			L := lua.NewState()
+			defer L.Close()
+			if err := L.DoString(`settings = { mode = "fast", profile = { mode = "fast" } }`); err != nil {
+				errs <- err
+				return
+			}
+			errs <- registry.Validate(L, L.GetGlobal("settings"), &Settings{})
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if err != nil {
+			t.Errorf("Unexpected error: %v", err)
+		}
+	}
+
+	// Values registered after Process() still update the alias
+	if err := registry.RegisterEnum(ModeFast, ModeSafe); err != nil {
+		t.Fatalf("RegisterEnum failed: %v", err)
+	}
+	stubs, err := registry.GenerateStubs()
+	if err != nil {
+		t.Fatalf("GenerateStubs failed: %v", err)
+	}
+	if expected := "---@alias glua.Mode \"fast\"|\"safe\"\n"; !strings.Contains(stubs, expected) {
+		t.Errorf("Expected stubs to contain %q, got:\n%s", expected, stubs)
+	}
+}
@@ -0,0 +1,259 @@
+// Copyright (c) 2024-2025 Thomas Maurice
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package glua
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+// JSONSchemaDialect: the JSON Schema draft used by GenerateJSONSchema
+const JSONSchemaDialect = "https://json-schema.org/draft/2020-12/schema"
+
+// jsonMarshalerType: reflected json.Marshaler interface, used to detect types with custom encodings
+var jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+
+// openAPISchemaTyper: implemented by types with a custom JSON encoding that describe
+// their schema type, such as metav1.Time, resource.Quantity or intstr.IntOrString
+type openAPISchemaTyper interface {
+	OpenAPISchemaType() []string
+}
+
+// openAPISchemaFormatter: optionally implemented alongside openAPISchemaTyper
+type openAPISchemaFormatter interface {
+	OpenAPISchemaFormat() string
+}
+
+// GenerateJSONSchema: generates a JSON Schema (draft 2020-12) document for a registered type.
+// The type must have been registered with Register() and processed with Process(); the
+// registry is only read, so schemas can be generated concurrently once it is processed.
+// Every named struct reachable from the type is emitted once under $defs and referenced
+// with $ref, fields without omitempty are listed in required, pointers, slices and maps
+// also accept null, and values declared with RegisterEnum are emitted as enum.
+//
+// Example output:
+//
+//	{
+//	  "$schema": "https://json-schema.org/draft/2020-12/schema",
+//	  "$ref": "#/$defs/corev1.Pod",
+//	  "$defs": {
+//	    "corev1.Pod": {"type": "object", "properties": {...}}
+//	  }
+//	}
+func (r *TypeRegistry) GenerateJSONSchema(obj interface{}) ([]byte, error) {
+	if obj == nil {
+		return nil, fmt.Errorf("cannot generate schema for nil object")
+	}
+
+	t := r.unwrapPointer(reflect.TypeOf(obj))
+	typeInfo, exists := r.types[r.getTypeKey(t)]
+	if t.Kind() != reflect.Struct || !exists {
+		return nil, fmt.Errorf("type %s is not registered, call Register() and Process() first", t)
+	}
+
+	defs := make(map[string]interface{})
+	root := r.typeSchema(typeInfo.GoType, defs)
+
+	doc := map[string]interface{}{
+		"$schema": JSONSchemaDialect,
+		"title":   typeInfo.Name,
+		"$defs":   defs,
+	}
+	for key, value := range root {
+		doc[key] = value
+	}
+
+	return json.MarshalIndent(doc, "", "  ")
+}
+
+// typeSchema: returns the JSON Schema for a Go type, adding named structs to defs
+func (r *TypeRegistry) typeSchema(t reflect.Type, defs map[string]interface{}) map[string]interface{} {
+	t = r.unwrapPointer(t)
+
+	if schema := r.customSchema(t); schema != nil {
+		return schema
+	}
+
+	var schema map[string]interface{}
+
+	switch t.Kind() {
+	case reflect.String:
+		schema = map[string]interface{}{"type": "string"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		schema = map[string]interface{}{"type": "integer"}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		schema = map[string]interface{}{"type": "integer", "minimum": 0}
+	case reflect.Float32, reflect.Float64:
+		schema = map[string]interface{}{"type": "number"}
+	case reflect.Bool:
+		schema = map[string]interface{}{"type": "boolean"}
+	case reflect.Slice, reflect.Array:
+		// encoding/json encodes byte slices as base64 strings
+		if t.Elem().Kind() == reflect.Uint8 {
+			return map[string]interface{}{"type": "string", "contentEncoding": "base64"}
+		}
+		return map[string]interface{}{"type": "array", "items": r.valueSchema(t.Elem(), defs)}
+	case reflect.Map:
+		return map[string]interface{}{"type": "object", "additionalProperties": r.valueSchema(t.Elem(), defs)}
+	case reflect.Struct:
+		return r.structSchema(t, defs)
+	default:
+		return map[string]interface{}{}
+	}
+
+	if values, ok := r.enums[r.getTypeKey(t)]; ok && t.Name() != "" {
+		schema["enum"] = values
+	}
+
+	return schema
+}
+
+// valueSchema: returns the schema of a field, array item or map value. encoding/json
+// writes nil pointers, slices and maps as null unless omitempty drops the field.
+func (r *TypeRegistry) valueSchema(t reflect.Type, defs map[string]interface{}) map[string]interface{} {
+	schema := r.typeSchema(t, defs)
+	switch t.Kind() {
+	case reflect.Ptr, reflect.Slice, reflect.Map:
+		return nullableSchema(schema)
+	}
+	return schema
+}
+
+// nullableSchema: returns a schema that also accepts null
+func nullableSchema(schema map[string]interface{}) map[string]interface{} {
+	if len(schema) == 0 {
+		return schema
+	}
+
+	if _, ok := schema["$ref"]; ok {
+		return map[string]interface{}{"anyOf": []interface{}{schema, map[string]interface{}{"type": "null"}}}
+	}
+
+	nullable := make(map[string]interface{}, len(schema))
+	for key, value := range schema {
+		nullable[key] = value
+	}
+
+	switch types := schema["type"].(type) {
+	case string:
+		nullable["type"] = []string{types, "null"}
+	case []string:
+		nullable["type"] = append(append([]string{}, types...), "null")
+	}
+	if values, ok := schema["enum"].([]interface{}); ok {
+		nullable["enum"] = append(append([]interface{}{}, values...), nil)
+	}
+
+	return nullable
+}
+
+// customSchema: returns the schema of types that control their own JSON encoding.
+// Types describing themselves through OpenAPISchemaType() use that description,
+// other json.Marshaler implementations accept any value.
+// Returns nil for types using the default encoding.
+func (r *TypeRegistry) customSchema(t reflect.Type) map[string]interface{} {
+	if t.Kind() == reflect.Interface {
+		return nil
+	}
+
+	value := reflect.New(t).Interface()
+
+	if typer, ok := value.(openAPISchemaTyper); ok && len(typer.OpenAPISchemaType()) > 0 {
+		types := typer.OpenAPISchemaType()
+		format := ""
+		if formatter, ok := value.(openAPISchemaFormatter); ok {
+			format = formatter.OpenAPISchemaFormat()
+		}
+
+		// IntOrString reports "string" with a dedicated format
+		if format == "int-or-string" {
+			return map[string]interface{}{"type": []string{"integer", "string"}}
+		}
+
+		schema := map[string]interface{}{"type": types[0]}
+		if len(types) > 1 {
+			schema["type"] = types
+		}
+		if format != "" {
+			schema["format"] = format
+		}
+		return schema
+	}
+
+	if reflect.PointerTo(t).Implements(jsonMarshalerType) {
+		return map[string]interface{}{}
+	}
+
+	return nil
+}
+
+// structSchema: returns a $ref to the definition of a named struct, or an inline
+// object schema for anonymous structs. Only reads the registry, so schemas can be
+// generated while other goroutines use it.
+func (r *TypeRegistry) structSchema(t reflect.Type, defs map[string]interface{}) map[string]interface{} {
+	typeInfo := &TypeInfo{Name: r.getTypeName(t), GoType: t, Fields: r.lookupStructFields(t)}
+	if t.Name() == "" {
+		return r.objectSchema(typeInfo, defs)
+	}
+	if registered, exists := r.types[r.getTypeKey(t)]; exists {
+		typeInfo = registered
+	}
+
+	ref := map[string]interface{}{"$ref": "#/$defs/" + typeInfo.Name}
+	if _, exists := defs[typeInfo.Name]; exists {
+		return ref
+	}
+
+	// Reserve the definition before recursing to handle circular references
+	defs[typeInfo.Name] = map[string]interface{}{}
+	defs[typeInfo.Name] = r.objectSchema(typeInfo, defs)
+
+	return ref
+}
+
+// objectSchema: builds an object schema from the fields of a struct type
+func (r *TypeRegistry) objectSchema(typeInfo *TypeInfo, defs map[string]interface{}) map[string]interface{} {
+	schema := map[string]interface{}{"type": "object"}
+	if len(typeInfo.Fields) == 0 {
+		return schema
+	}
+
+	properties := make(map[string]interface{})
+	required := make([]string, 0)
+
+	for name, field := range typeInfo.Fields {
+		properties[name] = r.valueSchema(field.GoType, defs)
+		if !field.Optional {
+			required = append(required, name)
+		}
+	}
+	sort.Strings(required)
+
+	schema["properties"] = properties
+	schema["additionalProperties"] = false
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+
+	return schema
+}
@@ -0,0 +1,318 @@
+// Copyright (c) 2024-2025 Thomas Maurice
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package glua
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// Mode: string enum used by the schema tests
+type Mode string
+
+const (
+	ModeFast Mode = "fast"
+	ModeSafe Mode = "safe"
+)
+
+// generateSchema: registers obj, generates its schema and decodes it for inspection
+func generateSchema(t *testing.T, registry *TypeRegistry, obj interface{}) map[string]interface{} {
+	t.Helper()
+
+	if err := registry.Register(obj); err != nil {
+		t.Fatalf("Register failed: %v", err)
+	}
+
+	if err := registry.Process(); err != nil {
+		t.Fatalf("Process failed: %v", err)
+	}
+
+	data, err := registry.GenerateJSONSchema(obj)
+	if err != nil {
+		t.Fatalf("GenerateJSONSchema failed: %v", err)
+	}
+
+	var doc map[string]interface{}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		t.Fatalf("Generated schema is not valid JSON: %v\n%s", err, data)
+	}
+
+	return doc
+}
+
+// definition: returns a definition from the $defs section of a schema
+func definition(t *testing.T, doc map[string]interface{}, name string) map[string]interface{} {
+	t.Helper()
+
+	defs, ok := doc["$defs"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("Schema has no $defs: %v", doc)
+	}
+
+	def, ok := defs[name].(map[string]interface{})
+	if !ok {
+		t.Fatalf("Schema has no definition for %s: %v", name, defs)
+	}
+
+	return def
+}
+
+func TestTypeRegistry_JSONSchemaStructure(t *testing.T) {
+	type Address struct {
+		City string `json:"city"`
+	}
+
+	type Person struct {
+		Name     string            `json:"name"`
+		Age      int               `json:"age,omitempty"`
+		Score    float64           `json:"score"`
+		Admin    bool              `json:"admin"`
+		Home     Address           `json:"home"`
+		Work     *Address          `json:"work"`
+		Nickname *string           `json:"nickname"`
+		Tags     []string          `json:"tags,omitempty"`
+		Labels   map[string]string `json:"labels,omitempty"`
+		Avatar   []byte            `json:"avatar,omitempty"`
+		Extra    interface{}       `json:"extra,omitempty"`
+		Internal string            `json:"-"`
+	}
+
+	doc := generateSchema(t, NewTypeRegistry(), Person{})
+
+	if doc["$schema"] != JSONSchemaDialect {
+		t.Errorf("Expected $schema %q, got %v", JSONSchemaDialect, doc["$schema"])
+	}
+
+	if doc["$ref"] != "#/$defs/glua.Person" {
+		t.Errorf("Expected root $ref to glua.Person, got %v", doc["$ref"])
+	}
+
+	person := definition(t, doc, "glua.Person")
+	props := person["properties"].(map[string]interface{})
+
+	expected := map[string]interface{}{
+		"name":  map[string]interface{}{"type": "string"},
+		"age":   map[string]interface{}{"type": "integer"},
+		"score": map[string]interface{}{"type": "number"},
+		"admin": map[string]interface{}{"type": "boolean"},
+		"home":  map[string]interface{}{"$ref": "#/$defs/glua.Address"},
+		"work": map[string]interface{}{"anyOf": []interface{}{
+			map[string]interface{}{"$ref": "#/$defs/glua.Address"},
+			map[string]interface{}{"type": "null"},
+		}},
+		"nickname": map[string]interface{}{"type": []interface{}{"string", "null"}},
+		"tags":     map[string]interface{}{"type": []interface{}{"array", "null"}, "items": map[string]interface{}{"type": "string"}},
+		"labels":   map[string]interface{}{"type": []interface{}{"object", "null"}, "additionalProperties": map[string]interface{}{"type": "string"}},
+		"avatar":   map[string]interface{}{"type": []interface{}{"string", "null"}, "contentEncoding": "base64"},
+		"extra":    map[string]interface{}{},
+	}
+
+	if len(props) != len(expected) {
+		t.Errorf("Expected %d properties, got %d: %v", len(expected), len(props), props)
+	}
+
+	for name, want := range expected {
+		if !reflect.DeepEqual(props[name], want) {
+			t.Errorf("Property %s: expected %v, got %v", name, want, props[name])
+		}
+	}
+
+	// Fields without omitempty that are not pointers are required
+	wantRequired := []interface{}{"admin", "home", "name", "score"}
+	if !reflect.DeepEqual(person["required"], wantRequired) {
+		t.Errorf("Expected required %v, got %v", wantRequired, person["required"])
+	}
+
+	if person["additionalProperties"] != false {
+		t.Errorf("Expected additionalProperties false, got %v", person["additionalProperties"])
+	}
+
+	// Shared types are defined once
+	address := definition(t, doc, "glua.Address")
+	if address["type"] != "object" {
+		t.Errorf("Expected glua.Address to be an object, got %v", address)
+	}
+}
+
+func TestTypeRegistry_JSONSchemaEnum(t *testing.T) {
+	type Job struct {
+		Mode     Mode  `json:"mode"`
+		Fallback *Mode `json:"fallback"`
+	}
+
+	registry := NewTypeRegistry()
+	if err := registry.RegisterEnum(ModeFast, ModeFast, ModeSafe); err != nil {
+		t.Fatalf("RegisterEnum failed: %v", err)
+	}
+
+	doc := generateSchema(t, registry, Job{})
+	props := definition(t, doc, "glua.Job")["properties"].(map[string]interface{})
+
+	want := map[string]interface{}{"type": "string", "enum": []interface{}{"fast", "safe"}}
+	if !reflect.DeepEqual(props["mode"], want) {
+		t.Errorf("Expected %v, got %v", want, props["mode"])
+	}
+
+	// A nil pointer is encoded as null
+	want = map[string]interface{}{"type": []interface{}{"string", "null"}, "enum": []interface{}{"fast", "safe", nil}}
+	if !reflect.DeepEqual(props["fallback"], want) {
+		t.Errorf("Expected %v, got %v", want, props["fallback"])
+	}
+}
+
+func TestTypeRegistry_RegisterEnumErrors(t *testing.T) {
+	registry := NewTypeRegistry()
+
+	tests := []struct {
+		name   string
+		obj    interface{}
+		values []interface{}
+	}{
+		{"nil object", nil, nil},
+		{"unnamed type", "fast", []interface{}{"fast"}},
+		{"struct type", corev1.Pod{}, nil},
+		{"mismatched value kind", ModeFast, []interface{}{42}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := registry.RegisterEnum(tt.obj, tt.values...); err == nil {
+				t.Error("Expected error, got nil")
+			}
+		})
+	}
+}
+
+func TestTypeRegistry_JSONSchemaCircular(t *testing.T) {
+	type Node struct {
+		Value    string  `json:"value"`
+		Children []*Node `json:"children,omitempty"`
+	}
+
+	doc := generateSchema(t, NewTypeRegistry(), Node{})
+	node := definition(t, doc, "glua.Node")
+	children := node["properties"].(map[string]interface{})["children"]
+
+	want := map[string]interface{}{"type": []interface{}{"array", "null"}, "items": map[string]interface{}{"anyOf": []interface{}{
+		map[string]interface{}{"$ref": "#/$defs/glua.Node"},
+		map[string]interface{}{"type": "null"},
+	}}}
+	if !reflect.DeepEqual(children, want) {
+		t.Errorf("Expected %v, got %v", want, children)
+	}
+}
+
+func TestTypeRegistry_JSONSchemaKubernetes(t *testing.T) {
+	doc := generateSchema(t, NewTypeRegistry(), &corev1.Pod{})
+
+	// TypeMeta is inlined into the Pod, like its JSON encoding
+	pod := definition(t, doc, "corev1.Pod")["properties"].(map[string]interface{})
+	for _, name := range []string{"kind", "apiVersion", "metadata", "spec", "status"} {
+		if _, ok := pod[name]; !ok {
+			t.Errorf("Expected corev1.Pod to have property %s, got %v", name, pod)
+		}
+	}
+	if _, ok := pod["TypeMeta"]; ok {
+		t.Errorf("Did not expect corev1.Pod to have a TypeMeta property")
+	}
+
+	// Types with custom JSON encodings describe themselves
+	meta := definition(t, doc, "v1.ObjectMeta")["properties"].(map[string]interface{})
+	wantTime := map[string]interface{}{"type": "string", "format": "date-time"}
+	if !reflect.DeepEqual(meta["creationTimestamp"], wantTime) {
+		t.Errorf("Expected creationTimestamp %v, got %v", wantTime, meta["creationTimestamp"])
+	}
+
+	container := definition(t, doc, "corev1.Container")
+	if !reflect.DeepEqual(container["required"], []interface{}{"name"}) {
+		t.Errorf("Expected corev1.Container to require name, got %v", container["required"])
+	}
+
+	resources := definition(t, doc, "corev1.ResourceRequirements")["properties"].(map[string]interface{})
+	wantLimits := map[string]interface{}{"type": []interface{}{"object", "null"}, "additionalProperties": map[string]interface{}{"type": "string"}}
+	if !reflect.DeepEqual(resources["limits"], wantLimits) {
+		t.Errorf("Expected limits %v, got %v", wantLimits, resources["limits"])
+	}
+
+	port := definition(t, doc, "corev1.HTTPGetAction")["properties"].(map[string]interface{})["port"]
+	wantPort := map[string]interface{}{"type": []interface{}{"integer", "string"}}
+	if !reflect.DeepEqual(port, wantPort) {
+		t.Errorf("Expected port %v, got %v", wantPort, port)
+	}
+}
+
+func TestTypeRegistry_JSONSchemaUnregistered(t *testing.T) {
+	type Unregistered struct {
+		Name string `json:"name"`
+	}
+
+	registry := NewTypeRegistry()
+	if _, err := registry.GenerateJSONSchema(Unregistered{}); err == nil {
+		t.Error("Expected error for unregistered type, got nil")
+	}
+
+	if _, err := registry.GenerateJSONSchema(nil); err == nil {
+		t.Error("Expected error for nil object, got nil")
+	}
+}
+
+func TestTypeRegistry_JSONSchemaNilCollections(t *testing.T) {
+	type Group struct {
+		Members []string          `json:"members"`
+		Roles   map[string]string `json:"roles"`
+		Scope   struct {
+			Names []string `json:"names"`
+		} `json:"scope"`
+	}
+
+	registry := NewTypeRegistry()
+	doc := generateSchema(t, registry, Group{})
+	group := definition(t, doc, "glua.Group")
+
+	// encoding/json writes nil slices and maps as null, even for required fields
+	if !reflect.DeepEqual(group["required"], []interface{}{"members", "roles", "scope"}) {
+		t.Errorf("Expected all fields to be required, got %v", group["required"])
+	}
+
+	props := group["properties"].(map[string]interface{})
+	for _, name := range []string{"members", "roles"} {
+		if types := props[name].(map[string]interface{})["type"].([]interface{}); types[1] != "null" {
+			t.Errorf("Expected %s to accept null, got %v", name, types)
+		}
+	}
+
+	names := props["scope"].(map[string]interface{})["properties"].(map[string]interface{})["names"]
+	if types := names.(map[string]interface{})["type"].([]interface{}); types[1] != "null" {
+		t.Errorf("Expected scope.names to accept null, got %v", types)
+	}
+
+	// Generating a schema only reads the registry
+	types := len(registry.types)
+	if _, err := registry.GenerateJSONSchema(Group{}); err != nil {
+		t.Fatalf("GenerateJSONSchema failed: %v", err)
+	}
+	if len(registry.types) != types {
+		t.Errorf("Expected %d registered types after generating the schema, got %d", types, len(registry.types))
+	}
+}
@@ -0,0 +1,432 @@
+// Copyright (c) 2024-2025 Thomas Maurice
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package glua
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+
+	lua "github.com/yuin/gopher-lua"
+	"github.com/yuin/gopher-lua/parse"
+)
+
+// ErrPoolClosed: returned by Get once the pool is closed
+var ErrPoolClosed = errors.New("state pool is closed")
+
+// PoolOptions: how the states of a StatePool are created and prepared
+type PoolOptions struct {
+	// State: options of lua.NewState (SkipOpenLibs is implied by Sandbox)
+	State lua.Options
+
+	// Sandbox: only opens the package, base, table, string, math and coroutine libraries,
+	// removes dofile and loadfile, and lets require load preloaded modules only
+	Sandbox bool
+
+	// Modules: loaders preloaded under their require name, and required once per state
+	Modules map[string]lua.LGFunction
+
+	// Globals: Go values converted with a Translator and set as globals
+	Globals map[string]interface{}
+
+	// Setup: called on every new state after the modules and globals are set
+	Setup func(L *lua.LState) error
+
+	// Init: compiled scripts run on every new state after Setup, in order.
+	// The globals they define are kept between borrows.
+	Init []*lua.FunctionProto
+
+	// MaxIdle: maximum number of idle states kept, states returned beyond it are closed
+	// (default: GOMAXPROCS)
+	MaxIdle int
+}
+
+// StatePool: a pool of Lua states prepared once from PoolOptions, safe for concurrent use.
+// States are borrowed with Get and returned with Put, which restores the globals so nothing
+// set by a request leaks into the next one.
+//
+// Example:
+//
+//	pool, err := glua.NewStatePool(glua.PoolOptions{
+//		Modules: map[string]lua.LGFunction{"kubernetes": kubernetes.Loader},
+//	})
+//	script, err := glua.CompileFile("mutate.lua")
+//
+//	err = pool.Do(func(L *lua.LState) error {
+//		L.SetGlobal("pod", podTable)
+//		return glua.DoProto(L, script)
+//	})
+type StatePool struct {
+	options    PoolOptions
+	translator *Translator
+	idle       chan *lua.LState
+
+	mu        sync.Mutex
+	baselines map[*lua.LState]*stateBaseline // Every state created and not closed yet
+	closed    bool
+}
+
+// NewStatePool: creates a pool and prepares its first state, so that configuration errors
+// (failing Setup or Init scripts, unconvertible globals) are returned here
+func NewStatePool(options PoolOptions) (*StatePool, error) {
+	if options.MaxIdle <= 0 {
+		options.MaxIdle = runtime.GOMAXPROCS(0)
+	}
+
+	p := &StatePool{
+		options:    options,
+		translator: NewTranslator(),
+		idle:       make(chan *lua.LState, options.MaxIdle),
+		baselines:  make(map[*lua.LState]*stateBaseline),
+	}
+
+	L, err := p.newState()
+	if err != nil {
+		return nil, err
+	}
+	p.idle <- L
+
+	return p, nil
+}
+
+// Get: borrows a prepared state, creating one when none is idle.
+// The state must be returned with Put and not used afterwards.
+func (p *StatePool) Get() (*lua.LState, error) {
+	select {
+	case L := <-p.idle:
+		return L, nil
+	default:
+	}
+
+	p.mu.Lock()
+	closed := p.closed
+	p.mu.Unlock()
+	if closed {
+		return nil, ErrPoolClosed
+	}
+
+	return p.newState()
+}
+
+// Put: returns a borrowed state to the pool. The stack is emptied, the context removed,
+// and every table reachable from the globals, package.loaded and the string metatable
+// (modules, nested tables of PoolOptions.Globals, metatables, ...) has its fields and
+// metatable restored to their state after preparation. Go values captured by modules are
+// not reset. The state is closed when the pool is closed or full.
+func (p *StatePool) Put(L *lua.LState) {
+	p.mu.Lock()
+	baseline, ok := p.baselines[L]
+	closed := p.closed
+	p.mu.Unlock()
+
+	if !ok || closed || L.IsClosed() {
+		p.discard(L)
+		return
+	}
+
+	L.SetTop(0)
+	L.RemoveContext()
+	baseline.restore(L)
+
+	// Checked again under the lock so that Close cannot miss a state being returned
+	p.mu.Lock()
+	kept := false
+	if !p.closed {
+		select {
+		case p.idle <- L:
+			kept = true
+		default:
+		}
+	}
+	p.mu.Unlock()
+
+	if !kept {
+		p.discard(L)
+	}
+}
+
+// Do: borrows a state, calls fn with it and returns it to the pool
+func (p *StatePool) Do(fn func(L *lua.LState) error) error {
+	L, err := p.Get()
+	if err != nil {
+		return err
+	}
+	defer p.Put(L)
+
+	return fn(L)
+}
+
+// Close: closes the idle states. Borrowed states are closed when they are returned.
+func (p *StatePool) Close() {
+	p.mu.Lock()
+	p.closed = true
+	p.mu.Unlock()
+
+	for {
+		select {
+		case L := <-p.idle:
+			p.discard(L)
+		default:
+			return
+		}
+	}
+}
+
+// discard: closes a state and forgets its baseline
+func (p *StatePool) discard(L *lua.LState) {
+	p.mu.Lock()
+	delete(p.baselines, L)
+	p.mu.Unlock()
+
+	if !L.IsClosed() {
+		L.Close()
+	}
+}
+
+// newState: creates a state, prepares it and records its baseline
+func (p *StatePool) newState() (*lua.LState, error) {
+	opts := p.options.State
+	if p.options.Sandbox {
+		opts.SkipOpenLibs = true
+	}
+
+	L := lua.NewState(opts)
+	if err := p.prepare(L); err != nil {
+		L.Close()
+		return nil, err
+	}
+
+	baseline := newStateBaseline(L)
+
+	p.mu.Lock()
+	p.baselines[L] = baseline
+	p.mu.Unlock()
+
+	return L, nil
+}
+
+// prepare: opens the libraries, preloads and requires the modules, sets the globals and runs
+// Setup and Init
+func (p *StatePool) prepare(L *lua.LState) error {
+	if p.options.Sandbox {
+		openSandboxLibs(L)
+	}
+
+	modules := make([]string, 0, len(p.options.Modules))
+	for name, loader := range p.options.Modules {
+		L.PreloadModule(name, loader)
+		modules = append(modules, name)
+	}
+	sort.Strings(modules)
+
+	// Required once here so that the module tables are part of the baseline
+	for _, name := range modules {
+		err := L.CallByParam(lua.P{Fn: L.GetGlobal("require"), NRet: 0, Protect: true}, lua.LString(name))
+		if err != nil {
+			return fmt.Errorf("failed to load module %s: %w", name, err)
+		}
+	}
+
+	names := make([]string, 0, len(p.options.Globals))
+	for name := range p.options.Globals {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		value, err := p.translator.ToLua(L, p.options.Globals[name])
+		if err != nil {
+			return fmt.Errorf("failed to convert global %s: %w", name, err)
+		}
+		L.SetGlobal(name, value)
+	}
+
+	if p.options.Setup != nil {
+		if err := p.options.Setup(L); err != nil {
+			return fmt.Errorf("failed to set up state: %w", err)
+		}
+	}
+
+	for _, proto := range p.options.Init {
+		if err := DoProto(L, proto); err != nil {
+			return fmt.Errorf("failed to run init script: %w", err)
+		}
+	}
+	L.SetTop(0)
+
+	return nil
+}
+
+// openSandboxLibs: opens the libraries that do not reach the host, and restricts require
+// to preloaded modules
+func openSandboxLibs(L *lua.LState) {
+	for _, lib := range []struct {
+		name string
+		open lua.LGFunction
+	}{
+		{lua.LoadLibName, lua.OpenPackage},
+		{lua.BaseLibName, lua.OpenBase},
+		{lua.TabLibName, lua.OpenTable},
+		{lua.StringLibName, lua.OpenString},
+		{lua.MathLibName, lua.OpenMath},
+		{lua.CoroutineLibName, lua.OpenCoroutine},
+	} {
+		L.Push(L.NewFunction(lib.open))
+		L.Push(lua.LString(lib.name))
+		L.Call(1, 0)
+	}
+
+	L.SetGlobal("dofile", lua.LNil)
+	L.SetGlobal("loadfile", lua.LNil)
+
+	// The first loader reads package.preload, the others search the filesystem
+	if pkg, ok := L.GetGlobal(lua.LoadLibName).(*lua.LTable); ok {
+		if loaders, ok := pkg.RawGetString("loaders").(*lua.LTable); ok {
+			for i := loaders.Len(); i > 1; i-- {
+				loaders.RawSetInt(i, lua.LNil)
+			}
+		}
+		pkg.RawSetString("path", lua.LString(""))
+	}
+}
+
+// stateBaseline: the tables of a prepared state, restored when it is returned
+type stateBaseline struct {
+	stringMetatable lua.LValue
+	tables          map[*lua.LTable]map[lua.LValue]lua.LValue // Every table reachable from _G, package.loaded and the string metatable
+	metatables      map[*lua.LTable]lua.LValue
+}
+
+// newStateBaseline: records the fields and metatables of the tables reachable from _G,
+// package.loaded and the string metatable
+func newStateBaseline(L *lua.LState) *stateBaseline {
+	b := &stateBaseline{
+		stringMetatable: L.GetMetatable(lua.LString("")),
+		tables:          make(map[*lua.LTable]map[lua.LValue]lua.LValue),
+		metatables:      make(map[*lua.LTable]lua.LValue),
+	}
+
+	b.record(L, L.G.Global)
+	if loaded, ok := L.GetField(L.Get(lua.RegistryIndex), "_LOADED").(*lua.LTable); ok {
+		b.record(L, loaded)
+	}
+	if mt, ok := b.stringMetatable.(*lua.LTable); ok {
+		b.record(L, mt)
+	}
+
+	return b
+}
+
+// record: copies the fields and the metatable of a table, then records the tables it holds
+func (b *stateBaseline) record(L *lua.LState, tbl *lua.LTable) {
+	if _, ok := b.tables[tbl]; ok {
+		return
+	}
+
+	fields := make(map[lua.LValue]lua.LValue)
+	var nested []*lua.LTable
+	tbl.ForEach(func(key, value lua.LValue) {
+		fields[key] = value
+		for _, v := range []lua.LValue{key, value} {
+			if t, ok := v.(*lua.LTable); ok {
+				nested = append(nested, t)
+			}
+		}
+	})
+	b.tables[tbl] = fields
+
+	metatable := L.GetMetatable(tbl)
+	b.metatables[tbl] = metatable
+	if mt, ok := metatable.(*lua.LTable); ok {
+		nested = append(nested, mt)
+	}
+
+	for _, t := range nested {
+		b.record(L, t)
+	}
+}
+
+// restore: resets the recorded tables to their recorded fields and metatables
+func (b *stateBaseline) restore(L *lua.LState) {
+	L.SetMetatable(lua.LString(""), b.stringMetatable)
+
+	for tbl, fields := range b.tables {
+		if metatable := b.metatables[tbl]; L.GetMetatable(tbl) != metatable {
+			L.SetMetatable(tbl, metatable)
+		}
+
+		// Fields are collected first, a table cannot be modified while iterating
+		var stale []lua.LValue
+		tbl.ForEach(func(key, value lua.LValue) {
+			if recorded, ok := fields[key]; !ok || recorded != value {
+				stale = append(stale, key)
+			}
+		})
+
+		for _, key := range stale {
+			value, ok := fields[key]
+			if !ok {
+				value = lua.LNil
+			}
+			tbl.RawSet(key, value)
+		}
+		for key, value := range fields {
+			if tbl.RawGet(key) == lua.LNil {
+				tbl.RawSet(key, value)
+			}
+		}
+	}
+}
+
+// CompileString: parses and compiles a Lua chunk once, to be run on any state with DoProto
+func CompileString(source, name string) (*lua.FunctionProto, error) {
+	chunk, err := parse.Parse(strings.NewReader(source), name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", name, err)
+	}
+
+	proto, err := lua.Compile(chunk, name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compile %s: %w", name, err)
+	}
+
+	return proto, nil
+}
+
+// CompileFile: reads, parses and compiles a Lua file once, to be run on any state with DoProto
+func CompileFile(path string) (*lua.FunctionProto, error) {
+	source, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return CompileString(string(source), path)
+}
+
+// DoProto: runs a compiled chunk on a state, like DoString or DoFile without recompiling it
+func DoProto(L *lua.LState, proto *lua.FunctionProto) error {
+	L.Push(L.NewFunctionFromProto(proto))
+	return L.PCall(0, lua.MultRet, nil)
+}
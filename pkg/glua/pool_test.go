@@ -0,0 +1,354 @@
+// Copyright (c) 2024-2025 Thomas Maurice
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package glua
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	lua "github.com/yuin/gopher-lua"
+)
+
+// greetLoader: a module returning a table with a greet function
+func greetLoader(L *lua.LState) int {
+	mod := L.NewTable()
+	mod.RawSetString("greet", L.NewFunction(func(L *lua.LState) int {
+		L.Push(lua.LString("hello " + L.CheckString(1)))
+		return 1
+	}))
+	L.Push(mod)
+	return 1
+}
+
+func TestStatePool_Prepare(t *testing.T) {
+	helpers, err := CompileString(`function double(x) return x * 2 end`, "helpers.lua")
+	if err != nil {
+		t.Fatalf("CompileString failed: %v", err)
+	}
+
+	pool, err := NewStatePool(PoolOptions{
+		Modules: map[string]lua.LGFunction{"greet": greetLoader},
+		Globals: map[string]interface{}{
+			"config": map[string]interface{}{"replicas": 3, "name": "web"},
+		},
+		Setup: func(L *lua.LState) error {
+			L.SetGlobal("prefix", lua.LString("pooled"))
+			return nil
+		},
+		Init: []*lua.FunctionProto{helpers},
+	})
+	if err != nil {
+		t.Fatalf("NewStatePool failed: %v", err)
+	}
+	defer pool.Close()
+
+	script, err := CompileString(`
+		local greet = require("greet")
+		result = prefix .. " " .. greet.greet(config.name) .. " " .. double(config.replicas)
+	`, "script.lua")
+	if err != nil {
+		t.Fatalf("CompileString failed: %v", err)
+	}
+
+	err = pool.Do(func(L *lua.LState) error {
+		if err := DoProto(L, script); err != nil {
+			return err
+		}
+		if got := L.GetGlobal("result").String(); got != "pooled hello web 6" {
+			return fmt.Errorf("expected %q, got %q", "pooled hello web 6", got)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Do failed: %v", err)
+	}
+}
+
+func TestStatePool_Reset(t *testing.T) {
+	pool, err := NewStatePool(PoolOptions{
+		Modules: map[string]lua.LGFunction{"greet": greetLoader, "setup": greetLoader},
+		Globals: map[string]interface{}{
+			"limit":  10,
+			"config": map[string]interface{}{"limits": map[string]interface{}{"cpu": 2}},
+		},
+		Setup: func(L *lua.LState) error {
+			return L.DoString(`require("setup")`)
+		},
+		MaxIdle: 1,
+	})
+	if err != nil {
+		t.Fatalf("NewStatePool failed: %v", err)
+	}
+	defer pool.Close()
+
+	L, err := pool.Get()
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+
+	L.SetContext(context.Background())
+	err = L.DoString(`
+		leaked = "secret"
+		limit = 20
+		print = nil
+		string.shout = function(s) return s:upper() end
+		local greet = require("greet")
+		greet.greet = nil
+		require("setup").greet = nil
+		config.limits.cpu = 4
+		config.limits.memory = 1
+		setmetatable(string, { __index = function() return "default" end })
+		getmetatable("").__index = { upper = function() return "hijacked" end }
+		debug.setmetatable("", { __index = { upper = function() return "replaced" end } })
+		setmetatable(_G, { __index = function() return "default" end })
+		return 1, 2, 3
+	`)
+	if err != nil {
+		t.Fatalf("DoString failed: %v", err)
+	}
+	pool.Put(L)
+
+	// With a single idle state, the same state is borrowed again
+	again, err := pool.Get()
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	defer pool.Put(again)
+
+	if again != L {
+		t.Fatal("Expected the returned state to be reused")
+	}
+	if again.GetTop() != 0 {
+		t.Errorf("Expected an empty stack, got %d values", again.GetTop())
+	}
+	if again.Context() != nil {
+		t.Error("Expected the context to be removed")
+	}
+
+	tests := []struct {
+		expr     string
+		expected string
+	}{
+		{"leaked", "nil"},
+		{"limit", "10"},
+		{"type(print)", "function"},
+		{"string.shout", "nil"},
+		{"getmetatable(_G)", "nil"},
+		{"getmetatable(string)", "nil"},
+		// The string metatable is the string table itself
+		{"getmetatable('') == string", "true"},
+		{"getmetatable('').__index == string", "true"},
+		{"('pool'):upper()", "POOL"},
+		{"config.limits.cpu", "2"},
+		{"config.limits.memory", "nil"},
+		// Module tables, required when preparing the state or during Setup, are restored
+		{"require('setup').greet('pool')", "hello pool"},
+		{"require('greet').greet('pool')", "hello pool"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			if err := again.DoString("return tostring(" + tt.expr + ")"); err != nil {
+				t.Fatalf("DoString failed: %v", err)
+			}
+			if got := again.Get(-1).String(); got != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, got)
+			}
+			again.Pop(1)
+		})
+	}
+}
+
+func TestStatePool_ModulesLoadedOnce(t *testing.T) {
+	calls := 0
+	pool, err := NewStatePool(PoolOptions{
+		Modules: map[string]lua.LGFunction{"greet": func(L *lua.LState) int {
+			calls++
+			return greetLoader(L)
+		}},
+		MaxIdle: 1,
+	})
+	if err != nil {
+		t.Fatalf("NewStatePool failed: %v", err)
+	}
+	defer pool.Close()
+
+	for i := 0; i < 5; i++ {
+		err := pool.Do(func(L *lua.LState) error {
+			return L.DoString(`require("greet").greet("pool")`)
+		})
+		if err != nil {
+			t.Fatalf("Do failed: %v", err)
+		}
+	}
+
+	if calls != 1 {
+		t.Errorf("Expected the loader to be called once, got %d calls", calls)
+	}
+}
+
+func TestStatePool_Sandbox(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "local.lua"), []byte("return 1"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	pool, err := NewStatePool(PoolOptions{
+		Sandbox: true,
+		Modules: map[string]lua.LGFunction{"greet": greetLoader},
+	})
+	if err != nil {
+		t.Fatalf("NewStatePool failed: %v", err)
+	}
+	defer pool.Close()
+
+	tests := []struct {
+		name     string
+		script   string
+		expected string
+	}{
+		{"string library", `return string.upper("ok")`, "OK"},
+		{"math library", `return tostring(math.max(1, 2))`, "2"},
+		{"preloaded module", `return require("greet").greet("sandbox")`, "hello sandbox"},
+		{"no os", `return type(os)`, "nil"},
+		{"no io", `return type(io)`, "nil"},
+		{"no debug", `return type(debug)`, "nil"},
+		{"no dofile", `return type(dofile)`, "nil"},
+		{"no loadfile", `return type(loadfile)`, "nil"},
+		{"no file modules", `package.path = "` + dir + `/?.lua"; return tostring(pcall(require, "local"))`, "false"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := pool.Do(func(L *lua.LState) error {
+				if err := L.DoString(tt.script); err != nil {
+					return err
+				}
+				if got := L.Get(-1).String(); got != tt.expected {
+					return fmt.Errorf("expected %s, got %s", tt.expected, got)
+				}
+				return nil
+			})
+			if err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+func TestStatePool_Errors(t *testing.T) {
+	if _, err := NewStatePool(PoolOptions{
+		Setup: func(L *lua.LState) error { return errors.New("boom") },
+	}); err == nil || !strings.Contains(err.Error(), "boom") {
+		t.Errorf("Expected the Setup error, got %v", err)
+	}
+
+	failing, err := CompileString(`error("init failed")`, "init.lua")
+	if err != nil {
+		t.Fatalf("CompileString failed: %v", err)
+	}
+	if _, err := NewStatePool(PoolOptions{Init: []*lua.FunctionProto{failing}}); err == nil || !strings.Contains(err.Error(), "init failed") {
+		t.Errorf("Expected the Init error, got %v", err)
+	}
+
+	if _, err := NewStatePool(PoolOptions{Globals: map[string]interface{}{"ch": make(chan int)}}); err == nil {
+		t.Error("Expected an error for an unconvertible global")
+	}
+
+	if _, err := CompileString(`local x = `, "broken.lua"); err == nil || !strings.Contains(err.Error(), "broken.lua") {
+		t.Errorf("Expected a parse error naming the script, got %v", err)
+	}
+
+	if _, err := CompileFile(filepath.Join(t.TempDir(), "missing.lua")); err == nil {
+		t.Error("Expected an error for a missing file")
+	}
+
+	pool, err := NewStatePool(PoolOptions{})
+	if err != nil {
+		t.Fatalf("NewStatePool failed: %v", err)
+	}
+
+	L, err := pool.Get()
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	pool.Close()
+
+	if _, err := pool.Get(); !errors.Is(err, ErrPoolClosed) {
+		t.Errorf("Expected ErrPoolClosed, got %v", err)
+	}
+
+	// States borrowed before Close are closed when returned
+	pool.Put(L)
+	if !L.IsClosed() {
+		t.Error("Expected the state to be closed")
+	}
+}
+
+func TestStatePool_Concurrent(t *testing.T) {
+	pool, err := NewStatePool(PoolOptions{MaxIdle: 2})
+	if err != nil {
+		t.Fatalf("NewStatePool failed: %v", err)
+	}
+	defer pool.Close()
+
+	script, err := CompileString(`
+		assert(request == nil, "global leaked from a previous request")
+		request = id
+		return request * 2
+	`, "script.lua")
+	if err != nil {
+		t.Fatalf("CompileString failed: %v", err)
+	}
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 50)
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func(id int) {
+			defer wg.Done()
+			errs <- pool.Do(func(L *lua.LState) error {
+				L.SetGlobal("id", lua.LNumber(id))
+				if err := DoProto(L, script); err != nil {
+					return err
+				}
+				if got := int(lua.LVAsNumber(L.Get(-1))); got != id*2 {
+					return fmt.Errorf("expected %d, got %d", id*2, got)
+				}
+				return nil
+			})
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if err != nil {
+			t.Error(err)
+		}
+	}
+}
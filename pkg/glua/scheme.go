@@ -0,0 +1,93 @@
+// Copyright (c) 2024-2025 Thomas Maurice
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package glua
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// RegisterScheme: registers every kind known to a runtime.Scheme, e.g. the client-go
+// scheme for all built-in Kubernetes objects. Internal versions are skipped.
+// When group versions are given, only kinds from those are registered; a group version
+// with an empty Version matches every version of the group.
+// Returns an error if the scheme is nil or no kind matches the filter.
+//
+// Example:
+//
+//	registry.RegisterScheme(scheme.Scheme)                                                   // everything
+//	registry.RegisterScheme(scheme.Scheme, corev1.SchemeGroupVersion, schema.GroupVersion{Group: "apps"})
+func (r *TypeRegistry) RegisterScheme(scheme *runtime.Scheme, groupVersions ...schema.GroupVersion) error {
+	if scheme == nil {
+		return fmt.Errorf("cannot register nil scheme")
+	}
+
+	knownTypes := scheme.AllKnownTypes()
+
+	gvks := make([]schema.GroupVersionKind, 0, len(knownTypes))
+	for gvk := range knownTypes {
+		if gvk.Version == runtime.APIVersionInternal || !matchesGroupVersion(gvk, groupVersions) {
+			continue
+		}
+		gvks = append(gvks, gvk)
+	}
+
+	if len(gvks) == 0 {
+		return fmt.Errorf("no kind in scheme matches %v", groupVersions)
+	}
+
+	// Sort for a deterministic registration order
+	sort.Slice(gvks, func(i, j int) bool { return gvks[i].String() < gvks[j].String() })
+
+	registered := make(map[reflect.Type]bool)
+	for _, gvk := range gvks {
+		t := knownTypes[gvk]
+		if registered[t] {
+			continue
+		}
+		registered[t] = true
+
+		if err := r.Register(reflect.New(t).Interface()); err != nil {
+			return fmt.Errorf("failed to register %s: %w", gvk, err)
+		}
+	}
+
+	return nil
+}
+
+// matchesGroupVersion: checks whether a kind belongs to one of the group versions (all if none)
+func matchesGroupVersion(gvk schema.GroupVersionKind, groupVersions []schema.GroupVersion) bool {
+	if len(groupVersions) == 0 {
+		return true
+	}
+
+	for _, gv := range groupVersions {
+		if gv.Group == gvk.Group && (gv.Version == "" || gv.Version == gvk.Version) {
+			return true
+		}
+	}
+
+	return false
+}
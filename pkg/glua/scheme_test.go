@@ -0,0 +1,131 @@
+// Copyright (c) 2024-2025 Thomas Maurice
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package glua
+
+import (
+	"strings"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes/scheme"
+)
+
+// newTestScheme: creates a scheme with the core and apps groups
+func newTestScheme(t *testing.T) *runtime.Scheme {
+	t.Helper()
+
+	s := runtime.NewScheme()
+	if err := corev1.AddToScheme(s); err != nil {
+		t.Fatalf("Failed to add corev1 to scheme: %v", err)
+	}
+	if err := appsv1.AddToScheme(s); err != nil {
+		t.Fatalf("Failed to add appsv1 to scheme: %v", err)
+	}
+
+	return s
+}
+
+func TestTypeRegistry_RegisterScheme(t *testing.T) {
+	tests := []struct {
+		name          string
+		groupVersions []schema.GroupVersion
+		expected      []string
+		unexpected    []string
+	}{
+		{
+			name:     "all kinds",
+			expected: []string{"---@class corev1.Pod\n", "---@class corev1.ConfigMapList\n", "---@class appsv1.Deployment\n"},
+		},
+		{
+			name:          "filter by group version",
+			groupVersions: []schema.GroupVersion{appsv1.SchemeGroupVersion},
+			expected:      []string{"---@class appsv1.Deployment\n", "---@class appsv1.StatefulSetList\n", "---@class corev1.PodSpec\n"},
+			unexpected:    []string{"---@class corev1.Pod\n", "---@class corev1.ConfigMap\n"},
+		},
+		{
+			name:          "filter by group",
+			groupVersions: []schema.GroupVersion{{Group: ""}},
+			expected:      []string{"---@class corev1.Pod\n", "---@class corev1.Service\n"},
+			unexpected:    []string{"---@class appsv1.Deployment\n"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			registry := NewTypeRegistry()
+			if err := registry.RegisterScheme(newTestScheme(t), tt.groupVersions...); err != nil {
+				t.Fatalf("RegisterScheme failed: %v", err)
+			}
+			if err := registry.Process(); err != nil {
+				t.Fatalf("Process failed: %v", err)
+			}
+
+			stubs, err := registry.GenerateStubs()
+			if err != nil {
+				t.Fatalf("GenerateStubs failed: %v", err)
+			}
+
+			for _, expected := range tt.expected {
+				if !strings.Contains(stubs, expected) {
+					t.Errorf("Expected stubs to contain %q", expected)
+				}
+			}
+
+			for _, unexpected := range tt.unexpected {
+				if strings.Contains(stubs, unexpected) {
+					t.Errorf("Did not expect stubs to contain %q", unexpected)
+				}
+			}
+		})
+	}
+}
+
+func TestTypeRegistry_RegisterSchemeClientGo(t *testing.T) {
+	registry := NewTypeRegistry()
+
+	if err := registry.RegisterScheme(scheme.Scheme); err != nil {
+		t.Fatalf("RegisterScheme failed: %v", err)
+	}
+	if err := registry.Process(); err != nil {
+		t.Fatalf("Process failed: %v", err)
+	}
+
+	for _, name := range []string{"corev1.Pod", "appsv1.Deployment", "batchv1.CronJob", "networkingv1.Ingress", "rbacv1.ClusterRole"} {
+		if _, err := registry.TypeByName(name); err != nil {
+			t.Errorf("Expected %s to be registered: %v", name, err)
+		}
+	}
+}
+
+func TestTypeRegistry_RegisterSchemeErrors(t *testing.T) {
+	registry := NewTypeRegistry()
+
+	if err := registry.RegisterScheme(nil); err == nil {
+		t.Error("Expected error for nil scheme, got nil")
+	}
+
+	if err := registry.RegisterScheme(newTestScheme(t), schema.GroupVersion{Group: "batch", Version: "v1"}); err == nil {
+		t.Error("Expected error when no kind matches the filter, got nil")
+	}
+}
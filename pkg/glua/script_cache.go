@@ -0,0 +1,252 @@
+// Copyright (c) 2024-2025 Thomas Maurice
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package glua
+
+import (
+	"context"
+	"crypto/sha256"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	lua "github.com/yuin/gopher-lua"
+)
+
+// smallScriptSize: files up to this size are hashed on every Refresh, since a rewrite
+// within the resolution of the file system clock can keep both the modification time and size
+const smallScriptSize = 64 << 10
+
+// ScriptReload: the outcome of reloading a script whose file changed.
+// Err is set when the new version could not be read or compiled, the previous one is kept.
+type ScriptReload struct {
+	Path string
+	Err  error
+}
+
+// ScriptCache: Lua files compiled once and shared by every state, safe for concurrent use.
+// Refresh (or Watch) recompiles the files that changed and swaps the new version in
+// atomically: scripts already running finish with the version they started with, and a
+// version that fails to compile is reported without replacing the last good one.
+//
+// Example:
+//
+//	cache := glua.NewScriptCache()
+//	go cache.Watch(ctx, 2*time.Second, func(r glua.ScriptReload) {
+//		if r.Err != nil {
+//			log.Printf("keeping previous %s: %v", r.Path, r.Err)
+//		}
+//	})
+//
+//	err := cache.Run(L, "/etc/webhook/scripts/mutate.lua")
+type ScriptCache struct {
+	mu      sync.RWMutex // Guards the map, the current versions are swapped atomically
+	scripts map[string]*cachedScript
+
+	refreshMu sync.Mutex // Serializes Refresh, which owns the fields of the cached scripts
+}
+
+// cachedScript: the current version of a file and what it was compiled from
+type cachedScript struct {
+	proto atomic.Pointer[lua.FunctionProto]
+
+	modTime time.Time         // Modification time of the file when last read
+	size    int64             // Size of the file when last read, -1 to read it again
+	hash    [sha256.Size]byte // Hash of the current version
+	read    [sha256.Size]byte // Hash of the content last read, which may have failed to compile
+	err     error             // Error of the last reload, not reported again until it changes
+}
+
+// NewScriptCache: creates an empty script cache
+func NewScriptCache() *ScriptCache {
+	return &ScriptCache{scripts: make(map[string]*cachedScript)}
+}
+
+// Load: returns the compiled version of a file, compiling it on first use.
+// Once cached, the file is not read again until Refresh sees it change.
+func (c *ScriptCache) Load(path string) (*lua.FunctionProto, error) {
+	path = filepath.Clean(path)
+
+	c.mu.RLock()
+	script, ok := c.scripts[path]
+	c.mu.RUnlock()
+	if ok {
+		return script.proto.Load(), nil
+	}
+
+	// The file is read and compiled without the lock, so a slow one does not block other callers
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+
+	source, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	proto, err := CompileString(string(source), path)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// Compiled by another caller in the meantime, its version is kept
+	if script, ok := c.scripts[path]; ok {
+		return script.proto.Load(), nil
+	}
+
+	hash := sha256.Sum256(source)
+	script = &cachedScript{modTime: info.ModTime(), size: info.Size(), hash: hash, read: hash}
+	script.proto.Store(proto)
+	c.scripts[path] = script
+
+	return proto, nil
+}
+
+// Run: runs the current version of a file on a state, compiling it on first use
+func (c *ScriptCache) Run(L *lua.LState, path string) error {
+	proto, err := c.Load(path)
+	if err != nil {
+		return err
+	}
+
+	return DoProto(L, proto)
+}
+
+// Paths: returns the paths of the cached scripts, sorted
+func (c *ScriptCache) Paths() []string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	paths := make([]string, 0, len(c.scripts))
+	for path := range c.scripts {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	return paths
+}
+
+// Refresh: checks the cached files and recompiles those whose content changed, without
+// blocking Load. Returns the reloads, sorted by path. Files up to 64 KiB are read and hashed
+// on every call; larger ones are only read again when their modification time or size
+// changes, so a rewrite keeping both is missed. A file that is removed or fails to compile
+// is reported once, and keeps its last good version.
+func (c *ScriptCache) Refresh() []ScriptReload {
+	c.refreshMu.Lock()
+	defer c.refreshMu.Unlock()
+
+	var reloads []ScriptReload
+	for _, path := range c.Paths() {
+		c.mu.RLock()
+		script := c.scripts[path]
+		c.mu.RUnlock()
+
+		if reload, changed := script.refresh(path); changed {
+			reloads = append(reloads, reload)
+		}
+	}
+
+	return reloads
+}
+
+// Watch: calls Refresh every interval and fn with every reload, until ctx is done.
+// Changes are detected as in Refresh.
+func (c *ScriptCache) Watch(ctx context.Context, interval time.Duration, fn func(ScriptReload)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			for _, reload := range c.Refresh() {
+				if fn != nil {
+					fn(reload)
+				}
+			}
+		}
+	}
+}
+
+// refresh: recompiles the file if it changed, and returns the reload to report
+func (s *cachedScript) refresh(path string) (ScriptReload, bool) {
+	info, err := os.Stat(path)
+	if err != nil {
+		// Read again once the file is back, whatever its modification time
+		s.size, s.read = -1, [sha256.Size]byte{}
+		return s.fail(path, err)
+	}
+
+	if info.ModTime().Equal(s.modTime) && info.Size() == s.size && info.Size() > smallScriptSize {
+		return ScriptReload{}, false
+	}
+	s.modTime, s.size = info.ModTime(), info.Size()
+
+	source, err := os.ReadFile(path)
+	if err != nil {
+		s.size, s.read = -1, [sha256.Size]byte{}
+		return s.fail(path, err)
+	}
+
+	// Unchanged since the last read, whether it compiled or not
+	hash := sha256.Sum256(source)
+	if hash == s.read {
+		return ScriptReload{}, false
+	}
+	s.read = hash
+
+	// Restored to the current version after an error
+	if hash == s.hash {
+		if s.err == nil {
+			return ScriptReload{}, false
+		}
+		s.err = nil
+		return ScriptReload{Path: path}, true
+	}
+
+	proto, err := CompileString(string(source), path)
+	if err != nil {
+		return s.fail(path, err)
+	}
+
+	s.proto.Store(proto)
+	s.hash = hash
+	s.err = nil
+
+	return ScriptReload{Path: path}, true
+}
+
+// fail: records a reload error, reporting it only if it differs from the previous one
+func (s *cachedScript) fail(path string, err error) (ScriptReload, bool) {
+	if s.err != nil && s.err.Error() == err.Error() {
+		return ScriptReload{}, false
+	}
+
+	s.err = err
+	return ScriptReload{Path: path, Err: err}, true
+}
@@ -0,0 +1,299 @@
+// Copyright (c) 2024-2025 Thomas Maurice
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package glua
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	lua "github.com/yuin/gopher-lua"
+)
+
+// scriptWriter: writes a script with a distinct modification time on every call,
+// so that changes are seen on filesystems with a coarse timestamp resolution
+type scriptWriter struct {
+	t     *testing.T
+	path  string
+	mtime time.Time
+}
+
+// write: replaces the content of the script
+func (w *scriptWriter) write(source string) {
+	w.t.Helper()
+
+	if err := os.WriteFile(w.path, []byte(source), 0644); err != nil {
+		w.t.Fatalf("Failed to write script: %v", err)
+	}
+	w.mtime = w.mtime.Add(time.Second)
+	if err := os.Chtimes(w.path, w.mtime, w.mtime); err != nil {
+		w.t.Fatalf("Failed to set modification time: %v", err)
+	}
+}
+
+// rewrite: replaces the content of the script, keeping its modification time
+func (w *scriptWriter) rewrite(source string) {
+	w.t.Helper()
+
+	if err := os.WriteFile(w.path, []byte(source), 0644); err != nil {
+		w.t.Fatalf("Failed to write script: %v", err)
+	}
+	if err := os.Chtimes(w.path, w.mtime, w.mtime); err != nil {
+		w.t.Fatalf("Failed to set modification time: %v", err)
+	}
+}
+
+// runScript: runs the current version of the script and returns its result
+func runScript(t *testing.T, cache *ScriptCache, path string) string {
+	t.Helper()
+
+	L := lua.NewState()
+	defer L.Close()
+
+	if err := cache.Run(L, path); err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	return L.Get(-1).String()
+}
+
+func TestScriptCache_Reload(t *testing.T) {
+	w := &scriptWriter{t: t, path: filepath.Join(t.TempDir(), "script.lua"), mtime: time.Now().Add(-time.Hour)}
+	w.write(`return "v1"`)
+
+	cache := NewScriptCache()
+	first, err := cache.Load(w.path)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	again, err := cache.Load(w.path)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if first != again {
+		t.Error("Expected the script to be compiled once")
+	}
+
+	if reloads := cache.Refresh(); len(reloads) != 0 {
+		t.Errorf("Expected no reload, got %v", reloads)
+	}
+
+	// Without Refresh, the cached version is used
+	w.write(`return "v2"`)
+	if got := runScript(t, cache, w.path); got != "v1" {
+		t.Errorf("Expected v1 before Refresh, got %s", got)
+	}
+
+	tests := []struct {
+		name     string
+		change   func()
+		reloaded bool
+		err      string
+		expected string
+	}{
+		{
+			name:     "changed",
+			change:   func() {},
+			reloaded: true,
+			expected: "v2",
+		},
+		{
+			name:     "touched",
+			change:   func() { w.write(`return "v2"`) },
+			expected: "v2",
+		},
+		{
+			name:     "compile error keeps the last good version",
+			change:   func() { w.write(`return "v3`) },
+			reloaded: true,
+			err:      "script.lua",
+			expected: "v2",
+		},
+		{
+			name:     "same error is reported once",
+			change:   func() { w.write(`return "v3`) },
+			expected: "v2",
+		},
+		{
+			name:     "removed",
+			change:   func() { os.Remove(w.path) },
+			reloaded: true,
+			err:      "no such file",
+			expected: "v2",
+		},
+		{
+			name:     "restored to the current version",
+			change:   func() { w.write(`return "v2"`) },
+			reloaded: true,
+			expected: "v2",
+		},
+		{
+			name:     "fixed",
+			change:   func() { w.write(`return "v4"`) },
+			reloaded: true,
+			expected: "v4",
+		},
+		{
+			name:     "rewritten with the same size and modification time",
+			change:   func() { w.rewrite(`return "v5"`) },
+			reloaded: true,
+			expected: "v5",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.change()
+			reloads := cache.Refresh()
+
+			if !tt.reloaded {
+				if len(reloads) != 0 {
+					t.Errorf("Expected no reload, got %v", reloads)
+				}
+			} else if len(reloads) != 1 || reloads[0].Path != w.path {
+				t.Fatalf("Expected a reload of %s, got %v", w.path, reloads)
+			} else if tt.err == "" && reloads[0].Err != nil {
+				t.Errorf("Unexpected error: %v", reloads[0].Err)
+			} else if tt.err != "" && (reloads[0].Err == nil || !strings.Contains(reloads[0].Err.Error(), tt.err)) {
+				t.Errorf("Expected an error containing %q, got %v", tt.err, reloads[0].Err)
+			}
+
+			if got := runScript(t, cache, w.path); got != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestScriptCache_Errors(t *testing.T) {
+	dir := t.TempDir()
+	cache := NewScriptCache()
+
+	if _, err := cache.Load(filepath.Join(dir, "missing.lua")); err == nil {
+		t.Error("Expected an error for a missing file")
+	}
+
+	broken := filepath.Join(dir, "broken.lua")
+	if err := os.WriteFile(broken, []byte("local = 1"), 0644); err != nil {
+		t.Fatalf("Failed to write script: %v", err)
+	}
+	if _, err := cache.Load(broken); err == nil || !strings.Contains(err.Error(), "broken.lua") {
+		t.Errorf("Expected a compile error naming the script, got %v", err)
+	}
+
+	// Scripts that failed to load are not cached
+	if paths := cache.Paths(); len(paths) != 0 {
+		t.Errorf("Expected no cached script, got %v", paths)
+	}
+}
+
+func TestScriptCache_ConcurrentLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "script.lua")
+	if err := os.WriteFile(path, []byte("return 1"), 0644); err != nil {
+		t.Fatalf("Failed to write script: %v", err)
+	}
+
+	cache := NewScriptCache()
+	protos := make([]*lua.FunctionProto, 16)
+
+	var wg sync.WaitGroup
+	for i := range protos {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			proto, err := cache.Load(path)
+			if err != nil {
+				t.Errorf("Load failed: %v", err)
+			}
+			protos[i] = proto
+		}(i)
+	}
+	wg.Wait()
+
+	// Callers compiling the file at the same time all get the version that was cached
+	cached, err := cache.Load(path)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	for i, proto := range protos {
+		if proto != cached {
+			t.Errorf("Load %d returned a version that was not cached", i)
+		}
+	}
+}
+
+func TestScriptCache_Watch(t *testing.T) {
+	w := &scriptWriter{t: t, path: filepath.Join(t.TempDir(), "script.lua"), mtime: time.Now().Add(-time.Hour)}
+	w.write(`return 1`)
+
+	cache := NewScriptCache()
+	if _, err := cache.Load(w.path); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var reloads []ScriptReload
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		cache.Watch(ctx, 10*time.Millisecond, func(reload ScriptReload) {
+			reloads = append(reloads, reload)
+			cancel()
+		})
+	}()
+
+	// Requests keep running while the script is swapped
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			L := lua.NewState()
+			defer L.Close()
+
+			for ctx.Err() == nil {
+				if err := cache.Run(L, w.path); err != nil {
+					t.Errorf("Run failed: %v", err)
+					return
+				}
+				L.SetTop(0)
+			}
+		}()
+	}
+
+	w.write(`return 2`)
+	<-done
+	wg.Wait()
+
+	if len(reloads) != 1 || reloads[0].Err != nil {
+		t.Fatalf("Expected one successful reload, got %v", reloads)
+	}
+	if got := runScript(t, cache, w.path); got != "2" {
+		t.Errorf("Expected 2, got %s", got)
+	}
+}
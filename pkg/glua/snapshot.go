@@ -0,0 +1,209 @@
+// Copyright (c) 2024-2025 Thomas Maurice
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package glua
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+)
+
+// SnapshotVersion: the format version written in registry snapshots
+const SnapshotVersion = 1
+
+// RegistrySnapshot: serializable view of the Lua types generated by a TypeRegistry.
+// Snapshots are meant to be checked in and compared with DiffSnapshots to detect
+// changes that break scripts, e.g. when upgrading k8s.io/api.
+type RegistrySnapshot struct {
+	Version int                          `json:"version"`           // Snapshot format version
+	Classes map[string]map[string]string `json:"classes"`           // Class name to field name to Lua type
+	Aliases map[string]string            `json:"aliases,omitempty"` // Alias name to aliased Lua type
+	Globals map[string]string            `json:"globals,omitempty"` // Global name to Lua type
+}
+
+// ChangeKind: the kind of a change between two snapshots
+type ChangeKind string
+
+const (
+	ChangeAdded   ChangeKind = "added"   // The element only exists in the new snapshot
+	ChangeRemoved ChangeKind = "removed" // The element only exists in the old snapshot
+	ChangeRetyped ChangeKind = "retyped" // The element exists in both with a different type
+)
+
+// SnapshotChange: a single difference between two snapshots
+type SnapshotChange struct {
+	Kind     ChangeKind `json:"kind"`              // What happened to the element
+	Element  string     `json:"element"`           // "class", "field", "alias" or "global"
+	Name     string     `json:"name"`              // The element name (e.g., "corev1.Pod.spec" for fields)
+	OldType  string     `json:"oldType,omitempty"` // The Lua type in the old snapshot
+	NewType  string     `json:"newType,omitempty"` // The Lua type in the new snapshot
+	Breaking bool       `json:"breaking"`          // Whether scripts relying on the old type may break
+}
+
+// String: formats the change for reports (e.g., "retyped field corev1.Pod.spec: A -> B")
+func (c SnapshotChange) String() string {
+	switch c.Kind {
+	case ChangeRetyped:
+		return fmt.Sprintf("%s %s %s: %s -> %s", c.Kind, c.Element, c.Name, c.OldType, c.NewType)
+	case ChangeRemoved:
+		if c.OldType != "" {
+			return fmt.Sprintf("%s %s %s (%s)", c.Kind, c.Element, c.Name, c.OldType)
+		}
+	case ChangeAdded:
+		if c.NewType != "" {
+			return fmt.Sprintf("%s %s %s (%s)", c.Kind, c.Element, c.Name, c.NewType)
+		}
+	}
+	return fmt.Sprintf("%s %s %s", c.Kind, c.Element, c.Name)
+}
+
+// Snapshot: captures the classes, aliases and globals the registry generates stubs for.
+// Call it after Process().
+func (r *TypeRegistry) Snapshot() *RegistrySnapshot {
+	snapshot := &RegistrySnapshot{
+		Version: SnapshotVersion,
+		Classes: make(map[string]map[string]string),
+		Aliases: make(map[string]string),
+		Globals: make(map[string]string),
+	}
+
+	for _, typeInfo := range r.types {
+		// Types without fields are not emitted as classes by GenerateStubs
+		if len(typeInfo.Fields) == 0 {
+			continue
+		}
+
+		fields := make(map[string]string, len(typeInfo.Fields))
+		for name, field := range typeInfo.Fields {
+			fields[name] = field.TypeKey
+		}
+		snapshot.Classes[typeInfo.Name] = fields
+	}
+
+	for _, alias := range r.aliases {
+		snapshot.Aliases[alias.Name] = alias.Definition
+	}
+
+	for _, global := range r.globals {
+		snapshot.Globals[global.Name] = global.TypeKey
+	}
+
+	return snapshot
+}
+
+// MarshalSnapshot: serializes a snapshot of the registry as indented JSON
+func (r *TypeRegistry) MarshalSnapshot() ([]byte, error) {
+	return json.MarshalIndent(r.Snapshot(), "", "  ")
+}
+
+// UnmarshalSnapshot: parses a snapshot written by MarshalSnapshot
+func UnmarshalSnapshot(data []byte) (*RegistrySnapshot, error) {
+	var snapshot RegistrySnapshot
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return nil, fmt.Errorf("failed to parse snapshot: %w", err)
+	}
+
+	if snapshot.Version != SnapshotVersion {
+		return nil, fmt.Errorf("unsupported snapshot version %d, expected %d", snapshot.Version, SnapshotVersion)
+	}
+
+	return &snapshot, nil
+}
+
+// DiffSnapshots: compares two snapshots and returns the added, removed and retyped
+// classes, fields, aliases and globals, sorted by element name.
+// Removals and type changes are breaking. Snapshots describe values scripts read as
+// well as write, so even a widened type (an enum alias gaining a constant, a field
+// becoming number|string) can hand a script a value it does not handle.
+func DiffSnapshots(oldSnapshot, newSnapshot *RegistrySnapshot) []SnapshotChange {
+	var changes []SnapshotChange
+
+	for _, class := range unionKeys(oldSnapshot.Classes, newSnapshot.Classes) {
+		oldFields, inOld := oldSnapshot.Classes[class]
+		newFields, inNew := newSnapshot.Classes[class]
+
+		switch {
+		case !inNew:
+			changes = append(changes, SnapshotChange{Kind: ChangeRemoved, Element: "class", Name: class, Breaking: true})
+		case !inOld:
+			changes = append(changes, SnapshotChange{Kind: ChangeAdded, Element: "class", Name: class})
+		default:
+			changes = append(changes, diffTypes("field", class+".", oldFields, newFields)...)
+		}
+	}
+
+	changes = append(changes, diffTypes("alias", "", oldSnapshot.Aliases, newSnapshot.Aliases)...)
+	changes = append(changes, diffTypes("global", "", oldSnapshot.Globals, newSnapshot.Globals)...)
+
+	return changes
+}
+
+// HasBreakingChanges: reports whether any of the changes is breaking
+func HasBreakingChanges(changes []SnapshotChange) bool {
+	for _, change := range changes {
+		if change.Breaking {
+			return true
+		}
+	}
+	return false
+}
+
+// diffTypes: compares two maps of names to Lua types
+func diffTypes(element, prefix string, oldTypes, newTypes map[string]string) []SnapshotChange {
+	var changes []SnapshotChange
+
+	for _, name := range unionKeys(oldTypes, newTypes) {
+		oldType, inOld := oldTypes[name]
+		newType, inNew := newTypes[name]
+
+		change := SnapshotChange{Element: element, Name: prefix + name, OldType: oldType, NewType: newType}
+		switch {
+		case !inNew:
+			change.Kind = ChangeRemoved
+			change.Breaking = true
+		case !inOld:
+			change.Kind = ChangeAdded
+		case oldType != newType:
+			change.Kind = ChangeRetyped
+			change.Breaking = true
+		default:
+			continue
+		}
+		changes = append(changes, change)
+	}
+
+	return changes
+}
+
+// unionKeys: returns the sorted keys present in either map
+func unionKeys[V any](a, b map[string]V) []string {
+	keys := make([]string, 0, len(a)+len(b))
+	for key := range a {
+		keys = append(keys, key)
+	}
+	for key := range b {
+		if _, exists := a[key]; !exists {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
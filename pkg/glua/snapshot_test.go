@@ -0,0 +1,135 @@
+// Copyright (c) 2024-2025 Thomas Maurice
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package glua
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTypeRegistry_Snapshot(t *testing.T) {
+	type Item struct {
+		Name string `json:"name"`
+		Mode Mode   `json:"mode"`
+	}
+
+	registry := NewTypeRegistry()
+	if err := registry.RegisterEnum(ModeFast, ModeFast, ModeSafe); err != nil {
+		t.Fatalf("RegisterEnum failed: %v", err)
+	}
+	if err := registry.RegisterGlobal("item", &Item{}); err != nil {
+		t.Fatalf("RegisterGlobal failed: %v", err)
+	}
+	if err := registry.Process(); err != nil {
+		t.Fatalf("Process failed: %v", err)
+	}
+
+	data, err := registry.MarshalSnapshot()
+	if err != nil {
+		t.Fatalf("MarshalSnapshot failed: %v", err)
+	}
+
+	snapshot, err := UnmarshalSnapshot(data)
+	if err != nil {
+		t.Fatalf("UnmarshalSnapshot failed: %v", err)
+	}
+
+	expected := &RegistrySnapshot{
+		Version: SnapshotVersion,
+		Classes: map[string]map[string]string{
+			"glua.Item": {"name": "string", "mode": "glua.Mode"},
+		},
+		Aliases: map[string]string{"glua.Mode": `"fast"|"safe"`},
+		Globals: map[string]string{"item": "glua.Item"},
+	}
+
+	if !reflect.DeepEqual(snapshot, expected) {
+		t.Errorf("Expected snapshot %+v, got %+v", expected, snapshot)
+	}
+
+	if _, err := UnmarshalSnapshot([]byte(`{"version": 42}`)); err == nil {
+		t.Error("Expected error for unsupported version, got nil")
+	}
+}
+
+func TestDiffSnapshots(t *testing.T) {
+	oldSnapshot := &RegistrySnapshot{
+		Version: SnapshotVersion,
+		Classes: map[string]map[string]string{
+			"corev1.Container": {"name": "string", "image": "string", "ports": "corev1.ContainerPort[]", "port": "number"},
+			"corev1.Legacy":    {"value": "string"},
+		},
+		Aliases: map[string]string{
+			"corev1.PullPolicy": `"Always"|"Never"`,
+			"corev1.Protocol":   `"TCP"|"UDP"`,
+		},
+		Globals: map[string]string{"pod": "corev1.Pod"},
+	}
+
+	newSnapshot := &RegistrySnapshot{
+		Version: SnapshotVersion,
+		Classes: map[string]map[string]string{
+			"corev1.Container": {"name": "string", "image": "number", "ports": "corev1.ContainerPort[]", "port": "number|string", "stdin": "boolean"},
+			"corev1.Probe":     {"exec": "corev1.ExecAction"},
+		},
+		Aliases: map[string]string{
+			"corev1.PullPolicy": `"Always"|"Never"|"IfNotPresent"`,
+			"corev1.Protocol":   `"TCP"`,
+		},
+		Globals: map[string]string{"pod": "corev1.Pod", "namespace": "string"},
+	}
+
+	changes := DiffSnapshots(oldSnapshot, newSnapshot)
+
+	expected := []string{
+		"retyped field corev1.Container.image: string -> number",
+		"retyped field corev1.Container.port: number -> number|string",
+		"added field corev1.Container.stdin (boolean)",
+		"removed class corev1.Legacy",
+		"added class corev1.Probe",
+		`retyped alias corev1.Protocol: "TCP"|"UDP" -> "TCP"`,
+		`retyped alias corev1.PullPolicy: "Always"|"Never" -> "Always"|"Never"|"IfNotPresent"`,
+		"added global namespace (string)",
+	}
+	// Widened types are breaking too, scripts reading them may get values they do not handle
+	breaking := []bool{true, true, false, true, false, true, true, false}
+
+	if len(changes) != len(expected) {
+		t.Fatalf("Expected %d changes, got %d: %v", len(expected), len(changes), changes)
+	}
+
+	for i, change := range changes {
+		if change.String() != expected[i] {
+			t.Errorf("Expected change %q, got %q", expected[i], change.String())
+		}
+		if change.Breaking != breaking[i] {
+			t.Errorf("Expected %q breaking=%v, got %v", change.String(), breaking[i], change.Breaking)
+		}
+	}
+
+	if !HasBreakingChanges(changes) {
+		t.Error("Expected breaking changes to be detected")
+	}
+
+	if changes := DiffSnapshots(oldSnapshot, oldSnapshot); len(changes) != 0 {
+		t.Errorf("Expected no changes between identical snapshots, got %v", changes)
+	}
+}
@@ -0,0 +1,319 @@
+// Copyright (c) 2024-2025 Thomas Maurice
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package glua
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// tealRecord: a Teal record declaration being assembled for one Lua package prefix
+type tealRecord struct {
+	records map[string]*TypeInfo // Nested records by short name
+	enums   map[string][]string  // Nested enums by short name
+}
+
+// GenerateTealDeclarations: generates Teal declarations (.d.tl) for all registered types.
+// Types are grouped into one global record per package prefix, so corev1.Pod is declared as
+// the nested record Pod of the global record corev1 and can be referenced as corev1.Pod.
+// Slices become arrays ({T}), maps become maps ({K:V}) and string types declared with
+// RegisterEnum become enums. Types registered from a CRD are declared from the Lua types of
+// their fields, with enums as plain strings or numbers. Teal record fields always accept nil,
+// so fields that may be omitted are only marked with an "-- optional" comment.
+//
+// Example output:
+//
+//	global record corev1
+//	   record Pod
+//	      metadata: v1.ObjectMeta -- optional
+//	      spec: corev1.PodSpec -- optional
+//	   end
+//	end
+func (r *TypeRegistry) GenerateTealDeclarations() (string, error) {
+	packages := make(map[string]*tealRecord)
+	getPackage := func(name string) *tealRecord {
+		if _, exists := packages[name]; !exists {
+			packages[name] = &tealRecord{
+				records: make(map[string]*TypeInfo),
+				enums:   make(map[string][]string),
+			}
+		}
+		return packages[name]
+	}
+
+	for _, typeInfo := range r.types {
+		// Types registered from a CRD have no Go type, their fields are declared from their Lua types
+		if typeInfo.GoType != nil && (typeInfo.GoType.Name() == "" || r.customSchema(typeInfo.GoType) != nil) {
+			continue
+		}
+
+		pkg, name := splitTypeName(typeInfo.Name)
+		getPackage(pkg).records[name] = typeInfo
+
+		// Enums used by the fields of this record are declared next to it
+		for _, field := range typeInfo.Fields {
+			r.collectTealEnums(field.GoType, getPackage)
+		}
+	}
+
+	var sb strings.Builder
+
+	for _, pkgName := range sortedKeys(packages) {
+		pkg := packages[pkgName]
+
+		indent := "   "
+		if pkgName != "" {
+			fmt.Fprintf(&sb, "global record %s\n", pkgName)
+		} else {
+			indent = ""
+		}
+
+		for _, enumName := range sortedKeys(pkg.enums) {
+			r.writeTealEnum(&sb, indent, pkgName, enumName, pkg.enums[enumName])
+		}
+
+		for _, recordName := range sortedKeys(pkg.records) {
+			r.writeTealRecord(&sb, indent, pkgName, recordName, pkg.records[recordName])
+		}
+
+		if pkgName != "" {
+			sb.WriteString("end\n\n")
+		}
+	}
+
+	return sb.String(), nil
+}
+
+// writeTealEnum: writes a Teal enum declaration
+func (r *TypeRegistry) writeTealEnum(sb *strings.Builder, indent, pkgName, name string, values []string) {
+	if pkgName == "" {
+		fmt.Fprintf(sb, "global enum %s\n", name)
+	} else {
+		fmt.Fprintf(sb, "%senum %s\n", indent, name)
+	}
+
+	for _, value := range values {
+		fmt.Fprintf(sb, "%s   %q\n", indent, value)
+	}
+
+	fmt.Fprintf(sb, "%send\n\n", indent)
+}
+
+// writeTealRecord: writes a Teal record declaration for a struct type
+func (r *TypeRegistry) writeTealRecord(sb *strings.Builder, indent, pkgName, name string, typeInfo *TypeInfo) {
+	if pkgName == "" {
+		fmt.Fprintf(sb, "global record %s\n", name)
+	} else {
+		fmt.Fprintf(sb, "%srecord %s\n", indent, name)
+	}
+
+	for _, fieldName := range sortedKeys(typeInfo.Fields) {
+		field := typeInfo.Fields[fieldName]
+		fieldType := r.tealType(field.GoType)
+		if field.GoType == nil {
+			fieldType = tealTypeFromLua(field.TypeKey)
+		}
+		fmt.Fprintf(sb, "%s   %s: %s", indent, LuaFieldName(field.Name), fieldType)
+		if field.Optional {
+			sb.WriteString(" -- optional")
+		}
+		sb.WriteString("\n")
+	}
+
+	fmt.Fprintf(sb, "%send\n\n", indent)
+}
+
+// collectTealEnums: finds string enum types reachable from a field type
+func (r *TypeRegistry) collectTealEnums(t reflect.Type, getPackage func(string) *tealRecord) {
+	if t == nil {
+		return
+	}
+
+	t = r.unwrapPointer(t)
+
+	switch t.Kind() {
+	case reflect.Slice, reflect.Array:
+		r.collectTealEnums(t.Elem(), getPackage)
+	case reflect.Map:
+		r.collectTealEnums(t.Key(), getPackage)
+		r.collectTealEnums(t.Elem(), getPackage)
+	case reflect.String:
+		values, ok := r.enums[r.getTypeKey(t)]
+		if !ok || t.Name() == "" {
+			return
+		}
+
+		pkg, name := splitTypeName(r.getTypeName(t))
+		if _, exists := getPackage(pkg).enums[name]; exists {
+			return
+		}
+
+		strs := make([]string, 0, len(values))
+		for _, value := range values {
+			strs = append(strs, fmt.Sprintf("%v", value))
+		}
+		getPackage(pkg).enums[name] = strs
+	}
+}
+
+// tealType: returns the Teal type annotation for a Go type
+func (r *TypeRegistry) tealType(t reflect.Type) string {
+	if t == nil {
+		return "any"
+	}
+
+	t = r.unwrapPointer(t)
+
+	if schema := r.customSchema(t); schema != nil {
+		switch schemaType := schema["type"].(type) {
+		case string:
+			return tealPrimitive(schemaType)
+		case []string:
+			parts := make([]string, len(schemaType))
+			for i, part := range schemaType {
+				parts[i] = tealPrimitive(part)
+			}
+			return strings.Join(parts, " | ")
+		}
+		return "any"
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		if _, ok := r.enums[r.getTypeKey(t)]; ok && t.Name() != "" {
+			return r.getTypeName(t)
+		}
+		return "string"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "integer"
+	case reflect.Float32, reflect.Float64:
+		return "number"
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return "string"
+		}
+		return "{" + r.tealType(t.Elem()) + "}"
+	case reflect.Map:
+		// Keys are converted to strings, like in the LuaLS stubs
+		key := "string"
+		if t.Key().Kind() == reflect.String {
+			key = r.tealType(t.Key())
+		}
+		return "{" + key + ":" + r.tealType(t.Elem()) + "}"
+	case reflect.Struct:
+		// Teal has no anonymous record types
+		if t.Name() == "" {
+			return "{string:any}"
+		}
+		if typeInfo, exists := r.types[r.getTypeKey(t)]; exists {
+			return typeInfo.Name
+		}
+		return r.getTypeName(t)
+	}
+
+	return "any"
+}
+
+// tealTypeFromLua: converts the Lua type of a field registered from a CRD schema to Teal.
+// Unions of string or number literals (enums) become string or number, as Teal has no literal types.
+func tealTypeFromLua(typeKey string) string {
+	if parts := splitLuaUnion(typeKey); len(parts) > 1 {
+		seen := make(map[string]bool)
+		var types []string
+		for _, part := range parts {
+			if t := tealTypeFromLua(part); !seen[t] {
+				seen[t] = true
+				types = append(types, t)
+			}
+		}
+		return strings.Join(types, " | ")
+	}
+
+	switch {
+	case strings.HasPrefix(typeKey, "(") && strings.HasSuffix(typeKey, ")"):
+		return tealTypeFromLua(typeKey[1 : len(typeKey)-1])
+	case strings.HasSuffix(typeKey, "[]"):
+		return "{" + tealTypeFromLua(strings.TrimSuffix(typeKey, "[]")) + "}"
+	case strings.HasPrefix(typeKey, "table<string, ") && strings.HasSuffix(typeKey, ">"):
+		return "{string:" + tealTypeFromLua(typeKey[len("table<string, "):len(typeKey)-1]) + "}"
+	case strings.HasPrefix(typeKey, `"`):
+		return "string"
+	case typeKey == "true" || typeKey == "false":
+		return "boolean"
+	case typeKey != "" && (typeKey[0] == '-' || (typeKey[0] >= '0' && typeKey[0] <= '9')):
+		return "number"
+	}
+
+	return typeKey
+}
+
+// splitLuaUnion: splits a Lua type on the | separators that are not nested in brackets
+func splitLuaUnion(typeKey string) []string {
+	var parts []string
+	depth, start := 0, 0
+	for i, ch := range typeKey {
+		switch ch {
+		case '(', '<':
+			depth++
+		case ')', '>':
+			depth--
+		case '|':
+			if depth == 0 {
+				parts = append(parts, typeKey[start:i])
+				start = i + 1
+			}
+		}
+	}
+
+	return append(parts, typeKey[start:])
+}
+
+// tealPrimitive: maps a JSON Schema primitive type name to a Teal type
+func tealPrimitive(schemaType string) string {
+	switch schemaType {
+	case "string", "number", "boolean", "integer":
+		return schemaType
+	}
+	return "any"
+}
+
+// splitTypeName: splits a Lua type name into its package prefix and short name
+func splitTypeName(name string) (string, string) {
+	if idx := strings.Index(name, "."); idx >= 0 {
+		return name[:idx], name[idx+1:]
+	}
+	return "", name
+}
+
+// sortedKeys: returns the keys of a string-keyed map in sorted order
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
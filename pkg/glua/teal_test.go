@@ -0,0 +1,192 @@
+// Copyright (c) 2024-2025 Thomas Maurice
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package glua
+
+import (
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestTypeRegistry_TealDeclarations(t *testing.T) {
+	type Address struct {
+		City string `json:"city"`
+	}
+
+	type Person struct {
+		Name    string            `json:"name"`
+		Age     int               `json:"age,omitempty"`
+		Score   float64           `json:"score"`
+		Home    *Address          `json:"home,omitempty"`
+		Tags    []string          `json:"tags"`
+		Labels  map[string]string `json:"labels"`
+		Scores  map[int]float64   `json:"scores"`
+		Mode    Mode              `json:"mode"`
+		End     string            `json:"end"`
+		Dashed  string            `json:"x-dashed"`
+		Options struct {
+			Verbose bool `json:"verbose"`
+		} `json:"options"`
+	}
+
+	registry := NewTypeRegistry()
+	if err := registry.RegisterEnum(ModeFast, ModeFast, ModeSafe); err != nil {
+		t.Fatalf("RegisterEnum failed: %v", err)
+	}
+	if err := registry.Register(Person{}); err != nil {
+		t.Fatalf("Register failed: %v", err)
+	}
+	if err := registry.Process(); err != nil {
+		t.Fatalf("Process failed: %v", err)
+	}
+
+	decl, err := registry.GenerateTealDeclarations()
+	if err != nil {
+		t.Fatalf("GenerateTealDeclarations failed: %v", err)
+	}
+
+	tests := []string{
+		"global record glua\n",
+		"   enum Mode\n      \"fast\"\n      \"safe\"\n   end\n",
+		"   record Address\n      city: string\n   end\n",
+		"   record Person\n",
+		"      age: integer -- optional\n",
+		"      home: glua.Address -- optional\n",
+		"      labels: {string:string}\n",
+		"      mode: glua.Mode\n",
+		"      name: string\n",
+		"      options: {string:any}\n",
+		"      score: number\n",
+		"      scores: {string:number}\n",
+		"      tags: {string}\n",
+		"      [\"end\"]: string\n",
+		"      [\"x-dashed\"]: string\n",
+	}
+
+	for _, expected := range tests {
+		if !strings.Contains(decl, expected) {
+			t.Errorf("Expected declarations to contain %q, got:\n%s", expected, decl)
+		}
+	}
+
+	if strings.Count(decl, "global record glua") != 1 {
+		t.Errorf("Expected a single glua record, got:\n%s", decl)
+	}
+}
+
+func TestTypeRegistry_TealDeclarationsKubernetes(t *testing.T) {
+	registry := NewTypeRegistry()
+	if err := registry.Register(&corev1.Pod{}); err != nil {
+		t.Fatalf("Register failed: %v", err)
+	}
+	if err := registry.Process(); err != nil {
+		t.Fatalf("Process failed: %v", err)
+	}
+
+	decl, err := registry.GenerateTealDeclarations()
+	if err != nil {
+		t.Fatalf("GenerateTealDeclarations failed: %v", err)
+	}
+
+	tests := []string{
+		"global record corev1\n",
+		"global record v1\n",
+		"      metadata: v1.ObjectMeta -- optional\n",
+		"      containers: {corev1.Container}\n",
+		"      creationTimestamp: string -- optional\n",
+		"      limits: {string:string} -- optional\n",
+		"      port: integer | string\n",
+	}
+
+	for _, expected := range tests {
+		if !strings.Contains(decl, expected) {
+			t.Errorf("Expected declarations to contain %q", expected)
+		}
+	}
+
+	// Types with custom JSON encodings are not declared as records
+	for _, unexpected := range []string{"record Time\n", "record Quantity\n", "record IntOrString\n"} {
+		if strings.Contains(decl, unexpected) {
+			t.Errorf("Did not expect declarations to contain %q", unexpected)
+		}
+	}
+}
+
+func TestTypeRegistry_TealDeclarationsCRD(t *testing.T) {
+	registry := NewTypeRegistry()
+	if err := registry.RegisterCRDYAML([]byte(widgetCRD)); err != nil {
+		t.Fatalf("RegisterCRDYAML failed: %v", err)
+	}
+	if err := registry.Process(); err != nil {
+		t.Fatalf("Process failed: %v", err)
+	}
+
+	decl, err := registry.GenerateTealDeclarations()
+	if err != nil {
+		t.Fatalf("GenerateTealDeclarations failed: %v", err)
+	}
+
+	// Types registered from a CRD are declared from the Lua types of their fields
+	tests := []string{
+		"global record examplecomv1\n",
+		"   record Widget\n",
+		"      metadata: v1.ObjectMeta -- optional\n",
+		"      spec: examplecomv1.WidgetSpec -- optional\n",
+		"      size: number\n",
+		"      color: string -- optional\n",
+		"      port: number | string -- optional\n",
+		"      [\"max-surge\"]: number -- optional\n",
+		"      labels: {string:string} -- optional\n",
+		"      config: {string:any} -- optional\n",
+		"      parts: {examplecomv1.WidgetSpecParts} -- optional\n",
+		"   record WidgetStatus\n      ready: boolean -- optional\n",
+	}
+
+	for _, expected := range tests {
+		if !strings.Contains(decl, expected) {
+			t.Errorf("Expected declarations to contain %q, got:\n%s", expected, decl)
+		}
+	}
+}
+
+func TestLuaFieldName(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected string
+	}{
+		{"name", "name"},
+		{"_private2", "_private2"},
+		{"max-surge", `["max-surge"]`},
+		{"2fa", `["2fa"]`},
+		{"end", `["end"]`},
+		{"goto", `["goto"]`},
+		{"", `[""]`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := LuaFieldName(tt.name); got != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, got)
+			}
+		})
+	}
+}
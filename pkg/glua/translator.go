@@ -29,13 +29,22 @@ import (
 )
 
 // Translator: handles conversion between Go values and Lua values
-type Translator struct{}
+type Translator struct {
+	registry *TypeRegistry // Optional registry used to validate tables built by constructors
+}
 
 // NewTranslator: creates a new Translator instance
 func NewTranslator() *Translator {
 	return &Translator{}
 }
 
+// NewTranslatorWithRegistry: creates a Translator that validates tables marked by the
+// constructors of ConstructorsLoader. FromLua rejects a marked table whose type does not
+// match the output, or that gained unknown or wrongly typed fields since it was built.
+func NewTranslatorWithRegistry(registry *TypeRegistry) *Translator {
+	return &Translator{registry: registry}
+}
+
 // ToLua: converts an arbitrary Go value to a Lua value.
 // It supports primitive types (string, int64, etc.) and complex structs.
 // The conversion process:
@@ -126,6 +135,10 @@ func (t *Translator) toLuaValue(L *lua.LState, v interface{}) (lua.LValue, error
 //  2. Marshal the value to JSON
 //  3. Unmarshal JSON into output object
 func (t *Translator) FromLua(L *lua.LState, lv lua.LValue, output interface{}) error {
+	if err := t.checkMarkedType(lv, output); err != nil {
+		return err
+	}
+
 	// Convert Lua value to Go value
 	data, err := t.fromLuaValue(lv)
 	if err != nil {
@@ -146,6 +159,34 @@ func (t *Translator) FromLua(L *lua.LState, lv lua.LValue, output interface{}) e
 	return nil
 }
 
+// checkMarkedType: validates tables marked by a constructor against the output type.
+// Does nothing without a registry or for unmarked values.
+func (t *Translator) checkMarkedType(lv lua.LValue, output interface{}) error {
+	markedType, ok := MarkedType(lv)
+	if t.registry == nil || !ok || output == nil {
+		return nil
+	}
+
+	outputType := reflect.TypeOf(output)
+	for outputType.Kind() == reflect.Ptr {
+		outputType = outputType.Elem()
+	}
+
+	if outputType.Kind() != reflect.Struct {
+		return nil
+	}
+
+	if name := t.registry.getTypeName(outputType); name != markedType {
+		return fmt.Errorf("cannot convert %s table to %s", markedType, name)
+	}
+
+	if err := t.registry.validate(lv, outputType, false); err != nil {
+		return fmt.Errorf("invalid %s: %w", markedType, err)
+	}
+
+	return nil
+}
+
 // fromLuaValue: recursively converts Lua values to Go values
 func (t *Translator) fromLuaValue(lv lua.LValue) (interface{}, error) {
 	switch v := lv.(type) {
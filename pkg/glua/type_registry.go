@@ -23,38 +23,67 @@ package glua
 import (
 	"fmt"
 	"reflect"
+	"regexp"
 	"sort"
 	"strings"
 )
 
 // TypeInfo: stores information about a registered Go type for Lua stub generation
 type TypeInfo struct {
-	Name       string                // The Lua-friendly type name (e.g., "corev1.Pod")
-	GoType     reflect.Type          // The original Go type
-	Fields     map[string]*FieldInfo // Map of field name to field information
-	IsArray    bool                  // Whether this is an array type
-	ElementKey string                // For arrays, the type key of the element
+	Name        string                // The Lua-friendly type name (e.g., "corev1.Pod")
+	GoType      reflect.Type          // The original Go type (nil for types registered from a CRD schema)
+	Fields      map[string]*FieldInfo // Map of field name to field information
+	IsArray     bool                  // Whether this is an array type
+	ElementKey  string                // For arrays, the type key of the element
+	Description string                // Optional description emitted above the class
 }
 
 // FieldInfo: stores information about a struct field for Lua stub generation
 type FieldInfo struct {
-	Name    string // The field name (from JSON tag)
-	TypeKey string // The Lua type annotation (e.g., "string", "number", "corev1.Container")
-	IsArray bool   // Whether this field is an array
+	Name        string       // The field name (from JSON tag)
+	TypeKey     string       // The Lua type annotation (e.g., "string", "number", "corev1.Container")
+	IsArray     bool         // Whether this field is an array
+	GoType      reflect.Type // The Go type of the field
+	Optional    bool         // Whether the field may be omitted (omitempty, omitzero or pointer)
+	Description string       // Optional description emitted after the field type
+}
+
+// GlobalInfo: stores information about a global variable injected into Lua scripts
+type GlobalInfo struct {
+	Name    string       // The Lua global variable name (e.g., "pod")
+	GoType  reflect.Type // The Go type of the injected value
+	TypeKey string       // The Lua type annotation, resolved by Process()
+}
+
+// AliasInfo: stores a named Go type emitted as a ---@alias declaration,
+// such as an enum type or a named map type
+type AliasInfo struct {
+	Name       string // The Lua-friendly type name (e.g., "corev1.ResourceList")
+	Definition string // The aliased Lua type (e.g., "table<string, resource.Quantity>")
 }
 
 // TypeRegistry: manages type registration and stub generation for Lua.
 // It processes Go types recursively and generates Lua LSP annotations.
 type TypeRegistry struct {
-	types map[string]*TypeInfo // Map of type key to type information (prevents duplicates)
-	queue []interface{}        // Queue of objects to process (for discovering types)
+	types         map[string]*TypeInfo     // Map of type key to type information (prevents duplicates)
+	queue         []interface{}            // Queue of objects to process (for discovering types)
+	globals       []*GlobalInfo            // Globals injected into scripts, in registration order
+	enums         map[string][]interface{} // Map of type key to the allowed constant values
+	constants     map[string][]interface{} // Map of type key to the constants discovered in its package
+	aliases       map[string]*AliasInfo    // Map of type key to the enum and named map types in use
+	discoverEnums bool                     // Whether constants are discovered from package sources
+	defaults      map[string]interface{}   // Map of type key to the default value of its constructor
 }
 
 // NewTypeRegistry: creates a new TypeRegistry instance
 func NewTypeRegistry() *TypeRegistry {
 	return &TypeRegistry{
-		types: make(map[string]*TypeInfo),
-		queue: make([]interface{}, 0),
+		types:     make(map[string]*TypeInfo),
+		queue:     make([]interface{}, 0),
+		enums:     make(map[string][]interface{}),
+		constants: make(map[string][]interface{}),
+		aliases:   make(map[string]*AliasInfo),
+		defaults:  make(map[string]interface{}),
 	}
 }
 
@@ -75,6 +104,136 @@ func (r *TypeRegistry) Register(obj interface{}) error {
 	return nil
 }
 
+// RegisterGlobal: declares a global variable that the host injects into Lua scripts.
+// The type of obj is registered like Register does, and GenerateStubs emits a
+// ---@type declaration so LuaLS knows the type of the global.
+// Returns an error if the name is not a valid Lua identifier or is already registered.
+//
+// Example:
+//
+//	registry.RegisterGlobal("pod", &corev1.Pod{})
+//
+// generates:
+//
+//	---@type corev1.Pod
+//	pod = nil
+func (r *TypeRegistry) RegisterGlobal(name string, obj interface{}) error {
+	if !IsLuaIdentifier(name) {
+		return fmt.Errorf("invalid Lua global name %q", name)
+	}
+
+	for _, global := range r.globals {
+		if global.Name == name {
+			return fmt.Errorf("global %q is already registered", name)
+		}
+	}
+
+	if err := r.Register(obj); err != nil {
+		return fmt.Errorf("cannot register global %q: %w", name, err)
+	}
+
+	r.globals = append(r.globals, &GlobalInfo{
+		Name:   name,
+		GoType: reflect.TypeOf(obj),
+	})
+	return nil
+}
+
+// RegisterEnum: declares the set of constant values allowed for a named Go type.
+// obj is any value of the type (typically one of the constants) and values are the
+// allowed constants, which must be convertible to that type.
+// Calling RegisterEnum again for the same type appends to the existing values.
+// Unlike the constants discovered in package sources, which only document the
+// well-known values in stubs, these values are enforced by Validate and GenerateJSONSchema.
+// Call it before Process() so field annotations reference the alias.
+//
+// Example:
+//
+//	registry.RegisterEnum(corev1.PullAlways, corev1.PullAlways, corev1.PullIfNotPresent, corev1.PullNever)
+func (r *TypeRegistry) RegisterEnum(obj interface{}, values ...interface{}) error {
+	if obj == nil {
+		return fmt.Errorf("cannot register enum for nil object")
+	}
+
+	t := r.unwrapPointer(reflect.TypeOf(obj))
+	if t.Name() == "" || t.PkgPath() == "" {
+		return fmt.Errorf("cannot register enum for unnamed type %s", t)
+	}
+
+	if r.getPrimitiveType(t) == "" || t.Kind() == reflect.Interface {
+		return fmt.Errorf("cannot register enum for non-primitive type %s", t)
+	}
+
+	typeKey := r.getTypeKey(t)
+	for _, value := range values {
+		v := reflect.ValueOf(value)
+		if !v.IsValid() || !v.Type().ConvertibleTo(t) || r.getPrimitiveType(v.Type()) != r.getPrimitiveType(t) {
+			return fmt.Errorf("enum value %v is not convertible to %s", value, t)
+		}
+		r.enums[typeKey] = append(r.enums[typeKey], enumValue(v.Convert(t)))
+	}
+
+	// Keep the alias of a type that was already processed in sync
+	if alias, exists := r.aliases[typeKey]; exists {
+		alias.Definition = enumAlias(r.enumValues(typeKey))
+	}
+
+	return nil
+}
+
+// enumValue: normalizes a constant to its underlying string, integer, float or boolean value
+func enumValue(v reflect.Value) interface{} {
+	switch v.Kind() {
+	case reflect.String:
+		return v.String()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return v.Uint()
+	case reflect.Float32, reflect.Float64:
+		return v.Float()
+	case reflect.Bool:
+		return v.Bool()
+	}
+	return v.Interface()
+}
+
+// IsLuaIdentifier: checks whether a string is a valid Lua identifier and not a reserved word.
+// goto is reserved too, as in Lua 5.2+ and Teal.
+func IsLuaIdentifier(name string) bool {
+	if name == "" || luaKeywords[name] {
+		return false
+	}
+
+	for i, ch := range name {
+		isLetter := (ch >= 'a' && ch <= 'z') || (ch >= 'A' && ch <= 'Z') || ch == '_'
+		isDigit := ch >= '0' && ch <= '9'
+		if !isLetter && (i == 0 || !isDigit) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// LuaFieldName: returns a field name as written in Lua and Teal declarations,
+// quoted as ["name"] when it is not a valid identifier (e.g. "x-kubernetes-foo" or "end")
+func LuaFieldName(name string) string {
+	if !IsLuaIdentifier(name) {
+		return fmt.Sprintf("[%q]", name)
+	}
+	return name
+}
+
+// luaKeywords: reserved words that cannot be used as Lua identifiers
+var luaKeywords = map[string]bool{
+	"and": true, "break": true, "do": true, "else": true, "elseif": true,
+	"end": true, "false": true, "for": true, "function": true, "goto": true,
+	"if": true, "in": true, "local": true, "nil": true, "not": true,
+	"or": true, "repeat": true, "return": true, "then": true, "true": true,
+	"until": true, "while": true,
+}
+
 // getTypeName: generates a human-readable type name for a Go type.
 // Handles Kubernetes API objects specially (e.g., corev1.Pod instead of v1.Pod).
 func (r *TypeRegistry) getTypeName(t reflect.Type) string {
@@ -83,30 +242,34 @@ func (r *TypeRegistry) getTypeName(t reflect.Type) string {
 		t = t.Elem()
 	}
 
-	// For primitive types
-	switch t.Kind() {
-	case reflect.String:
-		return "string"
-	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
-		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
-		reflect.Float32, reflect.Float64:
-		return "number"
-	case reflect.Bool:
-		return "boolean"
-	case reflect.Map:
-		return "table"
-	case reflect.Slice, reflect.Array:
-		return "table"
-	}
-
 	// For named types with package path
 	pkgPath := t.PkgPath()
 	typeName := t.Name()
 
+	// For builtin primitive types (named types like corev1.PullPolicy keep their name)
 	if pkgPath == "" {
+		switch t.Kind() {
+		case reflect.String:
+			return "string"
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+			reflect.Float32, reflect.Float64:
+			return "number"
+		case reflect.Bool:
+			return "boolean"
+		case reflect.Map:
+			return "table"
+		case reflect.Slice, reflect.Array:
+			return "table"
+		}
 		return typeName
 	}
 
+	// Instantiated generic types are named like Page[github.com/x/models.Item]
+	if strings.Contains(typeName, "[") {
+		typeName = sanitizeGenericName(typeName)
+	}
+
 	// Extract package name from path
 	parts := strings.Split(pkgPath, "/")
 	pkgName := parts[len(parts)-1]
@@ -154,6 +317,9 @@ func (r *TypeRegistry) processType(t reflect.Type) string {
 	t = r.unwrapPointer(t)
 
 	if primType := r.getPrimitiveType(t); primType != "" {
+		if alias := r.processEnumType(t); alias != "" {
+			return alias
+		}
 		return primType
 	}
 
@@ -166,6 +332,9 @@ func (r *TypeRegistry) processType(t reflect.Type) string {
 	}
 
 	if t.Kind() == reflect.Struct {
+		if t.Name() == "" {
+			return r.processAnonymousStruct(t)
+		}
 		return r.processStructType(t)
 	}
 
@@ -204,11 +373,39 @@ func (r *TypeRegistry) processArrayType(t reflect.Type) string {
 	return elemKey + "[]"
 }
 
-// processMapType: processes map types
+// processMapType: processes map types.
+// Named map types (e.g., corev1.ResourceList) are declared as aliases and referenced by name.
 func (r *TypeRegistry) processMapType(t reflect.Type) string {
-	valueType := t.Elem()
-	valueKey := r.processType(valueType)
-	return "table<string, " + valueKey + ">"
+	if t.Name() == "" || t.PkgPath() == "" {
+		return "table<" + r.processMapKeyType(t.Key()) + ", " + r.processType(t.Elem()) + ">"
+	}
+
+	typeKey := r.getTypeKey(t)
+	if alias, exists := r.aliases[typeKey]; exists {
+		return alias.Name
+	}
+
+	// Reserve the alias before recursing to handle maps referencing themselves
+	alias := &AliasInfo{Name: r.getTypeName(t)}
+	r.aliases[typeKey] = alias
+	alias.Definition = "table<" + r.processMapKeyType(t.Key()) + ", " + r.processType(t.Elem()) + ">"
+
+	return alias.Name
+}
+
+// processMapKeyType: returns the Lua type of map keys.
+// The Translator converts every key to its string form (and Validate only accepts string
+// keys), so keys are strings, or the alias of a string enum.
+func (r *TypeRegistry) processMapKeyType(t reflect.Type) string {
+	t = r.unwrapPointer(t)
+
+	if t.Kind() == reflect.String {
+		if alias := r.processEnumType(t); alias != "" {
+			return alias
+		}
+	}
+
+	return "string"
 }
 
 // processStructType: processes struct types and registers them
@@ -231,8 +428,72 @@ func (r *TypeRegistry) processStructType(t reflect.Type) string {
 	return typeName
 }
 
-// processStructFields: processes all fields in a struct
+// processAnonymousStruct: returns an inline table literal type for an anonymous struct
+// (e.g., { name: string, port: number }), since it has no name to declare a class with
+func (r *TypeRegistry) processAnonymousStruct(t reflect.Type) string {
+	typeInfo := &TypeInfo{GoType: t, Fields: make(map[string]*FieldInfo)}
+	r.processStructFields(t, typeInfo)
+
+	if len(typeInfo.Fields) == 0 {
+		return "table"
+	}
+
+	fields := make([]string, 0, len(typeInfo.Fields))
+	for _, name := range sortedKeys(typeInfo.Fields) {
+		fields = append(fields, LuaFieldName(name)+": "+typeInfo.Fields[name].TypeKey)
+	}
+
+	return "{ " + strings.Join(fields, ", ") + " }"
+}
+
+// genericPackagePath: matches the package path prefixes in the type arguments of generic type names
+var genericPackagePath = regexp.MustCompile(`[\w.\-~]+/`)
+
+// localTypeSuffix: matches the counter the compiler appends to function-local type names
+var localTypeSuffix = regexp.MustCompile(`·\d+`)
+
+// nonIdentifierChars: matches runs of characters that cannot appear in a Lua identifier
+var nonIdentifierChars = regexp.MustCompile(`[^A-Za-z0-9_]+`)
+
+// sanitizeGenericName: turns the name of an instantiated generic type into a stable
+// identifier, keeping only the package name of type arguments
+// (e.g., Page[github.com/x/models.Item] becomes Page_models_Item and
+// Pair[string,[]int] becomes Pair_string_array_int)
+func sanitizeGenericName(name string) string {
+	name = genericPackagePath.ReplaceAllString(name, "")
+	name = localTypeSuffix.ReplaceAllString(name, "")
+	name = strings.ReplaceAll(name, "[]", "array_")
+	name = strings.ReplaceAll(name, "*", "")
+	name = nonIdentifierChars.ReplaceAllString(name, "_")
+	return strings.Trim(name, "_")
+}
+
+// processStructFields: processes all fields in a struct, registering the types they use.
+// Embedded structs without a JSON name (e.g. metav1.TypeMeta with `json:",inline"`)
+// are flattened into the parent, the same way encoding/json serializes them: a field
+// of the parent hides a promoted field with the same name.
 func (r *TypeRegistry) processStructFields(t reflect.Type, typeInfo *TypeInfo) {
+	r.collectStructFields(t, typeInfo, r.processType)
+}
+
+// lookupStructFields: returns the JSON fields of a struct without modifying the registry.
+// Registered structs use their processed fields, other structs (anonymous ones) are
+// inspected on the fly and their fields are left without a Lua type.
+func (r *TypeRegistry) lookupStructFields(t reflect.Type) map[string]*FieldInfo {
+	if typeInfo, exists := r.types[r.getTypeKey(t)]; exists && t.Name() != "" {
+		return typeInfo.Fields
+	}
+
+	typeInfo := &TypeInfo{GoType: t, Fields: make(map[string]*FieldInfo)}
+	r.collectStructFields(t, typeInfo, func(reflect.Type) string { return "" })
+	return typeInfo.Fields
+}
+
+// collectStructFields: adds the JSON fields of a struct to typeInfo, using luaType
+// to resolve the Lua type of each field
+func (r *TypeRegistry) collectStructFields(t reflect.Type, typeInfo *TypeInfo, luaType func(reflect.Type) string) {
+	var embedded []reflect.Type
+
 	for i := 0; i < t.NumField(); i++ {
 		field := t.Field(i)
 		if !field.IsExported() {
@@ -240,19 +501,53 @@ func (r *TypeRegistry) processStructFields(t reflect.Type, typeInfo *TypeInfo) {
 		}
 
 		jsonTag := field.Tag.Get("json")
-		if jsonTag == "" || jsonTag == "-" {
+		if jsonTag == "-" {
+			continue
+		}
+
+		tagParts := strings.Split(jsonTag, ",")
+		fieldName := tagParts[0]
+
+		if field.Anonymous && fieldName == "" {
+			if embeddedType := r.unwrapPointer(field.Type); embeddedType.Kind() == reflect.Struct {
+				embedded = append(embedded, embeddedType)
+				continue
+			}
+		}
+
+		if jsonTag == "" {
 			continue
 		}
 
-		fieldName := strings.Split(jsonTag, ",")[0]
 		if fieldName == "" {
 			fieldName = field.Name
 		}
 
-		fieldTypeKey := r.processType(field.Type)
+		optional := field.Type.Kind() == reflect.Ptr
+		for _, opt := range tagParts[1:] {
+			if opt == "omitempty" || opt == "omitzero" {
+				optional = true
+			}
+		}
+
+		fieldTypeKey := luaType(field.Type)
 		typeInfo.Fields[fieldName] = &FieldInfo{
-			Name:    fieldName,
-			TypeKey: fieldTypeKey,
+			Name:     fieldName,
+			TypeKey:  fieldTypeKey,
+			GoType:   field.Type,
+			Optional: optional,
+		}
+	}
+
+	// Fields of the struct itself take precedence over the promoted ones
+	for _, embeddedType := range embedded {
+		promoted := &TypeInfo{Fields: make(map[string]*FieldInfo)}
+		r.collectStructFields(embeddedType, promoted, luaType)
+
+		for name, field := range promoted.Fields {
+			if _, ok := typeInfo.Fields[name]; !ok {
+				typeInfo.Fields[name] = field
+			}
 		}
 	}
 }
@@ -268,55 +563,181 @@ func (r *TypeRegistry) Process() error {
 		r.processType(t)
 	}
 
+	// Resolve the Lua type of each injected global
+	for _, global := range r.globals {
+		global.TypeKey = r.processType(global.GoType)
+	}
+
 	return nil
 }
 
 // GenerateStubs: generates Lua annotation stubs for all registered types.
 // Returns a string containing ---@class and ---@field annotations.
 //
+// Named primitive types with constants (from RegisterEnum or discovered in the package
+// source) and named map types are declared as ---@alias before the classes. Globals registered with
+// RegisterGlobal are declared after the classes.
+//
 // Example output:
 //
+//	---@alias corev1.PullPolicy "Always"|"IfNotPresent"|"Never"
+//	---@alias corev1.ResourceList table<string, resource.Quantity>
+//
 //	---@class corev1.Pod
 //	---@field metadata corev1.ObjectMeta
 //	---@field spec corev1.PodSpec
+//
+//	---@type corev1.Pod
+//	pod = nil
 func (r *TypeRegistry) GenerateStubs() (string, error) {
 	var sb strings.Builder
 
-	// Sort type names for consistent output
-	var typeKeys []string
-	for key := range r.types {
-		typeKeys = append(typeKeys, key)
+	// Generate enum and named map aliases
+	r.writeAliases(&sb, r.sortedAliases())
+
+	// Generate class definitions, sorted by type key for consistent output
+	for _, key := range sortedKeys(r.types) {
+		r.writeClass(&sb, r.types[key])
 	}
-	sort.Strings(typeKeys)
 
-	// Generate class definitions
-	for _, key := range typeKeys {
-		typeInfo := r.types[key]
+	// Generate global declarations
+	r.writeGlobals(&sb)
+
+	sb.WriteString("return {}\n")
 
-		// Skip types with no fields (likely incomplete type processing)
+	return sb.String(), nil
+}
+
+// packageStubSuffix: the suffix of the files written by GenerateStubsByPackage
+const packageStubSuffix = ".types.gen.lua"
+
+// GenerateStubsByPackage: generates the same annotations as GenerateStubs, split into
+// one ---@meta file per Go package so LuaLS does not have to load a single huge file.
+// Returns a map of file name (e.g., "corev1.types.gen.lua") to file content. Globals
+// registered with RegisterGlobal are declared in "globals.types.gen.lua".
+// The ".types" part keeps the files apart from module stubs written to the same
+// directory, like kubernetes.gen.lua and the types of a Go package named kubernetes.
+func (r *TypeRegistry) GenerateStubsByPackage() (map[string]string, error) {
+	aliases := make(map[string][]*AliasInfo)
+	for _, alias := range r.sortedAliases() {
+		pkg := stubPackage(alias.Name)
+		aliases[pkg] = append(aliases[pkg], alias)
+	}
+
+	classes := make(map[string][]*TypeInfo)
+	for _, key := range sortedKeys(r.types) {
+		typeInfo := r.types[key]
 		if len(typeInfo.Fields) == 0 {
 			continue
 		}
+		pkg := stubPackage(typeInfo.Name)
+		classes[pkg] = append(classes[pkg], typeInfo)
+	}
 
-		sb.WriteString(fmt.Sprintf("---@class %s\n", typeInfo.Name))
+	packages := make(map[string]bool)
+	for pkg := range aliases {
+		packages[pkg] = true
+	}
+	for pkg := range classes {
+		packages[pkg] = true
+	}
 
-		// Sort field names for consistent output
-		var fieldNames []string
-		for fieldName := range typeInfo.Fields {
-			fieldNames = append(fieldNames, fieldName)
-		}
-		sort.Strings(fieldNames)
+	files := make(map[string]string)
+	for _, pkg := range sortedKeys(packages) {
+		var sb strings.Builder
+		sb.WriteString(fmt.Sprintf("---@meta %s\n\n", pkg))
 
-		// Generate field annotations
-		for _, fieldName := range fieldNames {
-			field := typeInfo.Fields[fieldName]
-			sb.WriteString(fmt.Sprintf("---@field %s %s\n", field.Name, field.TypeKey))
+		r.writeAliases(&sb, aliases[pkg])
+		for _, typeInfo := range classes[pkg] {
+			r.writeClass(&sb, typeInfo)
 		}
 
+		files[pkg+packageStubSuffix] = strings.TrimSuffix(sb.String(), "\n")
+	}
+
+	if len(r.globals) > 0 {
+		var sb strings.Builder
+		sb.WriteString("---@meta globals\n\n")
+		r.writeGlobals(&sb)
+		files["globals"+packageStubSuffix] = strings.TrimSuffix(sb.String(), "\n")
+	}
+
+	return files, nil
+}
+
+// stubPackage: returns the package prefix a type is written under by GenerateStubsByPackage
+func stubPackage(name string) string {
+	if pkg, _ := splitTypeName(name); pkg != "" {
+		return pkg
+	}
+	return "types"
+}
+
+// sortedAliases: returns the enum and named map aliases in use, sorted by name
+func (r *TypeRegistry) sortedAliases() []*AliasInfo {
+	aliases := make([]*AliasInfo, 0, len(r.aliases))
+	for _, alias := range r.aliases {
+		aliases = append(aliases, alias)
+	}
+	sort.Slice(aliases, func(i, j int) bool { return aliases[i].Name < aliases[j].Name })
+	return aliases
+}
+
+// writeAliases: writes ---@alias declarations followed by a blank line
+func (r *TypeRegistry) writeAliases(sb *strings.Builder, aliases []*AliasInfo) {
+	for _, alias := range aliases {
+		sb.WriteString(fmt.Sprintf("---@alias %s %s\n", alias.Name, alias.Definition))
+	}
+	if len(aliases) > 0 {
 		sb.WriteString("\n")
 	}
+}
 
-	sb.WriteString("return {}\n")
+// writeClass: writes the ---@class and ---@field annotations of a type followed by a blank line
+func (r *TypeRegistry) writeClass(sb *strings.Builder, typeInfo *TypeInfo) {
+	// Skip types with no fields (likely incomplete type processing)
+	if len(typeInfo.Fields) == 0 {
+		return
+	}
 
-	return sb.String(), nil
+	for _, line := range descriptionLines(typeInfo.Description) {
+		sb.WriteString(fmt.Sprintf("--- %s\n", line))
+	}
+	sb.WriteString(fmt.Sprintf("---@class %s\n", typeInfo.Name))
+
+	// Sort field names for consistent output
+	for _, fieldName := range sortedKeys(typeInfo.Fields) {
+		field := typeInfo.Fields[fieldName]
+
+		// Names like "x-kubernetes-foo" in CRDs or JSON tags are quoted
+		name := LuaFieldName(field.Name)
+
+		if field.Description != "" {
+			description := strings.Join(descriptionLines(field.Description), " ")
+			sb.WriteString(fmt.Sprintf("---@field %s %s %s\n", name, field.TypeKey, description))
+			continue
+		}
+		sb.WriteString(fmt.Sprintf("---@field %s %s\n", name, field.TypeKey))
+	}
+
+	sb.WriteString("\n")
+}
+
+// writeGlobals: writes the ---@type declarations of the registered globals
+func (r *TypeRegistry) writeGlobals(sb *strings.Builder) {
+	for _, global := range r.globals {
+		sb.WriteString(fmt.Sprintf("---@type %s\n", global.TypeKey))
+		sb.WriteString(fmt.Sprintf("%s = nil\n\n", global.Name))
+	}
+}
+
+// descriptionLines: splits a description into trimmed, non-empty lines
+func descriptionLines(description string) []string {
+	lines := make([]string, 0)
+	for _, line := range strings.Split(description, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines
 }
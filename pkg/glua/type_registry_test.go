@@ -23,6 +23,8 @@ package glua
 import (
 	"strings"
 	"testing"
+
+	corev1 "k8s.io/api/core/v1"
 )
 
 func TestTypeRegistry_SimpleStruct(t *testing.T) {
@@ -359,3 +361,446 @@ func TestTypeRegistry_Pointers(t *testing.T) {
 		t.Errorf("Expected stub to contain '---@field innerPtr glua.Inner', got:\n%s", stubs)
 	}
 }
+
+func TestTypeRegistry_RegisterGlobal(t *testing.T) {
+	type Container struct {
+		Name string `json:"name"`
+	}
+
+	type Pod struct {
+		Name       string      `json:"name"`
+		Containers []Container `json:"containers"`
+	}
+
+	registry := NewTypeRegistry()
+	if err := registry.RegisterGlobal("pod", &Pod{}); err != nil {
+		t.Fatalf("RegisterGlobal failed: %v", err)
+	}
+	if err := registry.RegisterGlobal("containers", []Container{}); err != nil {
+		t.Fatalf("RegisterGlobal failed: %v", err)
+	}
+	if err := registry.RegisterGlobal("threshold", 0); err != nil {
+		t.Fatalf("RegisterGlobal failed: %v", err)
+	}
+
+	if err := registry.Process(); err != nil {
+		t.Fatalf("Process failed: %v", err)
+	}
+
+	stubs, err := registry.GenerateStubs()
+	if err != nil {
+		t.Fatalf("GenerateStubs failed: %v", err)
+	}
+
+	tests := []string{
+		"---@class glua.Pod",
+		"---@type glua.Pod\npod = nil\n",
+		"---@type glua.Container[]\ncontainers = nil\n",
+		"---@type number\nthreshold = nil\n",
+	}
+
+	for _, expected := range tests {
+		if !strings.Contains(stubs, expected) {
+			t.Errorf("Expected stub to contain %q, got:\n%s", expected, stubs)
+		}
+	}
+
+	// Globals are declared after the classes they reference
+	if strings.Index(stubs, "pod = nil") < strings.Index(stubs, "---@class glua.Pod") {
+		t.Errorf("Expected globals after class definitions, got:\n%s", stubs)
+	}
+
+	if !strings.HasSuffix(stubs, "return {}\n") {
+		t.Errorf("Expected stub to end with 'return {}', got:\n%s", stubs)
+	}
+}
+
+func TestTypeRegistry_RegisterGlobalErrors(t *testing.T) {
+	registry := NewTypeRegistry()
+
+	if err := registry.RegisterGlobal("pod", struct{}{}); err != nil {
+		t.Fatalf("RegisterGlobal failed: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		global string
+		obj    interface{}
+	}{
+		{"duplicate name", "pod", struct{}{}},
+		{"empty name", "", struct{}{}},
+		{"invalid identifier", "my-pod", struct{}{}},
+		{"leading digit", "1pod", struct{}{}},
+		{"reserved keyword", "end", struct{}{}},
+		{"nil object", "node", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := registry.RegisterGlobal(tt.global, tt.obj); err == nil {
+				t.Errorf("Expected error registering global %q, got nil", tt.global)
+			}
+		})
+	}
+}
+
+func TestTypeRegistry_InlineEmbeddedStructs(t *testing.T) {
+	type TypeMeta struct {
+		Kind       string `json:"kind,omitempty"`
+		APIVersion string `json:"apiVersion,omitempty"`
+	}
+
+	type Resource struct {
+		TypeMeta `json:",inline"`
+		Name     string `json:"name"`
+	}
+
+	registry := NewTypeRegistry()
+	if err := registry.Register(Resource{}); err != nil {
+		t.Fatalf("Register failed: %v", err)
+	}
+
+	if err := registry.Process(); err != nil {
+		t.Fatalf("Process failed: %v", err)
+	}
+
+	stubs, err := registry.GenerateStubs()
+	if err != nil {
+		t.Fatalf("GenerateStubs failed: %v", err)
+	}
+
+	expected := "---@class glua.Resource\n---@field apiVersion string\n---@field kind string\n---@field name string\n"
+	if !strings.Contains(stubs, expected) {
+		t.Errorf("Expected stub to contain %q, got:\n%s", expected, stubs)
+	}
+
+	if strings.Contains(stubs, "TypeMeta") {
+		t.Errorf("Did not expect embedded struct to appear as a field, got:\n%s", stubs)
+	}
+}
+
+func TestTypeRegistry_FlattenEmbeddedStructs(t *testing.T) {
+	type Base struct {
+		ID   string `json:"id"`
+		Name string `json:"name"`
+	}
+
+	type Labels struct {
+		Labels map[string]string `json:"labels,omitempty"`
+	}
+
+	type Resource struct {
+		Base            // Untagged, flattened
+		*Labels         // Embedded pointer, flattened
+		Meta    Base    `json:"meta"` // Named, kept as a field
+		Name    float64 `json:"name"` // Hides Base.Name
+	}
+
+	registry := NewTypeRegistry()
+	if err := registry.Register(Resource{}); err != nil {
+		t.Fatalf("Register failed: %v", err)
+	}
+	if err := registry.Register(&corev1.Pod{}); err != nil {
+		t.Fatalf("Register failed: %v", err)
+	}
+	if err := registry.Process(); err != nil {
+		t.Fatalf("Process failed: %v", err)
+	}
+
+	stubs, err := registry.GenerateStubs()
+	if err != nil {
+		t.Fatalf("GenerateStubs failed: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		expected string
+	}{
+		{"promoted and parent fields", "---@class glua.Resource\n---@field id string\n---@field labels table<string, string>\n---@field meta glua.Base\n---@field name number\n"},
+		{"metav1.TypeMeta is flattened into corev1.Pod", "---@class corev1.Pod\n---@field apiVersion string\n---@field kind string\n---@field metadata v1.ObjectMeta\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if !strings.Contains(stubs, tt.expected) {
+				t.Errorf("Expected stub to contain:\n%s\ngot:\n%s", tt.expected, stubs)
+			}
+		})
+	}
+
+	for _, unexpected := range []string{"---@field Base ", "---@field Labels ", "---@field TypeMeta "} {
+		if strings.Contains(stubs, unexpected) {
+			t.Errorf("Did not expect %q in stubs", unexpected)
+		}
+	}
+}
+
+// Page: generic type used by the generics tests
+type Page[T any] struct {
+	Items []T  `json:"items"`
+	Next  *int `json:"next,omitempty"`
+}
+
+// Pair: generic type with two type parameters used by the generics tests
+type Pair[K comparable, V any] struct {
+	Key   K `json:"key"`
+	Value V `json:"value"`
+}
+
+func TestTypeRegistry_GenericTypes(t *testing.T) {
+	type Item struct {
+		ID string `json:"id"`
+	}
+
+	type Catalog struct {
+		Items   Page[Item]            `json:"items"`
+		Names   Page[string]          `json:"names"`
+		Entries []Pair[string, []int] `json:"entries"`
+	}
+
+	registry := NewTypeRegistry()
+	if err := registry.Register(&Catalog{}); err != nil {
+		t.Fatalf("Register failed: %v", err)
+	}
+
+	if err := registry.Process(); err != nil {
+		t.Fatalf("Process failed: %v", err)
+	}
+
+	stubs, err := registry.GenerateStubs()
+	if err != nil {
+		t.Fatalf("GenerateStubs failed: %v", err)
+	}
+
+	expectedStrings := []string{
+		"---@class glua.Page_glua_Item\n---@field items glua.Item[]\n---@field next number\n",
+		"---@class glua.Page_string\n---@field items string[]\n",
+		"---@class glua.Pair_string_array_int\n---@field key string\n---@field value number[]\n",
+		"---@field items glua.Page_glua_Item\n",
+		"---@field names glua.Page_string\n",
+		"---@field entries glua.Pair_string_array_int[]\n",
+	}
+
+	for _, expected := range expectedStrings {
+		if !strings.Contains(stubs, expected) {
+			t.Errorf("Expected stubs to contain %q, got:\n%s", expected, stubs)
+		}
+	}
+
+	if strings.Contains(stubs, "github.com") {
+		t.Errorf("Expected package paths to be stripped from generic names, got:\n%s", stubs)
+	}
+}
+
+func TestTypeRegistry_AnonymousStructs(t *testing.T) {
+	type Target struct {
+		Host string `json:"host"`
+	}
+
+	type Config struct {
+		Server struct {
+			Host string `json:"host"`
+			Port int    `json:"port,omitempty"`
+		} `json:"server"`
+		Backends []struct {
+			Target Target `json:"target"`
+			Weight int    `json:"weight"`
+		} `json:"backends"`
+		Labels struct {
+			Name string `json:"app.kubernetes.io/name"`
+		} `json:"labels"`
+		Empty struct{} `json:"empty"`
+	}
+
+	registry := NewTypeRegistry()
+	if err := registry.Register(&Config{}); err != nil {
+		t.Fatalf("Register failed: %v", err)
+	}
+
+	if err := registry.Process(); err != nil {
+		t.Fatalf("Process failed: %v", err)
+	}
+
+	stubs, err := registry.GenerateStubs()
+	if err != nil {
+		t.Fatalf("GenerateStubs failed: %v", err)
+	}
+
+	expectedStrings := []string{
+		"---@field server { host: string, port: number }\n",
+		"---@field backends { target: glua.Target, weight: number }[]\n",
+		"---@field labels { [\"app.kubernetes.io/name\"]: string }\n",
+		"---@field empty table\n",
+		"---@class glua.Target\n---@field host string\n",
+	}
+
+	for _, expected := range expectedStrings {
+		if !strings.Contains(stubs, expected) {
+			t.Errorf("Expected stubs to contain %q, got:\n%s", expected, stubs)
+		}
+	}
+
+	if strings.Contains(stubs, "---@class \n") || strings.Contains(stubs, "---@class glua.\n") {
+		t.Errorf("Did not expect a class for anonymous structs, got:\n%s", stubs)
+	}
+}
+
+func TestSanitizeGenericName(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected string
+	}{
+		{"Page[string]", "Page_string"},
+		{"Page[github.com/acme/models.Item]", "Page_models_Item"},
+		{"Pair[string,[]int]", "Pair_string_array_int"},
+		{"Cache[map[string]*k8s.io/api/core/v1.Pod]", "Cache_map_string_v1_Pod"},
+		{"Box[github.com/acme/models.Wrapper[github.com/acme/other.Item]]", "Box_models_Wrapper_other_Item"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if result := sanitizeGenericName(tt.name); result != tt.expected {
+				t.Errorf("sanitizeGenericName(%q) = %q, want %q", tt.name, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestTypeRegistry_TypedMapKeys(t *testing.T) {
+	type Port struct {
+		Name string `json:"name"`
+	}
+
+	type Tags map[string]string
+
+	type Tree map[string]Tree
+
+	type Config struct {
+		Ports    map[int32]Port   `json:"ports"`
+		Weights  map[uint]float64 `json:"weights"`
+		Modes    map[Mode]bool    `json:"modes"`
+		Tags     Tags             `json:"tags"`
+		AllTags  []Tags           `json:"allTags"`
+		Children Tree             `json:"children"`
+	}
+
+	registry := NewTypeRegistry()
+	if err := registry.RegisterEnum(ModeFast, ModeFast, ModeSafe); err != nil {
+		t.Fatalf("RegisterEnum failed: %v", err)
+	}
+	if err := registry.Register(&Config{}); err != nil {
+		t.Fatalf("Register failed: %v", err)
+	}
+	if err := registry.Process(); err != nil {
+		t.Fatalf("Process failed: %v", err)
+	}
+
+	stubs, err := registry.GenerateStubs()
+	if err != nil {
+		t.Fatalf("GenerateStubs failed: %v", err)
+	}
+
+	expectedStrings := []string{
+		"---@alias glua.Mode \"fast\"|\"safe\"\n---@alias glua.Tags table<string, string>\n---@alias glua.Tree table<string, glua.Tree>\n",
+		"---@field ports table<string, glua.Port>\n",
+		"---@field weights table<string, number>\n",
+		"---@field modes table<glua.Mode, boolean>\n",
+		"---@field tags glua.Tags\n",
+		"---@field allTags glua.Tags[]\n",
+		"---@field children glua.Tree\n",
+	}
+
+	for _, expected := range expectedStrings {
+		if !strings.Contains(stubs, expected) {
+			t.Errorf("Expected stubs to contain %q, got:\n%s", expected, stubs)
+		}
+	}
+}
+
+func TestTypeRegistry_KubernetesResourceList(t *testing.T) {
+	registry := NewTypeRegistry()
+	if err := registry.Register(&corev1.ResourceRequirements{}); err != nil {
+		t.Fatalf("Register failed: %v", err)
+	}
+	if err := registry.Process(); err != nil {
+		t.Fatalf("Process failed: %v", err)
+	}
+
+	stubs, err := registry.GenerateStubs()
+	if err != nil {
+		t.Fatalf("GenerateStubs failed: %v", err)
+	}
+
+	expectedStrings := []string{
+		"---@alias corev1.ResourceList table<string, resource.Quantity>\n",
+		"---@field limits corev1.ResourceList\n",
+		"---@field requests corev1.ResourceList\n",
+	}
+
+	for _, expected := range expectedStrings {
+		if !strings.Contains(stubs, expected) {
+			t.Errorf("Expected stubs to contain %q, got:\n%s", expected, stubs)
+		}
+	}
+}
+
+func TestTypeRegistry_GenerateStubsByPackage(t *testing.T) {
+	registry := NewTypeRegistry()
+	if err := registry.RegisterGlobal("pod", &corev1.Pod{}); err != nil {
+		t.Fatalf("RegisterGlobal failed: %v", err)
+	}
+	if err := registry.Process(); err != nil {
+		t.Fatalf("Process failed: %v", err)
+	}
+
+	files, err := registry.GenerateStubsByPackage()
+	if err != nil {
+		t.Fatalf("GenerateStubsByPackage failed: %v", err)
+	}
+
+	for _, name := range []string{"corev1.types.gen.lua", "v1.types.gen.lua", "globals.types.gen.lua"} {
+		if _, ok := files[name]; !ok {
+			t.Errorf("Expected file %s, got files %v", name, sortedKeys(files))
+		}
+	}
+
+	// Files cannot be mistaken for module stubs such as kubernetes.gen.lua
+	for name := range files {
+		if !strings.HasSuffix(name, ".types.gen.lua") {
+			t.Errorf("Expected %s to end with .types.gen.lua", name)
+		}
+	}
+
+	corev1Stubs := files["corev1.types.gen.lua"]
+	if !strings.HasPrefix(corev1Stubs, "---@meta corev1\n\n---@alias corev1.") {
+		t.Errorf("Expected corev1 file to start with its ---@meta header and aliases, got:\n%.200s", corev1Stubs)
+	}
+	if !strings.Contains(corev1Stubs, "---@class corev1.Pod\n") {
+		t.Error("Expected corev1 file to declare corev1.Pod")
+	}
+	if strings.Contains(corev1Stubs, "---@class v1.ObjectMeta") || strings.Contains(corev1Stubs, "return {}") {
+		t.Error("Expected corev1 file to only contain corev1 declarations")
+	}
+
+	if !strings.Contains(files["v1.types.gen.lua"], "---@class v1.ObjectMeta\n") {
+		t.Error("Expected v1 file to declare v1.ObjectMeta")
+	}
+
+	if files["globals.types.gen.lua"] != "---@meta globals\n\n---@type corev1.Pod\npod = nil\n" {
+		t.Errorf("Unexpected globals file:\n%s", files["globals.types.gen.lua"])
+	}
+
+	// Every class of the combined output is declared in exactly one file
+	stubs, err := registry.GenerateStubs()
+	if err != nil {
+		t.Fatalf("GenerateStubs failed: %v", err)
+	}
+
+	total := 0
+	for _, content := range files {
+		total += strings.Count(content, "---@class ")
+	}
+	if expected := strings.Count(stubs, "---@class "); total != expected {
+		t.Errorf("Expected %d classes across files, got %d", expected, total)
+	}
+}
@@ -0,0 +1,410 @@
+// Copyright (c) 2024-2025 Thomas Maurice
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package glua
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strings"
+
+	lua "github.com/yuin/gopher-lua"
+)
+
+// ValidationError: describes a single mismatch between a Lua value and a Go type
+type ValidationError struct {
+	Path    string // The path of the offending value (e.g., "spec.containers[1].image")
+	Message string // What is wrong with the value
+}
+
+// Error: formats the error as "path: message"
+func (e *ValidationError) Error() string {
+	if e.Path == "" {
+		return e.Message
+	}
+	return e.Path + ": " + e.Message
+}
+
+// ValidationErrors: all mismatches found while validating a Lua value
+type ValidationErrors []*ValidationError
+
+// Error: joins all validation errors with "; "
+func (e ValidationErrors) Error() string {
+	messages := make([]string, len(e))
+	for i, err := range e {
+		messages[i] = err.Error()
+	}
+	return strings.Join(messages, "; ")
+}
+
+// TypeByName: returns the Go type registered under a Lua type name (e.g., "corev1.Pod").
+// Only struct types discovered by Process() can be looked up. Returns an error if no
+// type has this name, or if types of different packages share it (two packages both
+// imported as v1, for instance).
+func (r *TypeRegistry) TypeByName(name string) (reflect.Type, error) {
+	var keys []string
+	for key, typeInfo := range r.types {
+		if typeInfo.Name == name && typeInfo.GoType != nil {
+			keys = append(keys, key)
+		}
+	}
+
+	switch len(keys) {
+	case 0:
+		return nil, fmt.Errorf("unknown type %q", name)
+	case 1:
+		return r.types[keys[0]].GoType, nil
+	default:
+		sort.Strings(keys)
+		return nil, fmt.Errorf("ambiguous type %q, registered for %s", name, strings.Join(keys, ", "))
+	}
+}
+
+// Validate: checks that a Lua value matches the shape of a registered Go type.
+// It reports wrongly typed values, fields that do not exist on the Go struct and
+// required fields (without omitempty) that are missing. Values declared with
+// RegisterEnum must be one of the registered constants.
+// Returns nil if the value is valid, ValidationErrors listing every problem with its
+// path otherwise, or a plain error if the type is not registered.
+// Array indices in paths are 1-based, as in Lua. L is the state lv belongs to, as in FromLua.
+//
+// Example:
+//
+//	if err := registry.Validate(L, L.GetGlobal("pod"), &corev1.Pod{}); err != nil {
+//	    // err: "spec.containers[1].name: missing required field"
+//	}
+func (r *TypeRegistry) Validate(L *lua.LState, lv lua.LValue, obj interface{}) error {
+	if obj == nil {
+		return fmt.Errorf("cannot validate against nil object")
+	}
+
+	t := r.unwrapPointer(reflect.TypeOf(obj))
+	if _, exists := r.types[r.getTypeKey(t)]; t.Kind() != reflect.Struct || !exists {
+		return fmt.Errorf("type %s is not registered, call Register() and Process() first", t)
+	}
+
+	return r.validate(lv, t, true)
+}
+
+// validation: state of a validation walk
+type validation struct {
+	errs          ValidationErrors // Problems found so far
+	requireFields bool             // Whether missing required fields are reported
+}
+
+// validate: walks a Lua value against a Go type, optionally ignoring missing required fields
+func (r *TypeRegistry) validate(lv lua.LValue, t reflect.Type, requireFields bool) error {
+	v := &validation{requireFields: requireFields}
+	r.validateValue(lv, t, "", v)
+	if len(v.errs) > 0 {
+		return v.errs
+	}
+
+	return nil
+}
+
+// validateValue: checks a Lua value against a Go type, appending mismatches to v.errs
+func (r *TypeRegistry) validateValue(lv lua.LValue, t reflect.Type, path string, v *validation) {
+	t = r.unwrapPointer(t)
+
+	// null is accepted everywhere, missing required fields are checked by validateStruct
+	if lv == lua.LNil {
+		return
+	}
+
+	fail := func(format string, args ...interface{}) {
+		v.errs = append(v.errs, &ValidationError{Path: path, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if schema := r.customSchema(t); schema != nil {
+		if !matchesSchemaType(lv, schema["type"]) {
+			fail("expected %s, got %s", schemaTypeName(schema["type"]), lv.Type())
+		}
+		return
+	}
+
+	switch t.Kind() {
+	case reflect.Interface:
+		return
+	case reflect.String:
+		if _, ok := lv.(lua.LString); !ok {
+			fail("expected string, got %s", lv.Type())
+			return
+		}
+	case reflect.Bool:
+		if _, ok := lv.(lua.LBool); !ok {
+			fail("expected boolean, got %s", lv.Type())
+			return
+		}
+	case reflect.Float32, reflect.Float64:
+		if _, ok := lv.(lua.LNumber); !ok {
+			fail("expected number, got %s", lv.Type())
+			return
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if !isLuaInteger(lv) {
+			fail("expected integer, got %s", describeLuaValue(lv))
+			return
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if !isLuaInteger(lv) || lv.(lua.LNumber) < 0 {
+			fail("expected non-negative integer, got %s", describeLuaValue(lv))
+			return
+		}
+	case reflect.Slice, reflect.Array:
+		// encoding/json encodes byte slices as base64 strings
+		if t.Elem().Kind() == reflect.Uint8 {
+			if _, ok := lv.(lua.LString); !ok {
+				fail("expected base64 string, got %s", lv.Type())
+			}
+			return
+		}
+		r.validateArray(lv, t, path, v)
+		return
+	case reflect.Map:
+		r.validateMap(lv, t, path, v)
+		return
+	case reflect.Struct:
+		r.validateStruct(lv, t, path, v)
+		return
+	default:
+		return
+	}
+
+	if values, ok := r.enums[r.getTypeKey(t)]; ok && t.Name() != "" {
+		r.validateEnum(lv, t, values, path, v)
+	}
+}
+
+// validateEnum: checks that a primitive Lua value is one of the registered constants
+func (r *TypeRegistry) validateEnum(lv lua.LValue, t reflect.Type, values []interface{}, path string, v *validation) {
+	var actual interface{}
+	switch v := lv.(type) {
+	case lua.LString:
+		actual = string(v)
+	case lua.LBool:
+		actual = bool(v)
+	case lua.LNumber:
+		actual = float64(v)
+	}
+
+	allowed := make([]string, len(values))
+	for i, value := range values {
+		if fmt.Sprint(value) == fmt.Sprint(actual) {
+			return
+		}
+		allowed[i] = fmt.Sprintf("%q", fmt.Sprint(value))
+	}
+
+	v.errs = append(v.errs, &ValidationError{
+		Path:    path,
+		Message: fmt.Sprintf("invalid %s %q, expected one of %s", r.getTypeName(t), fmt.Sprint(actual), strings.Join(allowed, ", ")),
+	})
+}
+
+// validateArray: checks that a Lua table is a sequence and validates each element
+func (r *TypeRegistry) validateArray(lv lua.LValue, t reflect.Type, path string, v *validation) {
+	tbl, ok := lv.(*lua.LTable)
+	if !ok {
+		v.errs = append(v.errs, &ValidationError{Path: path, Message: fmt.Sprintf("expected array, got %s", lv.Type())})
+		return
+	}
+
+	// A sequence has exactly the keys 1..n
+	count, length := 0, 0
+	isSequence := true
+	tbl.ForEach(func(key, _ lua.LValue) {
+		n, ok := key.(lua.LNumber)
+		if !ok || !isLuaInteger(n) || n < 1 {
+			isSequence = false
+			return
+		}
+		count++
+		if int(n) > length {
+			length = int(n)
+		}
+	})
+	if !isSequence || count != length {
+		v.errs = append(v.errs, &ValidationError{Path: path, Message: "expected array, got table with non-sequential keys"})
+		return
+	}
+
+	for i := 1; i <= length; i++ {
+		r.validateValue(tbl.RawGetInt(i), t.Elem(), fmt.Sprintf("%s[%d]", path, i), v)
+	}
+}
+
+// validateMap: checks that a Lua table has string keys and validates each value
+func (r *TypeRegistry) validateMap(lv lua.LValue, t reflect.Type, path string, v *validation) {
+	tbl, ok := lv.(*lua.LTable)
+	if !ok {
+		v.errs = append(v.errs, &ValidationError{Path: path, Message: fmt.Sprintf("expected table, got %s", lv.Type())})
+		return
+	}
+
+	keys, invalid := sortedStringKeys(tbl)
+	for _, key := range invalid {
+		v.errs = append(v.errs, &ValidationError{Path: path, Message: fmt.Sprintf("expected string key, got %s", describeLuaValue(key))})
+	}
+
+	for _, key := range keys {
+		r.validateValue(tbl.RawGetString(key), t.Elem(), joinValidationPath(path, key), v)
+	}
+}
+
+// validateStruct: checks the fields of a Lua table against the JSON fields of a struct
+func (r *TypeRegistry) validateStruct(lv lua.LValue, t reflect.Type, path string, v *validation) {
+	tbl, ok := lv.(*lua.LTable)
+	if !ok {
+		v.errs = append(v.errs, &ValidationError{Path: path, Message: fmt.Sprintf("expected %s, got %s", r.structName(t), lv.Type())})
+		return
+	}
+
+	fields := r.lookupStructFields(t)
+
+	keys, invalid := sortedStringKeys(tbl)
+	for _, key := range invalid {
+		v.errs = append(v.errs, &ValidationError{Path: path, Message: fmt.Sprintf("unexpected key %s", describeLuaValue(key))})
+	}
+
+	for _, key := range keys {
+		field, exists := fields[key]
+		if !exists {
+			v.errs = append(v.errs, &ValidationError{Path: joinValidationPath(path, key), Message: fmt.Sprintf("unknown field of %s", r.structName(t))})
+			continue
+		}
+		r.validateValue(tbl.RawGetString(key), field.GoType, joinValidationPath(path, key), v)
+	}
+
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if v.requireFields && !fields[name].Optional && tbl.RawGetString(name) == lua.LNil {
+			v.errs = append(v.errs, &ValidationError{Path: joinValidationPath(path, name), Message: "missing required field"})
+		}
+	}
+}
+
+// structName: returns the Lua name of a struct type, or "object" for anonymous structs
+func (r *TypeRegistry) structName(t reflect.Type) string {
+	if t.Name() == "" {
+		return "object"
+	}
+	return r.getTypeName(t)
+}
+
+// sortedStringKeys: returns the sorted string keys of a table, and the keys that are not strings
+func sortedStringKeys(tbl *lua.LTable) ([]string, []lua.LValue) {
+	keys := make([]string, 0)
+	invalid := make([]lua.LValue, 0)
+
+	tbl.ForEach(func(key, _ lua.LValue) {
+		if str, ok := key.(lua.LString); ok {
+			keys = append(keys, string(str))
+		} else {
+			invalid = append(invalid, key)
+		}
+	})
+	sort.Strings(keys)
+
+	return keys, invalid
+}
+
+// joinValidationPath: appends a field name to a path, quoting names that are not identifiers
+func joinValidationPath(path, name string) string {
+	if !IsLuaIdentifier(name) {
+		return fmt.Sprintf("%s[%q]", path, name)
+	}
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
+
+// isLuaInteger: checks whether a Lua value is a number without fractional part
+func isLuaInteger(lv lua.LValue) bool {
+	n, ok := lv.(lua.LNumber)
+	return ok && float64(n) == math.Trunc(float64(n)) && !math.IsInf(float64(n), 0)
+}
+
+// describeLuaValue: formats a Lua value for error messages, including numbers and strings
+func describeLuaValue(lv lua.LValue) string {
+	switch v := lv.(type) {
+	case lua.LNumber:
+		return "number " + v.String()
+	case lua.LString:
+		return fmt.Sprintf("string %q", string(v))
+	}
+	return lv.Type().String()
+}
+
+// matchesSchemaType: checks a Lua value against the "type" of a custom schema
+func matchesSchemaType(lv lua.LValue, schemaType interface{}) bool {
+	var types []string
+	switch v := schemaType.(type) {
+	case nil:
+		return true
+	case string:
+		types = []string{v}
+	case []string:
+		types = v
+	}
+
+	for _, typ := range types {
+		switch typ {
+		case "string":
+			if _, ok := lv.(lua.LString); ok {
+				return true
+			}
+		case "integer":
+			if isLuaInteger(lv) {
+				return true
+			}
+		case "number":
+			if _, ok := lv.(lua.LNumber); ok {
+				return true
+			}
+		case "boolean":
+			if _, ok := lv.(lua.LBool); ok {
+				return true
+			}
+		case "object", "array":
+			if _, ok := lv.(*lua.LTable); ok {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// schemaTypeName: formats the "type" of a custom schema for error messages
+func schemaTypeName(schemaType interface{}) string {
+	if types, ok := schemaType.([]string); ok {
+		return strings.Join(types, " or ")
+	}
+	return fmt.Sprint(schemaType)
+}
@@ -0,0 +1,265 @@
+// Copyright (c) 2024-2025 Thomas Maurice
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package glua
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	lua "github.com/yuin/gopher-lua"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// evalLua: evaluates a Lua expression and returns its value
+func evalLua(t *testing.T, L *lua.LState, expr string) lua.LValue {
+	t.Helper()
+
+	if err := L.DoString("return " + expr); err != nil {
+		t.Fatalf("Failed to evaluate %q: %v", expr, err)
+	}
+	lv := L.Get(-1)
+	L.Pop(1)
+
+	return lv
+}
+
+func TestTypeRegistry_Validate(t *testing.T) {
+	type Item struct {
+		Name  string            `json:"name"`
+		Count int               `json:"count,omitempty"`
+		Size  uint              `json:"size,omitempty"`
+		Ratio float64           `json:"ratio,omitempty"`
+		Tags  map[string]string `json:"tags,omitempty"`
+	}
+
+	type Config struct {
+		Mode    Mode    `json:"mode"`
+		Enabled bool    `json:"enabled,omitempty"`
+		Items   []Item  `json:"items,omitempty"`
+		Parent  *Config `json:"parent,omitempty"`
+		Extra   any     `json:"extra,omitempty"`
+	}
+
+	registry := NewTypeRegistry()
+	if err := registry.RegisterEnum(ModeFast, ModeFast, ModeSafe); err != nil {
+		t.Fatalf("RegisterEnum failed: %v", err)
+	}
+	if err := registry.Register(&Config{}); err != nil {
+		t.Fatalf("Register failed: %v", err)
+	}
+	if err := registry.Process(); err != nil {
+		t.Fatalf("Process failed: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		value    string
+		expected []string
+	}{
+		{
+			name:  "valid",
+			value: `{mode = "fast", enabled = true, items = {{name = "a", count = 2, tags = {env = "prod"}}}, extra = {1, "x"}}`,
+		},
+		{
+			name:  "valid nested pointer",
+			value: `{mode = "safe", parent = {mode = "fast"}, items = {}}`,
+		},
+		{
+			name:     "missing required field",
+			value:    `{}`,
+			expected: []string{"mode: missing required field"},
+		},
+		{
+			name:     "wrong type",
+			value:    `{mode = "fast", enabled = "yes"}`,
+			expected: []string{"enabled: expected boolean, got string"},
+		},
+		{
+			name:     "unknown field",
+			value:    `{mode = "fast", verbose = true}`,
+			expected: []string{"verbose: unknown field of glua.Config"},
+		},
+		{
+			name:     "invalid enum",
+			value:    `{mode = "slow"}`,
+			expected: []string{`mode: invalid glua.Mode "slow", expected one of "fast", "safe"`},
+		},
+		{
+			name:  "nested errors",
+			value: `{mode = "fast", items = {{name = "a"}, {count = 1.5, size = -1, tags = {env = 1}}}}`,
+			expected: []string{
+				"items[2].count: expected integer, got number 1.5",
+				"items[2].size: expected non-negative integer, got number -1",
+				"items[2].tags.env: expected string, got number",
+				"items[2].name: missing required field",
+			},
+		},
+		{
+			name:     "array with holes",
+			value:    `{mode = "fast", items = {[1] = {name = "a"}, [3] = {name = "b"}}}`,
+			expected: []string{"items: expected array, got table with non-sequential keys"},
+		},
+		{
+			name:     "not a table",
+			value:    `"config"`,
+			expected: []string{"expected glua.Config, got string"},
+		},
+		{
+			name:     "quoted map keys",
+			value:    `{mode = "fast", items = {{name = "a", tags = {["app.kubernetes.io/name"] = true}}}}`,
+			expected: []string{`items[1].tags["app.kubernetes.io/name"]: expected string, got boolean`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			L := lua.NewState()
+			defer L.Close()
+
+			err := registry.Validate(L, evalLua(t, L, tt.value), &Config{})
+			if len(tt.expected) == 0 {
+				if err != nil {
+					t.Fatalf("Expected no error, got: %v", err)
+				}
+				return
+			}
+
+			var errs ValidationErrors
+			if !errors.As(err, &errs) {
+				t.Fatalf("Expected ValidationErrors, got: %v", err)
+			}
+
+			if len(errs) != len(tt.expected) {
+				t.Fatalf("Expected %d errors, got %d: %v", len(tt.expected), len(errs), err)
+			}
+
+			for i, expected := range tt.expected {
+				if errs[i].Error() != expected {
+					t.Errorf("Expected error %q, got %q", expected, errs[i].Error())
+				}
+			}
+		})
+	}
+}
+
+func TestTypeRegistry_ValidateKubernetes(t *testing.T) {
+	L := lua.NewState()
+	defer L.Close()
+
+	registry := NewTypeRegistry()
+	if err := registry.Register(&corev1.Pod{}); err != nil {
+		t.Fatalf("Register failed: %v", err)
+	}
+	if err := registry.Process(); err != nil {
+		t.Fatalf("Process failed: %v", err)
+	}
+
+	// A pod converted by the translator is always valid
+	pod := &corev1.Pod{}
+	pod.Kind = "Pod"
+	pod.APIVersion = "v1"
+	pod.Name = "web"
+	pod.Spec.Containers = []corev1.Container{{Name: "nginx", Image: "nginx:latest"}}
+
+	lv, err := NewTranslator().ToLua(L, pod)
+	if err != nil {
+		t.Fatalf("ToLua failed: %v", err)
+	}
+	if err := registry.Validate(L, lv, &corev1.Pod{}); err != nil {
+		t.Errorf("Expected translated pod to be valid, got: %v", err)
+	}
+
+	lv = evalLua(t, L, `{
+		kind = "Pod",
+		metadata = {name = "web", creationTimestamp = 12, labels = {app = "web"}},
+		spec = {
+			containers = {
+				{image = "nginx", ports = {{containerPort = "80"}}, resources = {limits = {cpu = "100m"}}},
+			},
+			priority = "high",
+		},
+	}`)
+
+	err = registry.Validate(L, lv, &corev1.Pod{})
+	if err == nil {
+		t.Fatal("Expected validation errors, got nil")
+	}
+
+	expected := []string{
+		"metadata.creationTimestamp: expected string, got number",
+		"spec.containers[1].ports[1].containerPort: expected integer, got string \"80\"",
+		"spec.containers[1].name: missing required field",
+		"spec.priority: expected integer, got string \"high\"",
+	}
+	for _, msg := range expected {
+		if !strings.Contains(err.Error(), msg) {
+			t.Errorf("Expected error to contain %q, got: %v", msg, err)
+		}
+	}
+}
+
+func TestTypeRegistry_ValidateUnregistered(t *testing.T) {
+	L := lua.NewState()
+	defer L.Close()
+
+	registry := NewTypeRegistry()
+	err := registry.Validate(L, L.NewTable(), &corev1.Pod{})
+	if err == nil {
+		t.Fatal("Expected error for unregistered type, got nil")
+	}
+
+	var errs ValidationErrors
+	if errors.As(err, &errs) {
+		t.Errorf("Expected a plain error, got validation errors: %v", err)
+	}
+}
+
+func TestTypeRegistry_TypeByName(t *testing.T) {
+	registry := NewTypeRegistry()
+	if err := registry.Register(&corev1.Pod{}); err != nil {
+		t.Fatalf("Register failed: %v", err)
+	}
+	if err := registry.Process(); err != nil {
+		t.Fatalf("Process failed: %v", err)
+	}
+
+	typ, err := registry.TypeByName("corev1.Container")
+	if err != nil {
+		t.Fatalf("Expected corev1.Container to be registered: %v", err)
+	}
+	if typ.Name() != "Container" {
+		t.Errorf("Expected Container, got %s", typ.Name())
+	}
+
+	if _, err := registry.TypeByName("corev1.Missing"); err == nil || !strings.Contains(err.Error(), "unknown type") {
+		t.Errorf("Expected corev1.Missing to be unknown, got %v", err)
+	}
+
+	// Two packages whose types get the same Lua name
+	registry.types["example.com/core/v1.Container"] = &TypeInfo{Name: "corev1.Container", GoType: reflect.TypeOf(struct{}{})}
+	_, err = registry.TypeByName("corev1.Container")
+	expected := `ambiguous type "corev1.Container", registered for example.com/core/v1.Container, k8s.io/api/core/v1.Container`
+	if err == nil || err.Error() != expected {
+		t.Errorf("Expected %q, got %v", expected, err)
+	}
+}
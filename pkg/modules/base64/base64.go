@@ -124,7 +124,7 @@ func encodeURL(L *lua.LState) int {
 //
 // Example:
 //
-//	local decoded, err = base64.decode_url("aGVsbG8gd29ybGQ")
+//	local decoded, err = base64.decode_url("aGVsbG8gd29ybGQ=")
 //	if err then
 //	    print("Error: " .. err)
 //	else
@@ -6,6 +6,8 @@ package base64
 import (
 	"testing"
 
+	"github.com/thomas-maurice/glua/pkg/stubgen"
+	"github.com/thomas-maurice/glua/pkg/stubgen/stubgentest"
 	lua "github.com/yuin/gopher-lua"
 )
 
@@ -119,3 +121,8 @@ func TestDecodeURL(t *testing.T) {
 		t.Fatalf("Failed to execute Lua code: %v", err)
 	}
 }
+
+// TestExamples: runs the Example blocks of the doc comments
+func TestExamples(t *testing.T) {
+	stubgentest.RunExampleTests(t, ".", "base64", Loader, stubgen.ExampleOptions{})
+}
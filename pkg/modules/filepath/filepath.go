@@ -26,125 +26,79 @@ import (
 	lua "github.com/yuin/gopher-lua"
 )
 
-// Loader: creates the filepath Lua module
+//go:generate go run ../../../cmd/stubgen -bindings .
+
+// Loader: creates the filepath Lua module. The bindings of the functions below
+// are generated in luaexport.gen.go from their @luaexport annotations.
 //
 // @luamodule filepath
 func Loader(L *lua.LState) int {
-	mod := L.SetFuncs(L.NewTable(), exports)
+	mod := L.SetFuncs(L.NewTable(), luaExports)
 	L.Push(mod)
 	return 1
 }
 
-var exports = map[string]lua.LGFunction{
-	"join":  join,
-	"split": split,
-	"abs":   abs,
-	"ext":   ext,
-	"base":  base,
-	"dir":   dir,
-	"clean": clean,
-}
-
 // join: joins path elements into a single path
 //
-// @luafunc join
+// @luaexport join
 // @luaparam ... string Path elements to join
 // @luareturn string The joined path
-func join(L *lua.LState) int {
-	n := L.GetTop()
-	if n == 0 {
-		L.Push(lua.LString(""))
-		return 1
-	}
-
-	parts := make([]string, n)
-	for i := 1; i <= n; i++ {
-		parts[i-1] = L.CheckString(i)
-	}
-
-	result := filepath.Join(parts...)
-	L.Push(lua.LString(result))
-	return 1
+func join(elem ...string) string {
+	return filepath.Join(elem...)
 }
 
 // split: splits path into directory and file
 //
-// @luafunc split
+// @luaexport split
 // @luaparam path string The path to split
 // @luareturn string The directory part
 // @luareturn string The file part
-func split(L *lua.LState) int {
-	path := L.CheckString(1)
-	dir, file := filepath.Split(path)
-	L.Push(lua.LString(dir))
-	L.Push(lua.LString(file))
-	return 2
+func split(path string) (string, string) {
+	return filepath.Split(path)
 }
 
 // abs: returns absolute path
 //
-// @luafunc abs
+// @luaexport abs
 // @luaparam path string The path to make absolute
 // @luareturn string The absolute path
 // @luareturn string|nil Error message if operation failed
-func abs(L *lua.LState) int {
-	path := L.CheckString(1)
-	absPath, err := filepath.Abs(path)
-	if err != nil {
-		L.Push(lua.LNil)
-		L.Push(lua.LString(err.Error()))
-		return 2
-	}
-
-	L.Push(lua.LString(absPath))
-	L.Push(lua.LNil)
-	return 2
+func abs(path string) (string, error) {
+	return filepath.Abs(path)
 }
 
 // ext: returns the file extension
 //
-// @luafunc ext
+// @luaexport ext
 // @luaparam path string The file path
 // @luareturn string The file extension (including the dot)
-func ext(L *lua.LState) int {
-	path := L.CheckString(1)
-	extension := filepath.Ext(path)
-	L.Push(lua.LString(extension))
-	return 1
+func ext(path string) string {
+	return filepath.Ext(path)
 }
 
 // base: returns the last element of path
 //
-// @luafunc base
+// @luaexport base
 // @luaparam path string The file path
 // @luareturn string The base name
-func base(L *lua.LState) int {
-	path := L.CheckString(1)
-	baseName := filepath.Base(path)
-	L.Push(lua.LString(baseName))
-	return 1
+func base(path string) string {
+	return filepath.Base(path)
 }
 
 // dir: returns all but the last element of path
 //
-// @luafunc dir
+// @luaexport dir
 // @luaparam path string The file path
 // @luareturn string The directory path
-func dir(L *lua.LState) int {
-	path := L.CheckString(1)
-	dirPath := filepath.Dir(path)
-	L.Push(lua.LString(dirPath))
-	return 1
+func dir(path string) string {
+	return filepath.Dir(path)
 }
 
 // clean: returns the shortest path equivalent to path
 //
-// @luafunc clean
+// @luaexport clean
 // @luaparam path string The path to clean
 // @luareturn string The cleaned path
-func clean(L *lua.LState) int {
-	path := L.CheckString(1)
-	cleanPath := filepath.Clean(path)
-	L.Push(lua.LString(cleanPath))
-	return 1
+func clean(path string) string {
+	return filepath.Clean(path)
 }
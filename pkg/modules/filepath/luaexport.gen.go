@@ -0,0 +1,112 @@
+// Code generated by stubgen -bindings. DO NOT EDIT.
+
+package filepath
+
+import lua "github.com/yuin/gopher-lua"
+
+// luaExports: the functions exported with @luaexport, by Lua name
+var luaExports = map[string]lua.LGFunction{
+	"abs":   luaAbs,
+	"base":  luaBase,
+	"clean": luaClean,
+	"dir":   luaDir,
+	"ext":   luaExt,
+	"join":  luaJoin,
+	"split": luaSplit,
+}
+
+// luaAbs: returns absolute path
+//
+// @luafunc abs
+// @luaparam path string The path to make absolute
+// @luareturn string The absolute path
+// @luareturn string|nil Error message if operation failed
+func luaAbs(L *lua.LState) int {
+	path := L.CheckString(1)
+	r0, err := abs(path)
+	if err != nil {
+		L.Push(lua.LNil)
+		L.Push(lua.LString(err.Error()))
+		return 2
+	}
+	L.Push(lua.LString(r0))
+	L.Push(lua.LNil)
+	return 2
+}
+
+// luaBase: returns the last element of path
+//
+// @luafunc base
+// @luaparam path string The file path
+// @luareturn string The base name
+func luaBase(L *lua.LState) int {
+	path := L.CheckString(1)
+	r0 := base(path)
+	L.Push(lua.LString(r0))
+	return 1
+}
+
+// luaClean: returns the shortest path equivalent to path
+//
+// @luafunc clean
+// @luaparam path string The path to clean
+// @luareturn string The cleaned path
+func luaClean(L *lua.LState) int {
+	path := L.CheckString(1)
+	r0 := clean(path)
+	L.Push(lua.LString(r0))
+	return 1
+}
+
+// luaDir: returns all but the last element of path
+//
+// @luafunc dir
+// @luaparam path string The file path
+// @luareturn string The directory path
+func luaDir(L *lua.LState) int {
+	path := L.CheckString(1)
+	r0 := dir(path)
+	L.Push(lua.LString(r0))
+	return 1
+}
+
+// luaExt: returns the file extension
+//
+// @luafunc ext
+// @luaparam path string The file path
+// @luareturn string The file extension (including the dot)
+func luaExt(L *lua.LState) int {
+	path := L.CheckString(1)
+	r0 := ext(path)
+	L.Push(lua.LString(r0))
+	return 1
+}
+
+// luaJoin: joins path elements into a single path
+//
+// @luafunc join
+// @luaparam ... string Path elements to join
+// @luareturn string The joined path
+func luaJoin(L *lua.LState) int {
+	elem := make([]string, 0, L.GetTop())
+	for i := 1; i <= L.GetTop(); i++ {
+		elem = append(elem, L.CheckString(i))
+	}
+	r0 := join(elem...)
+	L.Push(lua.LString(r0))
+	return 1
+}
+
+// luaSplit: splits path into directory and file
+//
+// @luafunc split
+// @luaparam path string The path to split
+// @luareturn string The directory part
+// @luareturn string The file part
+func luaSplit(L *lua.LState) int {
+	path := L.CheckString(1)
+	r0, r1 := split(path)
+	L.Push(lua.LString(r0))
+	L.Push(lua.LString(r1))
+	return 2
+}
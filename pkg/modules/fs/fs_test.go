@@ -4,10 +4,13 @@
 package fs
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
 	"testing"
 
+	"github.com/thomas-maurice/glua/pkg/stubgen"
+	"github.com/thomas-maurice/glua/pkg/stubgen/stubgentest"
 	lua "github.com/yuin/gopher-lua"
 )
 
@@ -292,3 +295,29 @@ func TestRoundTrip(t *testing.T) {
 		t.Fatalf("Failed to execute Lua code: %v", err)
 	}
 }
+
+// TestExamples: runs the Example blocks of the doc comments. Their absolute paths are
+// rebased on a temporary directory holding the files they use.
+func TestExamples(t *testing.T) {
+	stubgentest.RunExampleTests(t, ".", "fs", Loader, stubgen.ExampleOptions{
+		Setup: func(L *lua.LState) error {
+			root := t.TempDir()
+			for _, file := range []string{"etc/config.yaml", "tmp/file.txt", "tmp/mydir/file.txt", "path/to/file.txt"} {
+				path := filepath.Join(root, file)
+				if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+					return err
+				}
+				if err := os.WriteFile(path, []byte("content"), 0644); err != nil {
+					return err
+				}
+			}
+
+			return L.DoString(fmt.Sprintf(`
+				local fs = require("fs")
+				for name, fn in pairs(fs) do
+					fs[name] = function(path, ...) return fn(%q .. path, ...) end
+				end
+			`, root))
+		},
+	})
+}
@@ -6,6 +6,8 @@ package hex
 import (
 	"testing"
 
+	"github.com/thomas-maurice/glua/pkg/stubgen"
+	"github.com/thomas-maurice/glua/pkg/stubgen/stubgentest"
 	lua "github.com/yuin/gopher-lua"
 )
 
@@ -99,3 +101,8 @@ func TestDecodeOddLength(t *testing.T) {
 		t.Fatalf("Failed to execute Lua code: %v", err)
 	}
 }
+
+// TestExamples: runs the Example blocks of the doc comments
+func TestExamples(t *testing.T) {
+	stubgentest.RunExampleTests(t, ".", "hex", Loader, stubgen.ExampleOptions{})
+}
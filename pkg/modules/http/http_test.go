@@ -4,10 +4,14 @@
 package http
 
 import (
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 
+	"github.com/thomas-maurice/glua/pkg/modules/json"
+	"github.com/thomas-maurice/glua/pkg/stubgen"
+	"github.com/thomas-maurice/glua/pkg/stubgen/stubgentest"
 	lua "github.com/yuin/gopher-lua"
 )
 
@@ -139,3 +143,31 @@ func TestInvalidURL(t *testing.T) {
 		t.Fatalf("Failed to execute Lua code: %v", err)
 	}
 }
+
+// TestExamples: runs the Example blocks of the doc comments. Their requests to
+// https://api.example.com are sent to a test server instead.
+func TestExamples(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"key":"value"}`))
+	}))
+	defer server.Close()
+
+	stubgentest.RunExampleTests(t, ".", "http", Loader, stubgen.ExampleOptions{
+		Setup: func(L *lua.LState) error {
+			L.PreloadModule("json", json.Loader)
+			return L.DoString(fmt.Sprintf(`
+				json = require("json")
+				local http = require("http")
+				local function rebase(url)
+					return (url:gsub("^https://api%%.example%%.com", %q))
+				end
+				for _, name in ipairs({"get", "post", "put", "delete"}) do
+					local fn = http[name]
+					http[name] = function(url, ...) return fn(rebase(url), ...) end
+				end
+				local request = http.request
+				http.request = function(method, url, ...) return request(method, rebase(url), ...) end
+			`, server.URL))
+		},
+	})
+}
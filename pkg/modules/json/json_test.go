@@ -24,6 +24,8 @@ import (
 	"path/filepath"
 	"testing"
 
+	"github.com/thomas-maurice/glua/pkg/stubgen"
+	"github.com/thomas-maurice/glua/pkg/stubgen/stubgentest"
 	lua "github.com/yuin/gopher-lua"
 )
 
@@ -52,3 +54,8 @@ func TestLuaScripts(t *testing.T) {
 		})
 	}
 }
+
+// TestExamples: runs the Example blocks of the doc comments
+func TestExamples(t *testing.T) {
+	stubgentest.RunExampleTests(t, ".", "json", Loader, stubgen.ExampleOptions{})
+}
@@ -68,22 +68,29 @@ func NewClient(config *rest.Config) (*Client, error) {
 //	local k8sclient = require("k8sclient")
 //	local client = k8sclient.new_client()
 //	local gvk = {group = "", version = "v1", kind = "ConfigMap"}
-//	local cm, err = client:get(gvk, "default", "my-config")
+//	local cm, err = client.get(gvk, "default", "my-config")
 //
 // @luaclass GVKMatcher
 // @luafield group string The API group (empty string for core resources)
 // @luafield version string The API version (e.g., "v1", "v1beta1")
 // @luafield kind string The resource kind (e.g., "Pod", "Deployment")
 func Loader(config *rest.Config) lua.LGFunction {
+	return loader(func() (*Client, error) {
+		return NewClient(config)
+	})
+}
+
+// loader: creates the k8sclient module, with clients created by newClient
+func loader(newClient func() (*Client, error)) lua.LGFunction {
 	return func(L *lua.LState) int {
 		// Create module table with new_client factory function
 		mod := L.NewTable()
 		L.SetField(mod, "new_client", L.NewFunction(func(L *lua.LState) int {
-			return newClientLua(L, config)
+			return newClientLua(L, newClient)
 		}))
 
 		// For backwards compatibility, also export functions at module level
-		client, err := NewClient(config)
+		client, err := newClient()
 		if err != nil {
 			L.RaiseError("failed to create k8s client: %v", err)
 			return 0
@@ -189,9 +196,9 @@ func createGVKTable(L *lua.LState, group, version, kind string) *lua.LTable {
 //
 //	local k8sclient = require("k8sclient")
 //	local client = k8sclient.new_client()
-//	local pod, err = client:get({group="", version="v1", kind="Pod"}, "default", "my-pod")
-func newClientLua(L *lua.LState, config *rest.Config) int {
-	client, err := NewClient(config)
+//	local pod, err = client.get({group="", version="v1", kind="Pod"}, "default", "my-pod")
+func newClientLua(L *lua.LState, newClient func() (*Client, error)) int {
+	client, err := newClient()
 	if err != nil {
 		L.Push(lua.LNil)
 		L.Push(lua.LString(fmt.Sprintf("failed to create client: %v", err)))
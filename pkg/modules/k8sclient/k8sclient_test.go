@@ -24,7 +24,10 @@ import (
 	"path/filepath"
 	"testing"
 
+	"github.com/thomas-maurice/glua/pkg/stubgen"
+	"github.com/thomas-maurice/glua/pkg/stubgen/stubgentest"
 	lua "github.com/yuin/gopher-lua"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/client-go/dynamic/fake"
@@ -300,7 +303,9 @@ func TestNewClientLua(t *testing.T) {
 	L := lua.NewState()
 	defer L.Close()
 
-	n := newClientLua(L, config)
+	n := newClientLua(L, func() (*Client, error) {
+		return NewClient(config)
+	})
 	if n != 2 {
 		t.Errorf("Expected newClientLua to return 2 values, got %d", n)
 	}
@@ -327,3 +332,25 @@ func TestNewClientLua(t *testing.T) {
 		}
 	}
 }
+
+// TestExamples: runs the Example blocks of the doc comments against a fake client
+// holding the ConfigMap they use
+func TestExamples(t *testing.T) {
+	newClient := func() (*Client, error) {
+		return setupFakeClient(&unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "v1",
+			"kind":       "ConfigMap",
+			"metadata":   map[string]interface{}{"name": "my-config", "namespace": "default"},
+			"data":       map[string]interface{}{"key": "value"},
+		}}), nil
+	}
+
+	stubgentest.RunExampleTests(t, ".", "k8sclient", loader(newClient), stubgen.ExampleOptions{
+		Setup: func(L *lua.LState) error {
+			return L.DoString(`
+				client = require("k8sclient")
+				cm = client.get({group = "", version = "v1", kind = "ConfigMap"}, "default", "my-config")
+			`)
+		},
+	})
+}
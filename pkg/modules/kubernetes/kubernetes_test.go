@@ -24,6 +24,8 @@ import (
 	"path/filepath"
 	"testing"
 
+	"github.com/thomas-maurice/glua/pkg/stubgen"
+	"github.com/thomas-maurice/glua/pkg/stubgen/stubgentest"
 	lua "github.com/yuin/gopher-lua"
 )
 
@@ -460,3 +462,16 @@ func TestLuaMatchGVKScripts(t *testing.T) {
 		})
 	}
 }
+
+// TestExamples: runs the Example blocks of the doc comments
+func TestExamples(t *testing.T) {
+	stubgentest.RunExampleTests(t, ".", "kubernetes", Loader, stubgen.ExampleOptions{
+		Setup: func(L *lua.LState) error {
+			return L.DoString(`
+				k8s = require("kubernetes")
+				pod = {apiVersion = "v1", kind = "Pod", metadata = {name = "nginx"}}
+				myPod = {apiVersion = "v1", kind = "Pod", metadata = {name = "my-pod"}}
+			`)
+		},
+	})
+}
@@ -48,6 +48,7 @@ import (
 
 func main() {
 	outputDir := flag.String("output", "library", "Output directory for generated stubs")
+	snapshotFile := flag.String("snapshot", "", "Optional name of a type snapshot to write in the output directory, for `stubgen diff`")
 	flag.Parse()
 
 	// Get the directory where this source file lives
@@ -61,10 +62,11 @@ func main() {
 	// Create generator and generate stubs
 	gen := stubgen.NewGenerator()
 	outputFile, err := gen.Generate(stubgen.GenerateConfig{
-		ScanDir:    moduleDir,
-		OutputDir:  *outputDir,
-		ModuleName: "kubernetes",
-		OutputFile: "kubernetes.gen.lua",
+		ScanDir:      moduleDir,
+		OutputDir:    *outputDir,
+		ModuleName:   "kubernetes",
+		OutputFile:   "kubernetes.gen.lua",
+		SnapshotFile: *snapshotFile,
 		Types: []interface{}{
 			kubernetes.GVKMatcher{},
 			// Core resources
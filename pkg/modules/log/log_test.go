@@ -27,6 +27,8 @@ import (
 	"time"
 
 	"github.com/charmbracelet/log"
+	"github.com/thomas-maurice/glua/pkg/stubgen"
+	"github.com/thomas-maurice/glua/pkg/stubgen/stubgentest"
 	lua "github.com/yuin/gopher-lua"
 )
 
@@ -339,3 +341,17 @@ func TestInjectLoggerWithFields(t *testing.T) {
 		t.Errorf("Expected output to contain pre-set fields")
 	}
 }
+
+// TestExamples: runs the Example blocks of the doc comments
+func TestExamples(t *testing.T) {
+	stubgentest.RunExampleTests(t, ".", "log", Loader, stubgen.ExampleOptions{
+		Setup: func(L *lua.LState) error {
+			return L.DoString(`
+				err_msg = "connection refused"
+				logger = require("log").logger()
+			`)
+		},
+		// Fatal exits the process
+		Skip: []string{"log.fatal", "log.Logger:fatal"},
+	})
+}
@@ -25,6 +25,8 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/thomas-maurice/glua/pkg/stubgen"
+	"github.com/thomas-maurice/glua/pkg/stubgen/stubgentest"
 	lua "github.com/yuin/gopher-lua"
 )
 
@@ -90,3 +92,8 @@ func TestLuaScripts(t *testing.T) {
 		})
 	}
 }
+
+// TestExamples: runs the Example blocks of the doc comments
+func TestExamples(t *testing.T) {
+	stubgentest.RunExampleTests(t, ".", "spew", Loader, stubgen.ExampleOptions{})
+}
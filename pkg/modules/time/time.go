@@ -145,7 +145,7 @@ func parseRFC3339(L *lua.LState) int {
 // Example:
 //
 //	local formatted = time.format(1710512400, "2006-01-02 15:04:05")
-//	print(formatted)  -- prints "2024-03-15 14:30:00"
+//	print(formatted)  -- prints "2024-03-15 14:20:00"
 func format(L *lua.LState) int {
 	timestamp := L.CheckNumber(1)
 	layout := L.CheckString(2)
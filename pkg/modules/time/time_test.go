@@ -7,6 +7,8 @@ import (
 	"testing"
 	"time"
 
+	"github.com/thomas-maurice/glua/pkg/stubgen"
+	"github.com/thomas-maurice/glua/pkg/stubgen/stubgentest"
 	lua "github.com/yuin/gopher-lua"
 )
 
@@ -290,3 +292,14 @@ func TestKubernetesTimestamp(t *testing.T) {
 		t.Fatalf("Failed to execute Lua code: %v", err)
 	}
 }
+
+// TestExamples: runs the Example blocks of the doc comments
+func TestExamples(t *testing.T) {
+	stubgentest.RunExampleTests(t, ".", "time", Loader, stubgen.ExampleOptions{
+		Setup: func(L *lua.LState) error {
+			return L.DoString(`pod_creation_time = 1710512400`)
+		},
+		// Sleeps for two seconds
+		Skip: []string{"time.sleep"},
+	})
+}
@@ -0,0 +1,60 @@
+// Copyright (c) 2024-2025 Thomas Maurice
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+
+	"github.com/thomas-maurice/glua/pkg/stubgen"
+)
+
+func main() {
+	outputDir := flag.String("output", "library", "Output directory for generated stubs")
+	flag.Parse()
+
+	// Get the directory where this source file lives
+	_, filename, _, ok := runtime.Caller(0)
+	if !ok {
+		fmt.Fprintf(os.Stderr, "Error determining source directory\n")
+		os.Exit(1)
+	}
+	moduleDir := filepath.Dir(filepath.Dir(filename))
+
+	// Create generator and generate stubs
+	gen := stubgen.NewGenerator()
+	outputFile, err := gen.Generate(stubgen.GenerateConfig{
+		ScanDir:    moduleDir,
+		OutputDir:  *outputDir,
+		ModuleName: "validate",
+		OutputFile: "validate.gen.lua",
+		Types:      nil, // No types to register for validate module
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("Generated %s\n", outputFile)
+}
@@ -0,0 +1,26 @@
+
+-- Test: validate.assert
+--
+-- Verifies that validate.assert() returns valid values unchanged
+-- and raises an error for invalid ones.
+
+local validate = require("validate")
+
+local container = {name = "nginx", image = "nginx:latest"}
+local result = validate.assert(container, "corev1.Container")
+if result ~= container then
+	error("Expected assert to return the value unchanged")
+end
+
+local ok, err = pcall(validate.assert, {image = 42}, "corev1.Container")
+if ok then
+	error("Expected assert to raise an error")
+end
+
+if not string.find(err, "invalid corev1.Container", 1, true) then
+	error("Expected error to mention the type, got: " .. err)
+end
+
+if not string.find(err, "image: expected string, got number", 1, true) then
+	error("Expected error to mention the image field, got: " .. err)
+end
@@ -0,0 +1,38 @@
+
+-- Test: validate.check - invalid object
+--
+-- Verifies that validate.check() reports wrong types, unknown fields
+-- and missing required fields with their paths.
+
+local validate = require("validate")
+
+local pod = {
+	kind = "Pod",
+	metadata = {name = "web", labelz = {}},
+	spec = {
+		containers = {
+			{image = "nginx", ports = {{containerPort = "80"}}},
+		},
+	},
+}
+
+local ok, errs = validate.check(pod, "corev1.Pod")
+if ok then
+	error("Expected pod to be invalid")
+end
+
+local expected = {
+	"metadata.labelz: unknown field of v1.ObjectMeta",
+	"spec.containers[1].ports[1].containerPort: expected integer, got string \"80\"",
+	"spec.containers[1].name: missing required field",
+}
+
+if #errs ~= #expected then
+	error("Expected " .. #expected .. " errors, got " .. #errs .. ": " .. table.concat(errs, "; "))
+end
+
+for i, msg in ipairs(expected) do
+	if errs[i] ~= msg then
+		error("Expected error " .. i .. " to be '" .. msg .. "', got: " .. errs[i])
+	end
+end
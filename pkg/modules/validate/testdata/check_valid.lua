@@ -0,0 +1,27 @@
+
+-- Test: validate.check - valid object
+--
+-- Verifies that validate.check() accepts a pod built by the script
+-- and returns true without errors.
+
+local validate = require("validate")
+
+local pod = {
+	apiVersion = "v1",
+	kind = "Pod",
+	metadata = {name = "web", labels = {app = "web"}},
+	spec = {
+		containers = {
+			{name = "nginx", image = "nginx:latest", ports = {{containerPort = 80}}},
+		},
+	},
+}
+
+local ok, errs = validate.check(pod, "corev1.Pod")
+if not ok then
+	error("Expected pod to be valid, got: " .. table.concat(errs, "; "))
+end
+
+if errs ~= nil then
+	error("Expected errs to be nil for a valid pod")
+end
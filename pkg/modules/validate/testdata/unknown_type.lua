@@ -0,0 +1,16 @@
+
+-- Test: validate.check - unknown type
+--
+-- Verifies that validate.check() raises an error when the type
+-- name is not registered.
+
+local validate = require("validate")
+
+local ok, err = pcall(validate.check, {}, "corev1.DoesNotExist")
+if ok then
+	error("Expected check to fail for an unknown type")
+end
+
+if not string.find(err, "unknown type", 1, true) then
+	error("Expected unknown type error, got: " .. err)
+end
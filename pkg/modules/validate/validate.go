@@ -0,0 +1,148 @@
+// Copyright (c) 2024-2025 Thomas Maurice
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package validate
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+
+	"github.com/thomas-maurice/glua/pkg/glua"
+	lua "github.com/yuin/gopher-lua"
+)
+
+// Loader: creates a validate module checking Lua values against the types of a registry.
+// The registry must have been processed with Process() before scripts run.
+//
+// Usage:
+//
+//	registry := glua.NewTypeRegistry()
+//	registry.Register(&corev1.Pod{})
+//	registry.Process()
+//	L.PreloadModule("validate", validate.Loader(registry))
+//
+// @luamodule validate
+//
+// Example usage in Lua:
+//
+//	local validate = require("validate")
+//	local ok, errs = validate.check(pod, "corev1.Pod")
+//	if not ok then
+//	    for _, e in ipairs(errs) do print(e) end
+//	end
+func Loader(registry *glua.TypeRegistry) lua.LGFunction {
+	return func(L *lua.LState) int {
+		mod := L.NewTable()
+		L.SetField(mod, "check", L.NewFunction(func(L *lua.LState) int {
+			return check(L, registry)
+		}))
+		L.SetField(mod, "assert", L.NewFunction(func(L *lua.LState) int {
+			return assert(L, registry)
+		}))
+
+		L.Push(mod)
+		return 1
+	}
+}
+
+// check: validates a value against a registered type.
+// Returns true, or false and the list of problems found.
+//
+// @luafunc check
+// @luaparam value any The value to validate
+// @luaparam type_name string The registered type name (e.g., "corev1.Pod")
+// @luareturn boolean ok Whether the value matches the type
+// @luareturn string[]|nil errs The problems found, each prefixed with its path
+//
+// Example:
+//
+//	local ok, errs = validate.check({kind = "Pod"}, "corev1.Pod")
+//	if not ok then
+//	    print(errs[1])  -- prints e.g. "spec.containers[1].name: missing required field"
+//	end
+func check(L *lua.LState, registry *glua.TypeRegistry) int {
+	errs := validateArgs(L, registry)
+	if len(errs) == 0 {
+		L.Push(lua.LTrue)
+		L.Push(lua.LNil)
+		return 2
+	}
+
+	tbl := L.NewTable()
+	for _, err := range errs {
+		tbl.Append(lua.LString(err.Error()))
+	}
+
+	L.Push(lua.LFalse)
+	L.Push(tbl)
+	return 2
+}
+
+// assert: validates a value against a registered type and raises an error listing
+// every problem found if it does not match.
+//
+// @luafunc assert
+// @luaparam value any The value to validate
+// @luaparam type_name string The registered type name (e.g., "corev1.Pod")
+// @luareturn any value The value, unchanged
+//
+// Example:
+//
+//	local pod = validate.assert(build_pod(), "corev1.Pod")
+func assert(L *lua.LState, registry *glua.TypeRegistry) int {
+	errs := validateArgs(L, registry)
+	if len(errs) > 0 {
+		messages := make([]string, len(errs))
+		for i, err := range errs {
+			messages[i] = err.Error()
+		}
+		L.RaiseError("invalid %s: %s", L.CheckString(2), strings.Join(messages, "; "))
+		return 0
+	}
+
+	L.Push(L.Get(1))
+	return 1
+}
+
+// validateArgs: validates the value argument against the type named by the second argument
+func validateArgs(L *lua.LState, registry *glua.TypeRegistry) glua.ValidationErrors {
+	value := L.CheckAny(1)
+	typeName := L.CheckString(2)
+
+	typ, err := registry.TypeByName(typeName)
+	if err != nil {
+		L.ArgError(2, err.Error())
+		return nil
+	}
+
+	err = registry.Validate(L, value, reflect.New(typ).Interface())
+	if err == nil {
+		return nil
+	}
+
+	var errs glua.ValidationErrors
+	if !errors.As(err, &errs) {
+		L.RaiseError("%v", err)
+		return nil
+	}
+
+	return errs
+}
@@ -0,0 +1,86 @@
+// Copyright (c) 2024-2025 Thomas Maurice
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package validate
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/thomas-maurice/glua/pkg/glua"
+	"github.com/thomas-maurice/glua/pkg/stubgen"
+	"github.com/thomas-maurice/glua/pkg/stubgen/stubgentest"
+	lua "github.com/yuin/gopher-lua"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// TestLuaScripts: runs all Lua test scripts in testdata/ directory
+func TestLuaScripts(t *testing.T) {
+	registry := glua.NewTypeRegistry()
+	if err := registry.Register(&corev1.Pod{}); err != nil {
+		t.Fatalf("Failed to register type: %v", err)
+	}
+	if err := registry.Process(); err != nil {
+		t.Fatalf("Failed to process types: %v", err)
+	}
+
+	files, err := filepath.Glob("testdata/*.lua")
+	if err != nil {
+		t.Fatalf("Failed to glob testdata: %v", err)
+	}
+
+	if len(files) == 0 {
+		t.Fatal("No Lua test files found in testdata/")
+	}
+
+	for _, file := range files {
+		testName := filepath.Base(file)
+		t.Run(testName, func(t *testing.T) {
+			L := lua.NewState()
+			defer L.Close()
+
+			L.PreloadModule("validate", Loader(registry))
+
+			if err := L.DoFile(file); err != nil {
+				t.Fatalf("Lua script failed: %v", err)
+			}
+		})
+	}
+}
+
+// TestExamples: runs the Example blocks of the doc comments
+func TestExamples(t *testing.T) {
+	registry := glua.NewTypeRegistry()
+	if err := registry.Register(&corev1.Pod{}); err != nil {
+		t.Fatalf("Failed to register type: %v", err)
+	}
+	if err := registry.Process(); err != nil {
+		t.Fatalf("Failed to process types: %v", err)
+	}
+
+	stubgentest.RunExampleTests(t, ".", "validate", Loader(registry), stubgen.ExampleOptions{
+		Setup: func(L *lua.LState) error {
+			return L.DoString(`
+				pod = {kind = "Pod", spec = {containers = {{name = "nginx", image = "nginx"}}}}
+				function build_pod() return pod end
+			`)
+		},
+	})
+}
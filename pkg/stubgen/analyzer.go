@@ -34,10 +34,15 @@ import (
 // LuaModule: represents a discovered Lua module
 type LuaModule struct {
 	Name              string
+	Dir               string         // Directory of the Go package declaring the module
+	Pos               token.Position // Position of the @luamodule annotation
 	Functions         []*LuaFunction
 	Classes           []*LuaClass
 	Constants         []*LuaConst
+	Enums             []*LuaEnum
+	Aliases           []*LuaAlias
 	CustomAnnotations []string // Module-level custom annotations
+	Example           string   // Lua code of the "Example usage in Lua:" block, if any
 }
 
 // LuaClass: represents a Lua class (UserData type with methods)
@@ -58,24 +63,65 @@ type LuaField struct {
 
 // LuaMethod: represents a method on a Lua class
 type LuaMethod struct {
-	Name              string
-	Description       string
-	Params            []*LuaParam
-	Returns           []*LuaReturn
-	CustomAnnotations []string
+	Name               string
+	Description        string
+	Params             []*LuaParam
+	Returns            []*LuaReturn
+	Generics           []*LuaGeneric
+	Overloads          []string // Alternative fun(...) signatures
+	See                []string // Cross-references
+	Deprecated         bool
+	DeprecationMessage string
+	CustomAnnotations  []string
+	Example            string // Lua code of the "Example:" block, if any
 }
 
 // LuaFunction: represents a Lua function exported by a module
 type LuaFunction struct {
-	Name              string
-	Description       string
-	Params            []*LuaParam
-	Returns           []*LuaReturn
-	CustomAnnotations []string // Function-level custom annotations
+	Name               string
+	Description        string
+	Params             []*LuaParam
+	Returns            []*LuaReturn
+	Generics           []*LuaGeneric
+	Overloads          []string // Alternative fun(...) signatures
+	See                []string // Cross-references
+	Deprecated         bool
+	DeprecationMessage string
+	CustomAnnotations  []string // Function-level custom annotations
+	Example            string   // Lua code of the "Example:" block, if any
 }
 
 // LuaParam: represents a function parameter
 type LuaParam struct {
+	Name        string // Parameter name, without the "?" of optional parameters
+	Type        string
+	Description string
+	Optional    bool // Declared as name?
+	Vararg      bool // Declared as ...
+}
+
+// LuaGeneric: represents a type parameter of a generic function or method
+type LuaGeneric struct {
+	Name       string
+	Constraint string // Type the parameter must satisfy, if any
+}
+
+// LuaEnum: represents an enumeration of values exported by a module
+type LuaEnum struct {
+	Name        string
+	Description string
+	Values      []*LuaEnumValue
+}
+
+// LuaEnumValue: represents one member of an enumeration
+type LuaEnumValue struct {
+	Name        string
+	Value       string // Lua literal, e.g. "debug" or 1
+	Description string
+}
+
+// LuaAlias: represents a named type alias
+type LuaAlias struct {
 	Name        string
 	Type        string
 	Description string
@@ -97,20 +143,32 @@ type LuaConst struct {
 
 // Analyzer: scans Go source files and extracts Lua module definitions
 type Analyzer struct {
-	modules map[string]*LuaModule
+	modules     map[string]*LuaModule
+	fset        *token.FileSet
+	comment     *ast.CommentGroup // Comment being extracted, used to position diagnostics
+	diagnostics []Diagnostic
+	references  []reference
+	scanned     map[string]bool // Files already parsed, so overlapping scans do not duplicate annotations
+	pending     []*ast.File     // Files of the current scan that do not declare a module themselves
 }
 
 // NewAnalyzer: creates a new code analyzer instance
 func NewAnalyzer() *Analyzer {
 	return &Analyzer{
 		modules: make(map[string]*LuaModule),
+		fset:    token.NewFileSet(),
+		scanned: make(map[string]bool),
 	}
 }
 
 // ScanDirectory: recursively scans a directory for Go files and extracts Lua module metadata.
 // It parses comment annotations like @luamodule, @luafunc, @luaparam, @luareturn.
-func (a *Analyzer) ScanDirectory(dir string) error {
-	return filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+// Malformed annotations are skipped and returned as diagnostics, positioned at their comment line;
+// the error is only set when the directory cannot be read or a file cannot be parsed.
+func (a *Analyzer) ScanDirectory(dir string) ([]Diagnostic, error) {
+	a.beginScan()
+
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
 			return err
 		}
@@ -127,31 +185,85 @@ func (a *Analyzer) ScanDirectory(dir string) error {
 
 		return a.parseFile(path)
 	})
+	if err != nil {
+		return nil, err
+	}
+
+	return a.endScan(), nil
+}
+
+// beginScan: resets the diagnostics before scanning
+func (a *Analyzer) beginScan() {
+	a.diagnostics = nil
+	a.references = nil
+	a.pending = nil
+}
+
+// endScan: attaches the files that do not declare a module to the module of their package,
+// resolves cross-references and returns the sorted diagnostics of the scan
+func (a *Analyzer) endScan() []Diagnostic {
+	modulesByDir := make(map[string][]*LuaModule)
+	for _, module := range a.modules {
+		modulesByDir[module.Dir] = append(modulesByDir[module.Dir], module)
+	}
+
+	for _, file := range a.pending {
+		dir := filepath.Dir(a.fset.Position(file.Pos()).Filename)
+
+		// A package declaring several modules is ambiguous
+		if modules := modulesByDir[dir]; len(modules) == 1 {
+			module := a.processFuncDeclarations(file, modules[0])
+			a.processStandaloneComments(file, module)
+		}
+	}
+	a.comment = nil
+	a.pending = nil
+
+	a.resolveReferences()
+	sortDiagnostics(a.diagnostics)
+
+	return a.diagnostics
 }
 
 // parseFile: parses a single Go file and extracts Lua module information
 func (a *Analyzer) parseFile(filename string) error {
-	fset := token.NewFileSet()
-	file, err := parser.ParseFile(fset, filename, nil, parser.ParseComments)
+	// The same file can be reached from several roots
+	key, err := filepath.Abs(filename)
+	if err != nil {
+		key = filename
+	}
+	if a.scanned[key] {
+		return nil
+	}
+	a.scanned[key] = true
+
+	file, err := parser.ParseFile(a.fset, filename, nil, parser.ParseComments)
 	if err != nil {
 		return fmt.Errorf("failed to parse %s: %w", filename, err)
 	}
 
-	var currentModule *LuaModule
-	classMap := make(map[string]*LuaClass)
+	a.checkTags(file)
+	defer func() { a.comment = nil }()
 
 	// Process function declarations
-	currentModule = a.processFuncDeclarations(file, classMap)
+	currentModule := a.processFuncDeclarations(file, nil)
+
+	// Files without @luamodule are attached to the module of their package once the scan is complete
+	if currentModule == nil {
+		a.pending = append(a.pending, file)
+		return nil
+	}
 
 	// Process standalone comments
-	a.processStandaloneComments(file, currentModule, classMap)
+	a.processStandaloneComments(file, currentModule)
 
 	return nil
 }
 
-// processFuncDeclarations: processes all function declarations in a file
-func (a *Analyzer) processFuncDeclarations(file *ast.File, classMap map[string]*LuaClass) *LuaModule {
-	var currentModule *LuaModule
+// processFuncDeclarations: processes all function declarations in a file,
+// starting with the given module (nil until a @luamodule is found)
+func (a *Analyzer) processFuncDeclarations(file *ast.File, currentModule *LuaModule) *LuaModule {
+	dir := filepath.Dir(a.fset.Position(file.Pos()).Filename)
 
 	for _, decl := range file.Decls {
 		funcDecl, ok := decl.(*ast.FuncDecl)
@@ -160,19 +272,44 @@ func (a *Analyzer) processFuncDeclarations(file *ast.File, classMap map[string]*
 		}
 
 		comment := funcDecl.Doc.Text()
+		a.comment = funcDecl.Doc
 
 		// Try to extract module, function, const, or method
 		if module := a.tryExtractModule(comment); module != nil {
-			currentModule = module
-			a.modules[module.Name] = module
+			currentModule = a.registerModule(module, dir)
 		} else if currentModule != nil {
-			a.tryAddFunctionOrConstOrMethod(comment, currentModule, classMap)
+			a.tryAddFunctionOrConstOrMethod(comment, currentModule)
 		}
 	}
 
 	return currentModule
 }
 
+// registerModule: registers a module declared in dir and returns the module its annotations belong to.
+// A module declared again in the same package is merged; declared in another package, it is reported
+// and its annotations are kept out of the registered module.
+func (a *Analyzer) registerModule(module *LuaModule, dir string) *LuaModule {
+	module.Dir = dir
+	module.Pos = a.annotationPosition(a.comment, "@luamodule")
+
+	existing, exists := a.modules[module.Name]
+	if !exists {
+		a.modules[module.Name] = module
+		return module
+	}
+
+	if existing.Dir != dir {
+		a.report("@luamodule "+module.Name, "module %s is already declared in %s", module.Name, existing.Dir)
+		return module
+	}
+
+	existing.CustomAnnotations = append(existing.CustomAnnotations, module.CustomAnnotations...)
+	if existing.Example == "" {
+		existing.Example = module.Example
+	}
+	return existing
+}
+
 // tryExtractModule: tries to extract a module from a comment
 func (a *Analyzer) tryExtractModule(comment string) *LuaModule {
 	if moduleName := a.extractModuleName(comment); moduleName != "" {
@@ -181,13 +318,14 @@ func (a *Analyzer) tryExtractModule(comment string) *LuaModule {
 			Functions:         make([]*LuaFunction, 0),
 			Classes:           make([]*LuaClass, 0),
 			CustomAnnotations: a.extractCustomAnnotations(comment),
+			Example:           a.extractExample(comment),
 		}
 	}
 	return nil
 }
 
 // tryAddFunctionOrConstOrMethod: tries to add a function, constant, or method to the current module
-func (a *Analyzer) tryAddFunctionOrConstOrMethod(comment string, module *LuaModule, classMap map[string]*LuaClass) {
+func (a *Analyzer) tryAddFunctionOrConstOrMethod(comment string, module *LuaModule) {
 	if luaFunc := a.extractFunction(comment); luaFunc != nil {
 		module.Functions = append(module.Functions, luaFunc)
 		return
@@ -199,60 +337,77 @@ func (a *Analyzer) tryAddFunctionOrConstOrMethod(comment string, module *LuaModu
 	}
 
 	if luaMethod := a.extractMethod(comment); luaMethod != nil {
-		a.addMethodToClass(luaMethod, module, classMap)
+		a.addMethodToClass(luaMethod, module)
 	}
 }
 
 // addMethodToClass: adds a method to a class, creating the class if needed
-func (a *Analyzer) addMethodToClass(luaMethod *methodResult, module *LuaModule, classMap map[string]*LuaClass) {
+func (a *Analyzer) addMethodToClass(luaMethod *methodResult, module *LuaModule) {
 	className := luaMethod.className
-	class, ok := classMap[className]
-	if !ok {
+	class := module.findClass(className)
+	if class == nil {
 		class = &LuaClass{
 			Name:              className,
 			Methods:           make([]*LuaMethod, 0),
 			Fields:            make([]*LuaField, 0),
 			CustomAnnotations: make([]string, 0),
 		}
-		classMap[className] = class
 		module.Classes = append(module.Classes, class)
 	}
 	class.Methods = append(class.Methods, luaMethod.method)
 }
 
 // processStandaloneComments: processes standalone comments for constants and class definitions
-func (a *Analyzer) processStandaloneComments(file *ast.File, module *LuaModule, classMap map[string]*LuaClass) {
+func (a *Analyzer) processStandaloneComments(file *ast.File, module *LuaModule) {
 	if module == nil {
 		return
 	}
 
 	for _, commentGroup := range file.Comments {
 		comment := commentGroup.Text()
+		a.comment = commentGroup
 
 		if luaConst := a.extractConst(comment); luaConst != nil {
 			module.Constants = append(module.Constants, luaConst)
 		}
 
 		if classInfo := a.extractClassDefinition(comment); classInfo != nil {
-			a.mergeOrAddClass(classInfo, module, classMap)
+			a.mergeOrAddClass(classInfo, module)
 		}
+
+		if enum := a.extractEnum(comment); enum != nil {
+			if strings.Contains(enum.Name, ".") && !strings.HasPrefix(enum.Name, module.Name+".") {
+				a.report("@luaenum "+enum.Name, "@luaenum %s: qualified enum names must start with %s.", enum.Name, module.Name)
+			}
+			module.Enums = append(module.Enums, enum)
+		}
+
+		module.Aliases = append(module.Aliases, a.extractAliases(comment)...)
 	}
 }
 
 // mergeOrAddClass: merges class info into existing class or adds new class
-func (a *Analyzer) mergeOrAddClass(classInfo *LuaClass, module *LuaModule, classMap map[string]*LuaClass) {
-	existingClass, exists := classMap[classInfo.Name]
-	if exists {
+func (a *Analyzer) mergeOrAddClass(classInfo *LuaClass, module *LuaModule) {
+	if existingClass := module.findClass(classInfo.Name); existingClass != nil {
 		existingClass.Fields = append(existingClass.Fields, classInfo.Fields...)
 		if existingClass.Description == "" {
 			existingClass.Description = classInfo.Description
 		}
 	} else {
-		classMap[classInfo.Name] = classInfo
 		module.Classes = append(module.Classes, classInfo)
 	}
 }
 
+// findClass: returns the class of the module with the given name, or nil
+func (m *LuaModule) findClass(name string) *LuaClass {
+	for _, class := range m.Classes {
+		if class.Name == name {
+			return class
+		}
+	}
+	return nil
+}
+
 // extractModuleName: extracts module name from @luamodule annotation
 func (a *Analyzer) extractModuleName(comment string) string {
 	lines := strings.Split(comment, "\n")
@@ -320,6 +475,9 @@ func (a *Analyzer) extractFunction(comment string) *LuaFunction {
 		if strings.HasPrefix(line, "@luaparam ") {
 			param := a.parseParam(line)
 			if param != nil {
+				if n := len(fn.Params); n > 0 && fn.Params[n-1].Vararg {
+					a.report(line, "@luaparam %s: parameters cannot follow the vararg parameter ...", param.Name)
+				}
 				fn.Params = append(fn.Params, param)
 			}
 		} else if strings.HasPrefix(line, "@luareturn ") {
@@ -332,16 +490,73 @@ func (a *Analyzer) extractFunction(comment string) *LuaFunction {
 			if annotation != "" {
 				fn.CustomAnnotations = append(fn.CustomAnnotations, annotation)
 			}
+		} else if strings.HasPrefix(line, "@luaoverload ") {
+			overload := strings.TrimSpace(strings.TrimPrefix(line, "@luaoverload "))
+			if err := validateFunctionType(overload); err != nil {
+				a.report(line, "invalid @luaoverload %q: %v", overload, err)
+				continue
+			}
+			fn.Overloads = append(fn.Overloads, overload)
+		} else if strings.HasPrefix(line, "@luageneric ") {
+			if generic := a.parseGeneric(line); generic != nil {
+				fn.Generics = append(fn.Generics, generic)
+			}
+		} else if strings.HasPrefix(line, "@luasee ") {
+			ref := strings.TrimSpace(strings.TrimPrefix(line, "@luasee "))
+			if a.comment != nil {
+				a.references = append(a.references, reference{line: line, comment: a.comment, target: strings.Fields(ref)[0]})
+			}
+			fn.See = append(fn.See, ref)
+		} else if line == "@luadeprecated" || strings.HasPrefix(line, "@luadeprecated ") {
+			fn.Deprecated = true
+			fn.DeprecationMessage = strings.TrimSpace(strings.TrimPrefix(line, "@luadeprecated"))
 		}
 	}
 
 	if fn != nil {
 		fn.Description = description.String()
+		fn.Example = a.extractExample(comment)
 	}
 
 	return fn
 }
 
+// extractExample: extracts the Lua code of the "Example:" (or "Example usage in Lua:") block
+// of a comment, i.e. the indented lines following the heading, without their indentation
+func (a *Analyzer) extractExample(comment string) string {
+	lines := strings.Split(comment, "\n")
+
+	start := -1
+	for i, line := range lines {
+		heading := strings.TrimSpace(line)
+		if heading == "Example:" || heading == "Example usage in Lua:" {
+			start = i + 1
+			break
+		}
+	}
+
+	if start < 0 {
+		return ""
+	}
+
+	var code []string
+	for _, line := range lines[start:] {
+		if strings.TrimSpace(line) == "" {
+			code = append(code, "")
+			continue
+		}
+
+		// The block ends with the first line that is not indented
+		if !strings.HasPrefix(line, "\t") && !strings.HasPrefix(line, " ") {
+			break
+		}
+
+		code = append(code, strings.TrimPrefix(line, "\t"))
+	}
+
+	return strings.Trim(strings.Join(code, "\n"), "\n")
+}
+
 // extractConst: extracts constant information from comment annotations
 func (a *Analyzer) extractConst(comment string) *LuaConst {
 	lines := strings.Split(comment, "\n")
@@ -354,7 +569,9 @@ func (a *Analyzer) extractConst(comment string) *LuaConst {
 		if strings.HasPrefix(line, "@luaconst ") {
 			// Parse: @luaconst NAME TYPE DESCRIPTION
 			parts := strings.Fields(strings.TrimPrefix(line, "@luaconst "))
-			if len(parts) >= 2 {
+			if len(parts) < 2 {
+				a.report(line, "@luaconst %s: missing type", parts[0])
+			} else {
 				cnst = &LuaConst{
 					Name: parts[0],
 					Type: parts[1],
@@ -362,6 +579,7 @@ func (a *Analyzer) extractConst(comment string) *LuaConst {
 				if len(parts) > 2 {
 					cnst.Description = strings.Join(parts[2:], " ")
 				}
+				a.checkType(line, "@luaconst "+cnst.Name, cnst.Type)
 			}
 			break
 		}
@@ -402,15 +620,16 @@ func (a *Analyzer) extractClassDefinition(comment string) *LuaClass {
 		// Look for @luafield annotations (only if we found a class)
 		if class != nil && strings.HasPrefix(line, "@luafield ") {
 			// Parse: @luafield FIELDNAME TYPE DESCRIPTION
-			parts := strings.Fields(strings.TrimPrefix(line, "@luafield "))
-			if len(parts) >= 2 {
+			name, rest, _ := strings.Cut(strings.TrimSpace(strings.TrimPrefix(line, "@luafield ")), " ")
+			if typ, description := cutLuaType(rest); typ == "" {
+				a.report(line, "@luafield %s: missing type", name)
+			} else {
 				field := &LuaField{
-					Name: parts[0],
-					Type: parts[1],
-				}
-				if len(parts) > 2 {
-					field.Description = strings.Join(parts[2:], " ")
+					Name:        name,
+					Type:        typ,
+					Description: strings.Join(strings.Fields(description), " "),
 				}
+				a.checkType(line, "@luafield "+field.Name, field.Type)
 				class.Fields = append(class.Fields, field)
 			}
 		}
@@ -419,6 +638,95 @@ func (a *Analyzer) extractClassDefinition(comment string) *LuaClass {
 	return class
 }
 
+// extractEnum: extracts an enumeration definition with its values
+// Format:
+//
+//	@luaenum <Name> <description>
+//	@luavalue <KEY> <value> <description>
+//	@luavalue <KEY> <value> <description>
+func (a *Analyzer) extractEnum(comment string) *LuaEnum {
+	lines := strings.Split(comment, "\n")
+
+	var enum *LuaEnum
+
+	for _, line := range lines {
+		line = strings.TrimSpace(line)
+
+		if strings.HasPrefix(line, "@luaenum ") {
+			parts := strings.Fields(strings.TrimPrefix(line, "@luaenum "))
+			if !luaQualifiedName.MatchString(parts[0]) {
+				a.report(line, "invalid @luaenum name %q", parts[0])
+				enum = nil
+			} else {
+				enum = &LuaEnum{
+					Name:   parts[0],
+					Values: make([]*LuaEnumValue, 0),
+				}
+				if len(parts) > 1 {
+					enum.Description = strings.Join(parts[1:], " ")
+				}
+			}
+			continue
+		}
+
+		// Parse: @luavalue KEY VALUE DESCRIPTION (only if we found an enum)
+		if enum != nil && strings.HasPrefix(line, "@luavalue ") {
+			parts := strings.Fields(strings.TrimPrefix(line, "@luavalue "))
+			value := &LuaEnumValue{Name: parts[0]}
+			if len(parts) > 1 {
+				value.Value = parts[1]
+			}
+			if len(parts) > 2 {
+				value.Description = strings.Join(parts[2:], " ")
+			}
+			a.checkEnumValue(line, enum, value)
+			enum.Values = append(enum.Values, value)
+		}
+	}
+
+	if enum != nil && len(enum.Values) == 0 {
+		a.report("@luaenum "+enum.Name, "@luaenum %s: no @luavalue", enum.Name)
+	}
+
+	return enum
+}
+
+// extractAliases: extracts type aliases from @luaalias annotations
+// Format: @luaalias <Name> <type> <description>
+func (a *Analyzer) extractAliases(comment string) []*LuaAlias {
+	lines := strings.Split(comment, "\n")
+
+	var aliases []*LuaAlias
+
+	for _, line := range lines {
+		line = strings.TrimSpace(line)
+
+		if strings.HasPrefix(line, "@luaalias ") {
+			parts := strings.Fields(strings.TrimPrefix(line, "@luaalias "))
+			if len(parts) == 0 {
+				continue
+			}
+			if !luaQualifiedName.MatchString(parts[0]) {
+				a.report(line, "invalid @luaalias name %q", parts[0])
+				continue
+			}
+			if len(parts) < 2 {
+				a.report(line, "@luaalias %s: missing type", parts[0])
+				continue
+			}
+
+			alias := &LuaAlias{Name: parts[0], Type: parts[1]}
+			if len(parts) > 2 {
+				alias.Description = strings.Join(parts[2:], " ")
+			}
+			a.checkType(line, "@luaalias "+alias.Name, alias.Type)
+			aliases = append(aliases, alias)
+		}
+	}
+
+	return aliases
+}
+
 // methodResult: temporary struct to hold method extraction results
 type methodResult struct {
 	className string
@@ -441,7 +749,9 @@ func (a *Analyzer) extractMethod(comment string) *methodResult {
 		// @luamethod ClassName methodName
 		if strings.HasPrefix(line, "@luamethod ") {
 			parts := strings.Fields(strings.TrimPrefix(line, "@luamethod "))
-			if len(parts) >= 2 {
+			if len(parts) < 2 {
+				a.report(line, "@luamethod needs a class and a method name")
+			} else {
 				result = &methodResult{
 					className: parts[0],
 					method: &LuaMethod{
@@ -469,6 +779,9 @@ func (a *Analyzer) extractMethod(comment string) *methodResult {
 		if strings.HasPrefix(line, "@luaparam ") {
 			param := a.parseParam(line)
 			if param != nil {
+				if n := len(result.method.Params); n > 0 && result.method.Params[n-1].Vararg {
+					a.report(line, "@luaparam %s: parameters cannot follow the vararg parameter ...", param.Name)
+				}
 				result.method.Params = append(result.method.Params, param)
 			}
 		} else if strings.HasPrefix(line, "@luareturn ") {
@@ -481,11 +794,32 @@ func (a *Analyzer) extractMethod(comment string) *methodResult {
 			if annotation != "" {
 				result.method.CustomAnnotations = append(result.method.CustomAnnotations, annotation)
 			}
+		} else if strings.HasPrefix(line, "@luaoverload ") {
+			overload := strings.TrimSpace(strings.TrimPrefix(line, "@luaoverload "))
+			if err := validateFunctionType(overload); err != nil {
+				a.report(line, "invalid @luaoverload %q: %v", overload, err)
+				continue
+			}
+			result.method.Overloads = append(result.method.Overloads, overload)
+		} else if strings.HasPrefix(line, "@luageneric ") {
+			if generic := a.parseGeneric(line); generic != nil {
+				result.method.Generics = append(result.method.Generics, generic)
+			}
+		} else if strings.HasPrefix(line, "@luasee ") {
+			ref := strings.TrimSpace(strings.TrimPrefix(line, "@luasee "))
+			if a.comment != nil {
+				a.references = append(a.references, reference{line: line, comment: a.comment, target: strings.Fields(ref)[0]})
+			}
+			result.method.See = append(result.method.See, ref)
+		} else if line == "@luadeprecated" || strings.HasPrefix(line, "@luadeprecated ") {
+			result.method.Deprecated = true
+			result.method.DeprecationMessage = strings.TrimSpace(strings.TrimPrefix(line, "@luadeprecated"))
 		}
 	}
 
 	if result != nil {
 		result.method.Description = description.String()
+		result.method.Example = a.extractExample(comment)
 	}
 
 	return result
@@ -494,18 +828,38 @@ func (a *Analyzer) extractMethod(comment string) *methodResult {
 // parseParam: parses @luaparam annotation
 // Format: @luaparam <name> <type> <description>
 func (a *Analyzer) parseParam(line string) *LuaParam {
+	annotation := line
 	line = strings.TrimSpace(strings.TrimPrefix(line, "@luaparam "))
-	parts := strings.SplitN(line, " ", 3)
+	parts := strings.SplitN(line, " ", 2)
+	if len(parts) == 2 {
+		typ, description := cutLuaType(parts[1])
+		parts = append(parts[:1], typ)
+		if description != "" {
+			parts = append(parts, description)
+		}
+	}
 
 	if len(parts) < 2 {
+		a.report(annotation, "@luaparam %s: missing type", parts[0])
 		return nil
 	}
 
 	param := &LuaParam{
-		Name: parts[0],
-		Type: parts[1],
+		Name:   parts[0],
+		Type:   parts[1],
+		Vararg: parts[0] == "...",
 	}
 
+	if name, ok := strings.CutSuffix(param.Name, "?"); ok {
+		param.Name = name
+		param.Optional = true
+	}
+
+	if !param.Vararg && !luaIdentifier.MatchString(param.Name) {
+		a.report(annotation, "invalid @luaparam name %q", parts[0])
+	}
+	a.checkType(annotation, "@luaparam "+param.Name, param.Type)
+
 	if len(parts) >= 3 {
 		param.Description = parts[2]
 	}
@@ -513,12 +867,39 @@ func (a *Analyzer) parseParam(line string) *LuaParam {
 	return param
 }
 
+// parseGeneric: parses @luageneric annotation
+// Format: @luageneric <name> [constraint]
+func (a *Analyzer) parseGeneric(line string) *LuaGeneric {
+	parts := strings.Fields(strings.TrimPrefix(line, "@luageneric "))
+	if len(parts) == 0 {
+		return nil
+	}
+
+	if !luaIdentifier.MatchString(parts[0]) {
+		a.report(line, "invalid @luageneric name %q", parts[0])
+		return nil
+	}
+
+	generic := &LuaGeneric{Name: parts[0]}
+	if len(parts) > 1 {
+		generic.Constraint = strings.Join(parts[1:], " ")
+		a.checkType(line, "@luageneric "+generic.Name, generic.Constraint)
+	}
+
+	return generic
+}
+
 // parseReturn: parses @luareturn annotation
 // Format: @luareturn <type> <name> <description>
 // Or: @luareturn <type> <description> (name will be empty)
 func (a *Analyzer) parseReturn(line string) *LuaReturn {
+	annotation := line
 	line = strings.TrimSpace(strings.TrimPrefix(line, "@luareturn "))
-	parts := strings.SplitN(line, " ", 3)
+	typ, rest := cutLuaType(line)
+	parts := []string{typ}
+	if rest != "" {
+		parts = append(parts, strings.SplitN(rest, " ", 2)...)
+	}
 
 	if len(parts) < 1 {
 		return nil
@@ -527,6 +908,7 @@ func (a *Analyzer) parseReturn(line string) *LuaReturn {
 	ret := &LuaReturn{
 		Type: parts[0],
 	}
+	a.checkType(annotation, "@luareturn", ret.Type)
 
 	// If we have 3 parts, check if middle part is actually a name or part of description
 	// If we have 2 parts, it could be: <type> <description> OR <type> <name>
@@ -612,45 +994,17 @@ func (a *Analyzer) GenerateStubs() (string, error) {
 			sb.WriteString(fmt.Sprintf("---%s\n", annotation))
 		}
 
+		a.writeAliases(&sb, module.Aliases)
+
 		sb.WriteString(fmt.Sprintf("---@class %s\n", moduleName))
 		sb.WriteString(fmt.Sprintf("local %s = {}\n\n", moduleName))
 
 		// Generate function stubs
 		for _, fn := range module.Functions {
-			// Parameter annotations
-			for _, param := range fn.Params {
-				if param.Description != "" {
-					sb.WriteString(fmt.Sprintf("---@param %s %s %s\n", param.Name, param.Type, param.Description))
-				} else {
-					sb.WriteString(fmt.Sprintf("---@param %s %s\n", param.Name, param.Type))
-				}
-			}
-
-			// Return annotations
-			for _, ret := range fn.Returns {
-				if ret.Name != "" && ret.Description != "" {
-					sb.WriteString(fmt.Sprintf("---@return %s %s %s\n", ret.Type, ret.Name, ret.Description))
-				} else if ret.Name != "" {
-					sb.WriteString(fmt.Sprintf("---@return %s %s\n", ret.Type, ret.Name))
-				} else if ret.Description != "" {
-					sb.WriteString(fmt.Sprintf("---@return %s %s\n", ret.Type, ret.Description))
-				} else {
-					sb.WriteString(fmt.Sprintf("---@return %s\n", ret.Type))
-				}
-			}
-
-			// Function-level custom annotations
-			for _, annotation := range fn.CustomAnnotations {
-				sb.WriteString(fmt.Sprintf("---%s\n", annotation))
-			}
-
-			// Function signature
-			paramNames := make([]string, len(fn.Params))
-			for i, param := range fn.Params {
-				paramNames[i] = param.Name
-			}
-			sb.WriteString(fmt.Sprintf("function %s.%s(%s) end\n\n", moduleName, fn.Name, strings.Join(paramNames, ", ")))
+			a.writeFunctionStub(&sb, fn, moduleName)
 		}
+
+		a.generateEnumStubs(&sb, module, moduleName)
 	}
 
 	// Add single return statement at the end of the file
@@ -687,6 +1041,9 @@ func (a *Analyzer) GenerateModuleStub(moduleName string) (string, error) {
 	// Module-level custom annotations
 	a.writeAnnotations(&sb, module.CustomAnnotations)
 
+	// Type aliases
+	a.writeAliases(&sb, module.Aliases)
+
 	// Generate class stubs FIRST (must come before module for proper LSP resolution)
 	namespacedClasses := a.generateClassStubs(&sb, module, moduleName)
 
@@ -699,6 +1056,9 @@ func (a *Analyzer) GenerateModuleStub(moduleName string) (string, error) {
 	// Generate constant declarations
 	a.generateConstantStubs(&sb, module, moduleName)
 
+	// Generate enum tables
+	a.generateEnumStubs(&sb, module, moduleName)
+
 	// Assign namespaced classes to module fields (e.g., log.Logger = Logger)
 	for _, fieldName := range namespacedClasses {
 		sb.WriteString(fmt.Sprintf("%s.%s = %s\n\n", moduleName, fieldName, fieldName))
@@ -779,8 +1139,12 @@ func (a *Analyzer) getClassLocalName(className, moduleName string) string {
 // generateMethodStubs: generates method stub declarations for a class
 func (a *Analyzer) generateMethodStubs(sb *strings.Builder, methods []*LuaMethod, localVarName string) {
 	for _, method := range methods {
+		a.writeDeprecation(sb, method.Deprecated, method.DeprecationMessage)
+		a.writeGenericAnnotations(sb, method.Generics)
 		a.writeParamAnnotations(sb, method.Params, true)
 		a.writeReturnAnnotations(sb, method.Returns)
+		a.writeOverloadAnnotations(sb, method.Overloads)
+		a.writeSeeAnnotations(sb, method.See)
 		a.writeAnnotations(sb, method.CustomAnnotations)
 
 		paramNames := a.extractParamNames(method.Params, true)
@@ -794,14 +1158,64 @@ func (a *Analyzer) writeParamAnnotations(sb *strings.Builder, params []*LuaParam
 		if skipSelf && param.Name == "self" {
 			continue
 		}
+		name := param.Name
+		if param.Optional {
+			name += "?"
+		}
 		if param.Description != "" {
-			fmt.Fprintf(sb, "---@param %s %s %s\n", param.Name, param.Type, param.Description)
+			fmt.Fprintf(sb, "---@param %s %s %s\n", name, param.Type, param.Description)
 		} else {
-			fmt.Fprintf(sb, "---@param %s %s\n", param.Name, param.Type)
+			fmt.Fprintf(sb, "---@param %s %s\n", name, param.Type)
 		}
 	}
 }
 
+// writeGenericAnnotations: writes type parameter annotations
+func (a *Analyzer) writeGenericAnnotations(sb *strings.Builder, generics []*LuaGeneric) {
+	for _, generic := range generics {
+		if generic.Constraint != "" {
+			fmt.Fprintf(sb, "---@generic %s : %s\n", generic.Name, generic.Constraint)
+		} else {
+			fmt.Fprintf(sb, "---@generic %s\n", generic.Name)
+		}
+	}
+}
+
+// writeOverloadAnnotations: writes alternative signature annotations
+func (a *Analyzer) writeOverloadAnnotations(sb *strings.Builder, overloads []string) {
+	for _, overload := range overloads {
+		fmt.Fprintf(sb, "---@overload %s\n", overload)
+	}
+}
+
+// writeSeeAnnotations: writes cross-reference annotations
+func (a *Analyzer) writeSeeAnnotations(sb *strings.Builder, see []string) {
+	for _, ref := range see {
+		fmt.Fprintf(sb, "---@see %s\n", ref)
+	}
+}
+
+// writeDeprecation: writes the deprecation annotation, preceded by its message if any
+func (a *Analyzer) writeDeprecation(sb *strings.Builder, deprecated bool, message string) {
+	if !deprecated {
+		return
+	}
+	if message != "" {
+		fmt.Fprintf(sb, "--- Deprecated: %s\n", message)
+	}
+	sb.WriteString("---@deprecated\n")
+}
+
+// writeAliases: writes type alias declarations
+func (a *Analyzer) writeAliases(sb *strings.Builder, aliases []*LuaAlias) {
+	for _, alias := range aliases {
+		if alias.Description != "" {
+			fmt.Fprintf(sb, "--- %s\n", alias.Description)
+		}
+		fmt.Fprintf(sb, "---@alias %s %s\n\n", alias.Name, alias.Type)
+	}
+}
+
 // writeReturnAnnotations: writes return value annotations
 func (a *Analyzer) writeReturnAnnotations(sb *strings.Builder, returns []*LuaReturn) {
 	for _, ret := range returns {
@@ -847,18 +1261,24 @@ func (a *Analyzer) generateModuleDeclaration(sb *strings.Builder, module *LuaMod
 // generateFunctionStubs: generates function stub declarations for a module
 func (a *Analyzer) generateFunctionStubs(sb *strings.Builder, module *LuaModule, moduleName string) {
 	for _, fn := range module.Functions {
-		a.writeParamAnnotations(sb, fn.Params, false)
-		a.writeReturnAnnotations(sb, fn.Returns)
-		a.writeAnnotations(sb, fn.CustomAnnotations)
-
-		paramNames := make([]string, len(fn.Params))
-		for i, param := range fn.Params {
-			paramNames[i] = param.Name
-		}
-		fmt.Fprintf(sb, "function %s.%s(%s) end\n\n", moduleName, fn.Name, strings.Join(paramNames, ", "))
+		a.writeFunctionStub(sb, fn, moduleName)
 	}
 }
 
+// writeFunctionStub: writes the annotations and declaration of a module function
+func (a *Analyzer) writeFunctionStub(sb *strings.Builder, fn *LuaFunction, moduleName string) {
+	a.writeDeprecation(sb, fn.Deprecated, fn.DeprecationMessage)
+	a.writeGenericAnnotations(sb, fn.Generics)
+	a.writeParamAnnotations(sb, fn.Params, false)
+	a.writeReturnAnnotations(sb, fn.Returns)
+	a.writeOverloadAnnotations(sb, fn.Overloads)
+	a.writeSeeAnnotations(sb, fn.See)
+	a.writeAnnotations(sb, fn.CustomAnnotations)
+
+	paramNames := a.extractParamNames(fn.Params, false)
+	fmt.Fprintf(sb, "function %s.%s(%s) end\n\n", moduleName, fn.Name, strings.Join(paramNames, ", "))
+}
+
 // generateConstantStubs: generates constant declarations for a module
 func (a *Analyzer) generateConstantStubs(sb *strings.Builder, module *LuaModule, moduleName string) {
 	for _, cnst := range module.Constants {
@@ -870,3 +1290,21 @@ func (a *Analyzer) generateConstantStubs(sb *strings.Builder, module *LuaModule,
 		fmt.Fprintf(sb, "%s.%s = nil\n\n", moduleName, cnst.Name)
 	}
 }
+
+// generateEnumStubs: generates enum tables for a module (e.g., log.Level = { DEBUG = "debug" })
+func (a *Analyzer) generateEnumStubs(sb *strings.Builder, module *LuaModule, moduleName string) {
+	for _, enum := range module.Enums {
+		if enum.Description != "" {
+			fmt.Fprintf(sb, "--- %s\n", enum.Description)
+		}
+		fmt.Fprintf(sb, "---@enum %s\n", enum.Name)
+		fmt.Fprintf(sb, "%s.%s = {\n", moduleName, a.getClassLocalName(enum.Name, moduleName))
+		for _, value := range enum.Values {
+			if value.Description != "" {
+				fmt.Fprintf(sb, "    --- %s\n", value.Description)
+			}
+			fmt.Fprintf(sb, "    %s = %s,\n", value.Name, value.Value)
+		}
+		sb.WriteString("}\n\n")
+	}
+}
@@ -191,7 +191,7 @@ func testFunc(L *lua.LState) int {
 	}
 
 	a := NewAnalyzer()
-	if err := a.ScanDirectory(tmpDir); err != nil {
+	if _, err := a.ScanDirectory(tmpDir); err != nil {
 		t.Fatalf("ScanDirectory failed: %v", err)
 	}
 
@@ -269,7 +269,7 @@ func TestAnalyzer_RealModule(t *testing.T) {
 	a := NewAnalyzer()
 
 	// Test with the actual kubernetes module
-	if err := a.ScanDirectory("../modules/kubernetes"); err != nil {
+	if _, err := a.ScanDirectory("../modules/kubernetes"); err != nil {
 		t.Fatalf("ScanDirectory failed: %v", err)
 	}
 
@@ -324,7 +324,7 @@ func TestAnalyzer_RealModule(t *testing.T) {
 func TestAnalyzer_CustomAnnotations(t *testing.T) {
 	a := NewAnalyzer()
 
-	if err := a.ScanDirectory("testdata"); err != nil {
+	if _, err := a.ScanDirectory("testdata"); err != nil {
 		t.Fatalf("ScanDirectory failed: %v", err)
 	}
 
@@ -410,3 +410,63 @@ func verifyGeneratedStubs(t *testing.T, a *Analyzer) {
 		}
 	}
 }
+
+func TestAnalyzer_AnnotationVocabulary(t *testing.T) {
+	a := NewAnalyzer()
+
+	if _, err := a.ScanDirectory("testdata"); err != nil {
+		t.Fatalf("ScanDirectory failed: %v", err)
+	}
+
+	module, exists := a.modules["vocabulary"]
+	if !exists {
+		t.Fatal("Expected vocabulary module to be registered")
+	}
+
+	if len(module.Aliases) != 2 || module.Aliases[0].Type != "string|number" {
+		t.Errorf("Expected 2 aliases, got %+v", module.Aliases)
+	}
+
+	if len(module.Enums) != 1 || len(module.Enums[0].Values) != 3 {
+		t.Fatalf("Expected 1 enum with 3 values, got %+v", module.Enums)
+	}
+
+	get := module.Functions[0]
+	if get.Params[1].Name != "default" || !get.Params[1].Optional {
+		t.Errorf("Expected optional parameter default, got %+v", get.Params[1])
+	}
+
+	join := module.Functions[2]
+	if !join.Params[1].Vararg {
+		t.Errorf("Expected vararg parameter, got %+v", join.Params[1])
+	}
+
+	oldJoin := module.Functions[3]
+	if !oldJoin.Deprecated || oldJoin.DeprecationMessage != "Use vocabulary.join instead" {
+		t.Errorf("Expected old_join to be deprecated, got %v %q", oldJoin.Deprecated, oldJoin.DeprecationMessage)
+	}
+
+	stubs, err := a.GenerateModuleStub("vocabulary")
+	if err != nil {
+		t.Fatalf("GenerateModuleStub failed: %v", err)
+	}
+
+	expected := []string{
+		"--- Key of a stored value\n---@alias vocabulary.Key string|number\n",
+		"---@param default? any Value returned when the key is missing\n",
+		"---@overload fun(key: vocabulary.Key): any\n---@see vocabulary.first\nfunction vocabulary.get(key, default) end\n",
+		"---@generic T\n---@param list T[] The list\n",
+		"---@param ... string The strings to join\n",
+		"function vocabulary.join(sep, ...) end\n",
+		"--- Deprecated: Use vocabulary.join instead\n---@deprecated\n",
+		"---@deprecated\n---@generic K : vocabulary.Key\n",
+		"function Store:keys(filter) end\n",
+		"---@enum vocabulary.Level\nvocabulary.Level = {\n    --- Verbose output\n    DEBUG = \"debug\",\n    INFO = \"info\",\n",
+	}
+
+	for _, want := range expected {
+		if !strings.Contains(stubs, want) {
+			t.Errorf("Expected stub to contain:\n%s\ngot:\n%s", want, stubs)
+		}
+	}
+}
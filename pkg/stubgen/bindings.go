@@ -0,0 +1,686 @@
+// Copyright (c) 2024-2025 Thomas Maurice
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+package stubgen
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// BindingsFile: name of the Go file written by WriteBindings in every package exporting functions
+const BindingsFile = "luaexport.gen.go"
+
+// bindingsHeader: first line of the generated bindings, also used to recognize them
+const bindingsHeader = "// Code generated by stubgen -bindings. DO NOT EDIT."
+
+// bindingsExports: name of the generated map of Lua names to bindings
+const bindingsExports = "luaExports"
+
+// scalarBinding: how a Go type is read from a Lua argument and converted to a Lua value
+type scalarBinding struct {
+	luaType string
+	check   string // Argument reader, %s is the argument index
+	push    string // Lua value of a Go value, %s is the Go value
+	from    string // Go value of a table element, %s is the Lua value; empty when not allowed in tables
+	kind    string // lua.LValueType required for table elements, empty when any value is accepted
+	min     string // Smallest integer accepted before the conversion, empty when unbounded
+	max     string // Largest integer accepted before the conversion, empty when unbounded
+}
+
+// scalarBindings: the supported Go types, by name
+var scalarBindings = map[string]scalarBinding{
+	"string":  {"string", "L.CheckString(%s)", "lua.LString(%s)", "lua.LVAsString(%s)", "lua.LTString", "", ""},
+	"bool":    {"boolean", "L.CheckBool(%s)", "lua.LBool(%s)", "lua.LVAsBool(%s)", "lua.LTBool", "", ""},
+	"int":     {"integer", "L.CheckInt(%s)", "lua.LNumber(%s)", "int(lua.LVAsNumber(%s))", "lua.LTNumber", "", ""},
+	"int8":    {"integer", "int8(L.CheckInt(%s))", "lua.LNumber(%s)", "int8(lua.LVAsNumber(%s))", "lua.LTNumber", "-128", "127"},
+	"int16":   {"integer", "int16(L.CheckInt(%s))", "lua.LNumber(%s)", "int16(lua.LVAsNumber(%s))", "lua.LTNumber", "-32768", "32767"},
+	"int32":   {"integer", "int32(L.CheckInt(%s))", "lua.LNumber(%s)", "int32(lua.LVAsNumber(%s))", "lua.LTNumber", "-2147483648", "2147483647"},
+	"int64":   {"integer", "L.CheckInt64(%s)", "lua.LNumber(%s)", "int64(lua.LVAsNumber(%s))", "lua.LTNumber", "", ""},
+	"uint":    {"integer", "uint(L.CheckInt64(%s))", "lua.LNumber(%s)", "uint(lua.LVAsNumber(%s))", "lua.LTNumber", "0", ""},
+	"uint8":   {"integer", "uint8(L.CheckInt(%s))", "lua.LNumber(%s)", "uint8(lua.LVAsNumber(%s))", "lua.LTNumber", "0", "255"},
+	"uint16":  {"integer", "uint16(L.CheckInt(%s))", "lua.LNumber(%s)", "uint16(lua.LVAsNumber(%s))", "lua.LTNumber", "0", "65535"},
+	"uint32":  {"integer", "uint32(L.CheckInt64(%s))", "lua.LNumber(%s)", "uint32(lua.LVAsNumber(%s))", "lua.LTNumber", "0", "4294967295"},
+	"uint64":  {"integer", "uint64(L.CheckInt64(%s))", "lua.LNumber(%s)", "uint64(lua.LVAsNumber(%s))", "lua.LTNumber", "0", ""},
+	"float32": {"number", "float32(L.CheckNumber(%s))", "lua.LNumber(%s)", "float32(lua.LVAsNumber(%s))", "lua.LTNumber", "", ""},
+	"float64": {"number", "float64(L.CheckNumber(%s))", "lua.LNumber(%s)", "float64(lua.LVAsNumber(%s))", "lua.LTNumber", "", ""},
+}
+
+// gopherLuaBindings: the supported gopher-lua types, passed through unchanged
+var gopherLuaBindings = map[string]scalarBinding{
+	"LValue":     {"any", "L.CheckAny(%s)", "%s", "%s", "", "", ""},
+	"*LTable":    {"table", "L.CheckTable(%s)", "%s", "", "", "", ""},
+	"*LFunction": {"function", "L.CheckFunction(%s)", "%s", "", "", "", ""},
+	"*LUserData": {"userdata", "L.CheckUserData(%s)", "%s", "", "", "", ""},
+}
+
+// bindingType: a Go parameter or result type, a scalar or a slice or map of scalars
+type bindingType struct {
+	goType  string // Go source of the type
+	kind    string // "scalar", "slice" or "map"
+	element scalarBinding
+}
+
+// luaType: returns the Lua type annotation of the type
+func (t bindingType) luaType() string {
+	switch t.kind {
+	case "slice":
+		return t.element.luaType + "[]"
+	case "map":
+		return "table<string, " + t.element.luaType + ">"
+	}
+	return t.element.luaType
+}
+
+// bindingParam: a Lua-visible parameter of an exported function
+type bindingParam struct {
+	name     string // Go variable holding the argument
+	luaName  string
+	typ      bindingType
+	variadic bool
+}
+
+// exportedFunc: a Go function annotated with @luaexport
+type exportedFunc struct {
+	decl     *ast.FuncDecl
+	luaName  string
+	binding  string // Name of the generated LGFunction
+	state    bool   // Whether the function takes the *lua.LState as first parameter
+	params   []bindingParam
+	results  []bindingType
+	hasError bool // Whether the last result is an error
+}
+
+// reservedBindingNames: names used by the generated code, renamed when used by parameters
+var reservedBindingNames = regexp.MustCompile(`^(L|lua|err|i|k|v|r[0-9]+)$`)
+
+// GenerateBindings: generates the LGFunction bindings of the functions of the Go package in dir annotated
+// with @luaexport <name>. Parameters are read with the L.Check* function of their type and results pushed
+// in order; a trailing error result follows the value, err convention: nil (one per value) and the error
+// message on failure, the values and nil otherwise. Supported types are strings, booleans, numbers, slices
+// and string-keyed maps of those, lua.LValue, *lua.LTable, *lua.LFunction and *lua.LUserData, and a
+// leading *lua.LState parameter receives the state.
+//
+// The bindings are annotated with @luafunc, followed by the annotations of the Go function, the missing
+// @luaparam and @luareturn annotations being inferred from the Go types, so the stubs are generated from
+// them like from hand-written functions. A luaExports map of Lua names to bindings is generated for the
+// Loader. Returns nil content when the package exports nothing, and diagnostics for the annotated
+// functions that cannot be bound.
+func GenerateBindings(dir string) ([]byte, []Diagnostic, error) {
+	fset := token.NewFileSet()
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var files []*ast.File
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, ".go") || strings.HasSuffix(name, "_test.go") || name == BindingsFile {
+			continue
+		}
+
+		file, err := parser.ParseFile(fset, filepath.Join(dir, name), nil, parser.ParseComments)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to parse %s: %w", name, err)
+		}
+		files = append(files, file)
+	}
+
+	var diagnostics []Diagnostic
+	report := func(pos token.Pos, format string, args ...interface{}) {
+		diagnostics = append(diagnostics, Diagnostic{Pos: fset.Position(pos), Message: fmt.Sprintf(format, args...)})
+	}
+
+	declared := make(map[string]bool)
+	for _, file := range files {
+		for _, decl := range file.Decls {
+			if funcDecl, ok := decl.(*ast.FuncDecl); ok && funcDecl.Recv == nil {
+				declared[funcDecl.Name.Name] = true
+			}
+		}
+	}
+
+	var funcs []*exportedFunc
+	exported := make(map[string]bool)
+	for _, file := range files {
+		luaName := gopherLuaName(file)
+
+		for _, decl := range file.Decls {
+			funcDecl, ok := decl.(*ast.FuncDecl)
+			if !ok || funcDecl.Doc == nil {
+				continue
+			}
+
+			name := exportName(funcDecl.Doc.Text())
+			if name == "" {
+				continue
+			}
+
+			fn, err := bindFunction(funcDecl, name, luaName)
+			switch {
+			case err != nil:
+				report(funcDecl.Name.Pos(), "%s cannot be exported: %v", funcDecl.Name.Name, err)
+				continue
+			case exported[name]:
+				report(funcDecl.Name.Pos(), "%s is exported as %s, which is already exported", funcDecl.Name.Name, name)
+				continue
+			case declared[fn.binding]:
+				report(funcDecl.Name.Pos(), "%s cannot be exported: %s is already declared", funcDecl.Name.Name, fn.binding)
+				continue
+			}
+
+			exported[name] = true
+			funcs = append(funcs, fn)
+		}
+	}
+
+	if len(funcs) == 0 {
+		return nil, diagnostics, nil
+	}
+	sort.Slice(funcs, func(i, j int) bool { return funcs[i].luaName < funcs[j].luaName })
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%s\n\npackage %s\n\nimport lua \"%s\"\n\n", bindingsHeader, files[0].Name.Name, gopherLuaPath)
+
+	fmt.Fprintf(&sb, "// %s: the functions exported with @luaexport, by Lua name\n", bindingsExports)
+	fmt.Fprintf(&sb, "var %s = map[string]lua.LGFunction{\n", bindingsExports)
+	for _, fn := range funcs {
+		fmt.Fprintf(&sb, "%q: %s,\n", fn.luaName, fn.binding)
+	}
+	sb.WriteString("}\n")
+
+	for _, fn := range funcs {
+		sb.WriteString("\n")
+		writeBindingDoc(&sb, fn)
+		writeBindingBody(&sb, fn)
+	}
+
+	source, err := format.Source([]byte(sb.String()))
+	if err != nil {
+		return nil, nil, fmt.Errorf("formatting bindings of %s: %w", dir, err)
+	}
+
+	return source, diagnostics, nil
+}
+
+// WriteBindings: writes the bindings of every package matched by the patterns (see ScanPatterns) to its
+// BindingsFile, and removes the generated file of packages that no longer export anything.
+// Returns the files written, those already up to date being left untouched, and the diagnostics
+// of the functions that cannot be bound.
+func WriteBindings(patterns, excludes []string) ([]string, []Diagnostic, error) {
+	var dirs []string
+	seen := make(map[string]bool)
+
+	err := walkPatterns(patterns, excludes, func(path string) error {
+		dir := filepath.Dir(path)
+		if !seen[dir] {
+			seen[dir] = true
+			dirs = append(dirs, dir)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var written []string
+	var diagnostics []Diagnostic
+	for _, dir := range dirs {
+		source, found, err := GenerateBindings(dir)
+		if err != nil {
+			return nil, nil, err
+		}
+		diagnostics = append(diagnostics, found...)
+
+		path := filepath.Join(dir, BindingsFile)
+		if source == nil {
+			if err := removeBindings(path); err != nil {
+				return nil, nil, err
+			}
+			continue
+		}
+
+		// Unchanged bindings are left alone, so that watchers do not see them change
+		if existing, err := os.ReadFile(path); err == nil && bytes.Equal(existing, source) {
+			continue
+		}
+		if err := os.WriteFile(path, source, 0644); err != nil {
+			return nil, nil, err
+		}
+		written = append(written, path)
+	}
+
+	sortDiagnostics(diagnostics)
+
+	return written, diagnostics, nil
+}
+
+// removeBindings: removes a stale bindings file, unless it was not generated
+func removeBindings(path string) error {
+	content, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if !bytes.HasPrefix(content, []byte(bindingsHeader)) {
+		return nil
+	}
+	return os.Remove(path)
+}
+
+// exportName: extracts the Lua name of a @luaexport annotation
+func exportName(comment string) string {
+	for _, line := range strings.Split(comment, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) >= 2 && fields[0] == "@luaexport" {
+			return fields[1]
+		}
+	}
+	return ""
+}
+
+// gopherLuaName: returns the name gopher-lua is imported as in a file
+func gopherLuaName(file *ast.File) string {
+	for _, spec := range file.Imports {
+		if path, err := strconv.Unquote(spec.Path.Value); err == nil && path == gopherLuaPath {
+			if spec.Name != nil {
+				return spec.Name.Name
+			}
+			return "lua"
+		}
+	}
+	return "lua"
+}
+
+// bindFunction: checks that a function can be bound and resolves its parameter and result types
+func bindFunction(funcDecl *ast.FuncDecl, luaName, gopherLua string) (*exportedFunc, error) {
+	if funcDecl.Recv != nil {
+		return nil, fmt.Errorf("methods cannot be exported, use a function")
+	}
+	if funcDecl.Type.TypeParams != nil {
+		return nil, fmt.Errorf("generic functions cannot be exported")
+	}
+	if !luaIdentifier.MatchString(luaName) {
+		return nil, fmt.Errorf("%q is not a valid Lua name", luaName)
+	}
+
+	goName := funcDecl.Name.Name
+	fn := &exportedFunc{
+		decl:    funcDecl,
+		luaName: luaName,
+		binding: "lua" + string(unicode.ToUpper(rune(goName[0]))) + goName[1:],
+	}
+
+	for i, field := range funcDecl.Type.Params.List {
+		expr := field.Type
+		if i == 0 && isGopherLuaType(expr, gopherLua, "*LState") {
+			if len(field.Names) > 1 {
+				return nil, fmt.Errorf("only the first parameter can be the *lua.LState")
+			}
+			fn.state = true
+			continue
+		}
+
+		ellipsis, variadic := expr.(*ast.Ellipsis)
+		if variadic {
+			expr = ellipsis.Elt
+		}
+
+		typ, err := resolveBindingType(expr, gopherLua)
+		if err != nil {
+			return nil, err
+		}
+		if variadic && typ.kind != "scalar" {
+			return nil, fmt.Errorf("variadic parameters must be of a scalar type, got %s", typ.goType)
+		}
+
+		names := field.Names
+		if len(names) == 0 {
+			names = []*ast.Ident{ast.NewIdent("")}
+		}
+		for _, name := range names {
+			param := bindingParam{luaName: name.Name, typ: typ, variadic: variadic}
+			if variadic {
+				param.luaName = "..."
+			}
+			if name.Name == "" || name.Name == "_" {
+				param.name = fmt.Sprintf("arg%d", len(fn.params)+1)
+				if !variadic {
+					param.luaName = param.name
+				}
+			} else {
+				param.name = name.Name
+				if reservedBindingNames.MatchString(param.name) {
+					param.name += "Arg"
+				}
+			}
+			fn.params = append(fn.params, param)
+		}
+	}
+
+	if funcDecl.Type.Results != nil {
+		for _, field := range funcDecl.Type.Results.List {
+			count := len(field.Names)
+			if count == 0 {
+				count = 1
+			}
+
+			for n := 0; n < count; n++ {
+				if ident, ok := field.Type.(*ast.Ident); ok && ident.Name == "error" {
+					fn.hasError = true
+					continue
+				}
+				if fn.hasError {
+					return nil, fmt.Errorf("error must be the last result")
+				}
+
+				typ, err := resolveBindingType(field.Type, gopherLua)
+				if err != nil {
+					return nil, err
+				}
+				fn.results = append(fn.results, typ)
+			}
+		}
+	}
+
+	return fn, nil
+}
+
+// resolveBindingType: resolves a Go type expression to the way it is bound
+func resolveBindingType(expr ast.Expr, gopherLua string) (bindingType, error) {
+	goType := exprString(expr)
+
+	if scalar, ok := resolveScalar(expr, gopherLua); ok {
+		return bindingType{goType: goType, kind: "scalar", element: scalar}, nil
+	}
+
+	switch t := expr.(type) {
+	case *ast.ArrayType:
+		if element, ok := resolveScalar(t.Elt, gopherLua); ok && t.Len == nil && element.from != "" {
+			return bindingType{goType: goType, kind: "slice", element: element}, nil
+		}
+	case *ast.MapType:
+		key, ok := t.Key.(*ast.Ident)
+		if element, found := resolveScalar(t.Value, gopherLua); ok && found && key.Name == "string" && element.from != "" {
+			return bindingType{goType: goType, kind: "map", element: element}, nil
+		}
+	}
+
+	return bindingType{}, fmt.Errorf("unsupported type %s", goType)
+}
+
+// resolveScalar: resolves a Go type to a supported scalar type
+func resolveScalar(expr ast.Expr, gopherLua string) (scalarBinding, bool) {
+	if ident, ok := expr.(*ast.Ident); ok {
+		scalar, found := scalarBindings[ident.Name]
+		return scalar, found
+	}
+
+	for name, scalar := range gopherLuaBindings {
+		if isGopherLuaType(expr, gopherLua, name) {
+			return scalar, true
+		}
+	}
+
+	return scalarBinding{}, false
+}
+
+// isGopherLuaType: checks whether an expression is a gopher-lua type, e.g. "*LTable" for *lua.LTable
+func isGopherLuaType(expr ast.Expr, gopherLua, name string) bool {
+	if star, ok := expr.(*ast.StarExpr); ok {
+		if !strings.HasPrefix(name, "*") {
+			return false
+		}
+		expr, name = star.X, name[1:]
+	} else if strings.HasPrefix(name, "*") {
+		return false
+	}
+
+	sel, ok := expr.(*ast.SelectorExpr)
+	if !ok {
+		return false
+	}
+	pkg, ok := sel.X.(*ast.Ident)
+	return ok && pkg.Name == gopherLua && sel.Sel.Name == name
+}
+
+// exprString: returns the Go source of a type expression
+func exprString(expr ast.Expr) string {
+	var buf bytes.Buffer
+	if err := format.Node(&buf, token.NewFileSet(), expr); err != nil {
+		return fmt.Sprintf("%T", expr)
+	}
+	return buf.String()
+}
+
+// writeBindingDoc: writes the doc comment of a binding: the description and annotations of the Go function,
+// with @luaexport replaced by @luafunc and the missing @luaparam and @luareturn annotations inferred
+func writeBindingDoc(sb *strings.Builder, fn *exportedFunc) {
+	lines := strings.Split(strings.TrimRight(fn.decl.Doc.Text(), "\n"), "\n")
+
+	hasParams, hasReturns := false, false
+	for _, line := range lines {
+		fields := strings.Fields(line)
+		if len(fields) > 0 {
+			hasParams = hasParams || fields[0] == "@luaparam"
+			hasReturns = hasReturns || fields[0] == "@luareturn"
+		}
+	}
+
+	writeLine := func(line string) {
+		switch {
+		case line == "":
+			sb.WriteString("//\n")
+		case strings.HasPrefix(line, "\t"):
+			fmt.Fprintf(sb, "//%s\n", line)
+		default:
+			fmt.Fprintf(sb, "// %s\n", line)
+		}
+	}
+
+	for i, line := range lines {
+		if i == 0 {
+			writeLine(fn.binding + ": " + goNamePrefix.ReplaceAllString(line, ""))
+			continue
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) == 0 || fields[0] != "@luaexport" {
+			writeLine(line)
+			continue
+		}
+
+		writeLine("@luafunc " + fn.luaName)
+		if !hasParams {
+			for _, param := range fn.params {
+				writeLine(fmt.Sprintf("@luaparam %s %s", param.luaName, param.typ.luaType()))
+			}
+		}
+		if !hasReturns {
+			for _, result := range fn.results {
+				writeLine("@luareturn " + result.luaType())
+			}
+			if fn.hasError {
+				writeLine("@luareturn string|nil err Error message if the call failed")
+			}
+		}
+	}
+}
+
+// writeBindingBody: writes the LGFunction reading the arguments, calling the Go function and pushing its results
+func writeBindingBody(sb *strings.Builder, fn *exportedFunc) {
+	fmt.Fprintf(sb, "func %s(L *lua.LState) int {\n", fn.binding)
+
+	var args []string
+	if fn.state {
+		args = append(args, "L")
+	}
+	for i, param := range fn.params {
+		writeReadArgument(sb, param, i+1)
+		if param.variadic {
+			args = append(args, param.name+"...")
+		} else {
+			args = append(args, param.name)
+		}
+	}
+
+	results := make([]string, len(fn.results))
+	for i := range fn.results {
+		results[i] = fmt.Sprintf("r%d", i)
+	}
+	call := fmt.Sprintf("%s(%s)", fn.decl.Name.Name, strings.Join(args, ", "))
+	pushed := len(fn.results)
+
+	switch {
+	case fn.hasError:
+		fmt.Fprintf(sb, "%s := %s\n", strings.Join(append(results, "err"), ", "), call)
+		sb.WriteString("if err != nil {\n")
+		for range fn.results {
+			sb.WriteString("L.Push(lua.LNil)\n")
+		}
+		sb.WriteString("L.Push(lua.LString(err.Error()))\n")
+		fmt.Fprintf(sb, "return %d\n}\n", pushed+1)
+	case len(results) > 0:
+		fmt.Fprintf(sb, "%s := %s\n", strings.Join(results, ", "), call)
+	default:
+		sb.WriteString(call + "\n")
+	}
+
+	for i, result := range fn.results {
+		writePushResult(sb, result, results[i])
+	}
+	if fn.hasError {
+		sb.WriteString("L.Push(lua.LNil)\n")
+		pushed++
+	}
+
+	fmt.Fprintf(sb, "return %d\n}\n", pushed)
+}
+
+// writeReadArgument: writes the statements reading the argument at index into the parameter variable
+func writeReadArgument(sb *strings.Builder, param bindingParam, index int) {
+	element := param.typ.element
+	arg := strconv.Itoa(index)
+
+	switch {
+	case param.variadic:
+		fmt.Fprintf(sb, "%s := make([]%s, 0, L.GetTop())\n", param.name, param.typ.goType)
+		fmt.Fprintf(sb, "for i := %d; i <= L.GetTop(); i++ {\n", index)
+		writeRangeCheck(sb, element, "L.CheckInt64(i)", "i", false)
+		fmt.Fprintf(sb, "%s = append(%s, %s)\n}\n", param.name, param.name, fmt.Sprintf(element.check, "i"))
+
+	case param.typ.kind == "slice":
+		table := param.name + "Table"
+		fmt.Fprintf(sb, "%s := L.CheckTable(%d)\n", table, index)
+		fmt.Fprintf(sb, "%s := make(%s, 0, %s.Len())\n", param.name, param.typ.goType, table)
+		fmt.Fprintf(sb, "for i := 1; i <= %s.Len(); i++ {\n", table)
+		fmt.Fprintf(sb, "v := %s.RawGetInt(i)\n", table)
+		writeElementCheck(sb, element, arg)
+		fmt.Fprintf(sb, "%s = append(%s, %s)\n}\n", param.name, param.name, fmt.Sprintf(element.from, "v"))
+
+	case param.typ.kind == "map":
+		table := param.name + "Table"
+		fmt.Fprintf(sb, "%s := L.CheckTable(%d)\n", table, index)
+		fmt.Fprintf(sb, "%s := make(%s)\n", param.name, param.typ.goType)
+		fmt.Fprintf(sb, "%s.ForEach(func(k, v lua.LValue) {\n", table)
+		fmt.Fprintf(sb, "if k.Type() != lua.LTString {\nL.ArgError(%s, %q)\n}\n", arg, "string keys expected")
+		writeElementCheck(sb, element, arg)
+		fmt.Fprintf(sb, "%s[lua.LVAsString(k)] = %s\n})\n", param.name, fmt.Sprintf(element.from, "v"))
+
+	default:
+		writeRangeCheck(sb, element, "L.CheckInt64("+arg+")", arg, false)
+		fmt.Fprintf(sb, "%s := %s\n", param.name, fmt.Sprintf(element.check, arg))
+	}
+}
+
+// writeElementCheck: writes the statements rejecting a table element v of the wrong type or out of
+// the range of its Go type, which the LVAs* functions and the conversion would silently accept
+func writeElementCheck(sb *strings.Builder, element scalarBinding, index string) {
+	if element.kind == "" {
+		return
+	}
+
+	fmt.Fprintf(sb, "if v.Type() != %s {\nL.ArgError(%s, %q)\n}\n", element.kind, index, element.luaType+"s expected")
+	writeRangeCheck(sb, element, "lua.LVAsNumber(v)", index, true)
+}
+
+// writeRangeCheck: writes the statement rejecting an integer out of the range of its Go type,
+// which the conversion would truncate or turn into a huge number
+func writeRangeCheck(sb *strings.Builder, element scalarBinding, value, index string, plural bool) {
+	var conditions []string
+	if element.min != "" {
+		conditions = append(conditions, value+" < "+element.min)
+	}
+	if element.max != "" {
+		conditions = append(conditions, value+" > "+element.max)
+	}
+	if len(conditions) == 0 {
+		return
+	}
+
+	noun := "integer"
+	if plural {
+		noun = "integers"
+	}
+
+	message := "non-negative " + noun + " expected"
+	if element.max != "" {
+		message = fmt.Sprintf("%s between %s and %s expected", noun, element.min, element.max)
+	}
+
+	fmt.Fprintf(sb, "if %s {\nL.ArgError(%s, %q)\n}\n", strings.Join(conditions, " || "), index, message)
+}
+
+// writePushResult: writes the statements pushing a result
+func writePushResult(sb *strings.Builder, typ bindingType, value string) {
+	switch typ.kind {
+	case "slice":
+		table := value + "Table"
+		fmt.Fprintf(sb, "%s := L.CreateTable(len(%s), 0)\n", table, value)
+		fmt.Fprintf(sb, "for _, v := range %s {\n", value)
+		fmt.Fprintf(sb, "%s.Append(%s)\n}\n", table, fmt.Sprintf(typ.element.push, "v"))
+		fmt.Fprintf(sb, "L.Push(%s)\n", table)
+
+	case "map":
+		table := value + "Table"
+		fmt.Fprintf(sb, "%s := L.CreateTable(0, len(%s))\n", table, value)
+		fmt.Fprintf(sb, "for k, v := range %s {\n", value)
+		fmt.Fprintf(sb, "%s.RawSetString(k, %s)\n}\n", table, fmt.Sprintf(typ.element.push, "v"))
+		fmt.Fprintf(sb, "L.Push(%s)\n", table)
+
+	default:
+		fmt.Fprintf(sb, "L.Push(%s)\n", fmt.Sprintf(typ.element.push, value))
+	}
+}
@@ -0,0 +1,267 @@
+// Copyright (c) 2024-2025 Thomas Maurice
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+package stubgen
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// bindingsTestCode: plain Go functions exported to Lua
+const bindingsTestCode = `package text
+
+import (
+	"errors"
+	"strings"
+
+	lua "github.com/yuin/gopher-lua"
+)
+
+// Loader: creates the text module
+//
+// @luamodule text
+func Loader(L *lua.LState) int {
+	L.Push(L.SetFuncs(L.NewTable(), luaExports))
+	return 1
+}
+
+// Repeat: repeats a string
+//
+// @luaexport repeat
+// @luaparam s string The string to repeat
+// @luaparam count integer How many times
+// @luareturn string repeated The repeated string
+//
+// Example:
+//
+//	print(text.repeat("ab", 2))  -- prints "abab"
+func Repeat(s string, count int) string {
+	return strings.Repeat(s, count)
+}
+
+// parse: parses a ratio
+//
+// @luaexport parse_ratio
+func parse(value string, strict bool) (float64, int, error) {
+	return 0, 0, errors.New("not implemented")
+}
+
+// words: splits words
+//
+// @luaexport words
+func words(s string, seps ...string) []string {
+	return nil
+}
+
+// counts: counts the words of a table
+//
+// @luaexport counts
+func counts(L *lua.LState, words []string, weights map[string]float64, opts *lua.LTable) map[string]int {
+	return nil
+}
+
+// check: validates a value
+//
+// @luaexport check
+func check(_ lua.LValue) error {
+	return nil
+}
+
+// take: integers out of the range of their Go type are rejected
+//
+// @luaexport take
+func take(n uint, offset int8, sizes []uint32, limits map[string]uint8, extra ...uint64) {}
+
+// reset: takes no argument
+//
+// @luaexport reset
+func reset() {}
+
+// channel: cannot be bound
+//
+// @luaexport channel
+func channel(c chan int) {}
+
+// duplicate: exported under a name already taken
+//
+// @luaexport words
+func duplicate() {}
+
+type builder struct{}
+
+// add: methods cannot be bound
+//
+// @luaexport add
+func (b *builder) add() {}
+`
+
+func TestGenerateBindings(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "text.go"), []byte(bindingsTestCode), 0644); err != nil {
+		t.Fatalf("Failed to write test file: %v", err)
+	}
+
+	source, diagnostics, err := GenerateBindings(dir)
+	if err != nil {
+		t.Fatalf("GenerateBindings failed: %v", err)
+	}
+
+	expectedDiagnostics := []string{
+		"channel cannot be exported: unsupported type chan int",
+		"duplicate is exported as words, which is already exported",
+		"add cannot be exported: methods cannot be exported, use a function",
+	}
+	if len(diagnostics) != len(expectedDiagnostics) {
+		t.Fatalf("Expected diagnostics %v, got %v", expectedDiagnostics, diagnostics)
+	}
+	for i, want := range expectedDiagnostics {
+		if diagnostics[i].Message != want {
+			t.Errorf("Expected diagnostic %q, got %q", want, diagnostics[i].Message)
+		}
+	}
+
+	generated := string(source)
+	for _, want := range []string{
+		"// Code generated by stubgen -bindings. DO NOT EDIT.\n\npackage text\n",
+		"var luaExports = map[string]lua.LGFunction{\n\t\"check\":       luaCheck,\n\t\"counts\":      luaCounts,\n\t\"parse_ratio\": luaParse,\n\t\"repeat\":      luaRepeat,\n\t\"reset\":       luaReset,\n\t\"take\":        luaTake,\n\t\"words\":       luaWords,\n}",
+
+		// Annotations of the Go function are kept, the description and example too
+		"// luaRepeat: repeats a string\n//\n// @luafunc repeat\n// @luaparam s string The string to repeat\n// @luaparam count integer How many times\n// @luareturn string repeated The repeated string\n//\n// Example:\n//\n//\tprint(text.repeat(\"ab\", 2))  -- prints \"abab\"\nfunc luaRepeat(L *lua.LState) int {\n\ts := L.CheckString(1)\n\tcount := L.CheckInt(2)\n\tr0 := Repeat(s, count)\n\tL.Push(lua.LString(r0))\n\treturn 1\n}",
+
+		// value, err convention with inferred annotations
+		"// @luafunc parse_ratio\n// @luaparam value string\n// @luaparam strict boolean\n// @luareturn number\n// @luareturn integer\n// @luareturn string|nil err Error message if the call failed\n",
+		"\tvalue := L.CheckString(1)\n\tstrict := L.CheckBool(2)\n\tr0, r1, err := parse(value, strict)\n\tif err != nil {\n\t\tL.Push(lua.LNil)\n\t\tL.Push(lua.LNil)\n\t\tL.Push(lua.LString(err.Error()))\n\t\treturn 3\n\t}\n\tL.Push(lua.LNumber(r0))\n\tL.Push(lua.LNumber(r1))\n\tL.Push(lua.LNil)\n\treturn 3\n",
+
+		// Variadic parameters and slices
+		"// @luaparam s string\n// @luaparam ... string\n// @luareturn string[]\n",
+		"\tseps := make([]string, 0, L.GetTop())\n\tfor i := 2; i <= L.GetTop(); i++ {\n\t\tseps = append(seps, L.CheckString(i))\n\t}\n\tr0 := words(s, seps...)\n\tr0Table := L.CreateTable(len(r0), 0)\n\tfor _, v := range r0 {\n\t\tr0Table.Append(lua.LString(v))\n\t}\n\tL.Push(r0Table)\n\treturn 1\n",
+
+		// State, tables and maps
+		"// @luaparam words string[]\n// @luaparam weights table<string, number>\n// @luaparam opts table\n// @luareturn table<string, integer>\n",
+		"\twordsTable := L.CheckTable(1)\n\twords := make([]string, 0, wordsTable.Len())\n\tfor i := 1; i <= wordsTable.Len(); i++ {\n\t\tv := wordsTable.RawGetInt(i)\n\t\tif v.Type() != lua.LTString {\n\t\t\tL.ArgError(1, \"strings expected\")\n\t\t}\n\t\twords = append(words, lua.LVAsString(v))\n\t}\n",
+		"\tweights := make(map[string]float64)\n\tweightsTable.ForEach(func(k, v lua.LValue) {\n\t\tif k.Type() != lua.LTString {\n\t\t\tL.ArgError(2, \"string keys expected\")\n\t\t}\n\t\tif v.Type() != lua.LTNumber {\n\t\t\tL.ArgError(2, \"numbers expected\")\n\t\t}\n\t\tweights[lua.LVAsString(k)] = float64(lua.LVAsNumber(v))\n\t})\n\topts := L.CheckTable(3)\n\tr0 := counts(L, words, weights, opts)\n",
+		"\tfor k, v := range r0 {\n\t\tr0Table.RawSetString(k, lua.LNumber(v))\n\t}\n",
+
+		// Error only, unnamed parameter
+		"// @luaparam arg1 any\n// @luareturn string|nil err Error message if the call failed\nfunc luaCheck(L *lua.LState) int {\n\targ1 := L.CheckAny(1)\n\terr := check(arg1)\n\tif err != nil {\n\t\tL.Push(lua.LString(err.Error()))\n\t\treturn 1\n\t}\n\tL.Push(lua.LNil)\n\treturn 1\n}",
+
+		"func luaReset(L *lua.LState) int {\n\treset()\n\treturn 0\n}",
+
+		// Integers out of the range of their Go type are rejected instead of wrapping around
+		"\tif L.CheckInt64(1) < 0 {\n\t\tL.ArgError(1, \"non-negative integer expected\")\n\t}\n\tn := uint(L.CheckInt64(1))\n",
+		"\tif L.CheckInt64(2) < -128 || L.CheckInt64(2) > 127 {\n\t\tL.ArgError(2, \"integer between -128 and 127 expected\")\n\t}\n\toffset := int8(L.CheckInt(2))\n",
+		"\t\tv := sizesTable.RawGetInt(i)\n\t\tif v.Type() != lua.LTNumber {\n\t\t\tL.ArgError(3, \"integers expected\")\n\t\t}\n\t\tif lua.LVAsNumber(v) < 0 || lua.LVAsNumber(v) > 4294967295 {\n\t\t\tL.ArgError(3, \"integers between 0 and 4294967295 expected\")\n\t\t}\n",
+		"\t\tif lua.LVAsNumber(v) < 0 || lua.LVAsNumber(v) > 255 {\n\t\t\tL.ArgError(4, \"integers between 0 and 255 expected\")\n\t\t}\n\t\tlimits[lua.LVAsString(k)] = uint8(lua.LVAsNumber(v))\n",
+		"\tfor i := 5; i <= L.GetTop(); i++ {\n\t\tif L.CheckInt64(i) < 0 {\n\t\t\tL.ArgError(i, \"non-negative integer expected\")\n\t\t}\n\t\textra = append(extra, uint64(L.CheckInt64(i)))\n\t}\n",
+	} {
+		if !strings.Contains(generated, want) {
+			t.Errorf("Expected bindings to contain:\n%s\n\nGot:\n%s", want, generated)
+		}
+	}
+
+	// The stubs are generated from the bindings
+	if err := os.WriteFile(filepath.Join(dir, BindingsFile), source, 0644); err != nil {
+		t.Fatalf("Failed to write bindings: %v", err)
+	}
+
+	a := NewAnalyzer()
+	scanDiagnostics, err := a.ScanDirectory(dir)
+	if err != nil {
+		t.Fatalf("ScanDirectory failed: %v", err)
+	}
+	if len(scanDiagnostics) != 0 {
+		t.Errorf("Unexpected diagnostics: %v", scanDiagnostics)
+	}
+
+	stub, err := a.GenerateModuleStub("text")
+	if err != nil {
+		t.Fatalf("GenerateModuleStub failed: %v", err)
+	}
+	if !strings.Contains(stub, "---@param s string\n---@param ... string\n---@return string[]\nfunction text.words(s, ...) end") {
+		t.Errorf("Expected a stub for words, got:\n%s", stub)
+	}
+}
+
+func TestWriteBindings(t *testing.T) {
+	root := t.TempDir()
+	dir := filepath.Join(root, "text")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "text.go"), []byte(bindingsTestCode), 0644); err != nil {
+		t.Fatalf("Failed to write test file: %v", err)
+	}
+
+	written, _, err := WriteBindings([]string{root + "/..."}, nil)
+	if err != nil {
+		t.Fatalf("WriteBindings failed: %v", err)
+	}
+	if len(written) != 1 || written[0] != filepath.Join(dir, BindingsFile) {
+		t.Fatalf("Expected %s to be written, got %v", filepath.Join(dir, BindingsFile), written)
+	}
+
+	// Once nothing is exported anymore, the generated file is removed
+	if err := os.WriteFile(filepath.Join(dir, "text.go"), []byte("package text\n"), 0644); err != nil {
+		t.Fatalf("Failed to write test file: %v", err)
+	}
+	if written, _, err = WriteBindings([]string{root + "/..."}, nil); err != nil || len(written) != 0 {
+		t.Fatalf("Expected nothing to be written, got %v, %v", written, err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, BindingsFile)); !os.IsNotExist(err) {
+		t.Errorf("Expected %s to be removed, got %v", BindingsFile, err)
+	}
+}
+
+func TestGenerateBindingsModules(t *testing.T) {
+	// The generated bindings of the bundled modules must be up to date
+	written := 0
+	err := walkPatterns([]string{"../modules/..."}, nil, func(path string) error {
+		if filepath.Base(path) != BindingsFile {
+			return nil
+		}
+		written++
+
+		expected, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+
+		source, diagnostics, err := GenerateBindings(filepath.Dir(path))
+		if err != nil {
+			return err
+		}
+		for _, d := range diagnostics {
+			t.Errorf("Unexpected diagnostic: %s", d)
+		}
+		if string(source) != string(expected) {
+			t.Errorf("%s is out of date, run go generate", path)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Failed to walk the modules: %v", err)
+	}
+
+	if written == 0 {
+		t.Error("Expected at least one module with generated bindings")
+	}
+}
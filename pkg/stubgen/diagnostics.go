@@ -0,0 +1,200 @@
+// Copyright (c) 2024-2025 Thomas Maurice
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+package stubgen
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"strings"
+)
+
+// annotationTags: annotations understood by the analyzer, and whether they need arguments
+var annotationTags = map[string]bool{
+	"@luamodule":     true,
+	"@luafunc":       true,
+	"@luaparam":      true,
+	"@luareturn":     true,
+	"@luaconst":      true,
+	"@luaclass":      true,
+	"@luafield":      true,
+	"@luamethod":     true,
+	"@luaannotation": true,
+	"@luaoverload":   true,
+	"@luadeprecated": false,
+	"@luageneric":    true,
+	"@luasee":        true,
+	"@luaenum":       true,
+	"@luavalue":      true,
+	"@luaalias":      true,
+	"@luaexport":     true,
+}
+
+// reference: a @luasee target, resolved once every module has been scanned
+type reference struct {
+	line    string
+	comment *ast.CommentGroup
+	target  string
+}
+
+// report: records a diagnostic at the comment line containing an annotation.
+// Nothing is recorded when no comment is being extracted, e.g. while checking signatures.
+func (a *Analyzer) report(line string, format string, args ...interface{}) {
+	if a.comment == nil {
+		return
+	}
+	a.reportAt(a.comment, line, format, args...)
+}
+
+// reportAt: records a diagnostic at a line of a comment group, once
+func (a *Analyzer) reportAt(comment *ast.CommentGroup, line string, format string, args ...interface{}) {
+	d := Diagnostic{
+		Pos:     a.annotationPosition(comment, line),
+		Message: fmt.Sprintf(format, args...),
+	}
+
+	// Doc comments are visited both as declarations and as standalone comments
+	for _, existing := range a.diagnostics {
+		if existing == d {
+			return
+		}
+	}
+	a.diagnostics = append(a.diagnostics, d)
+}
+
+// annotationPosition: returns the position of a line within a comment group
+func (a *Analyzer) annotationPosition(comment *ast.CommentGroup, line string) token.Position {
+	for _, c := range comment.List {
+		for i, text := range strings.Split(c.Text, "\n") {
+			idx := strings.Index(text, line)
+			if idx < 0 {
+				continue
+			}
+
+			pos := a.fset.Position(c.Slash)
+			if i == 0 {
+				pos.Column += idx
+			} else {
+				pos.Line += i
+				pos.Column = idx + 1
+			}
+			return pos
+		}
+	}
+
+	return a.fset.Position(comment.Pos())
+}
+
+// checkTags: reports unknown @lua annotations, and known ones missing their arguments
+func (a *Analyzer) checkTags(file *ast.File) {
+	for _, group := range file.Comments {
+		for _, line := range strings.Split(group.Text(), "\n") {
+			line = strings.TrimSpace(line)
+			if !strings.HasPrefix(line, "@lua") {
+				continue
+			}
+
+			tag := strings.Fields(line)[0]
+			needsArgs, known := annotationTags[tag]
+			switch {
+			case !known:
+				a.reportAt(group, line, "unknown annotation %s", tag)
+			case needsArgs && tag == line:
+				a.reportAt(group, line, "%s has no arguments", tag)
+			}
+		}
+	}
+}
+
+// checkType: reports a malformed type expression
+func (a *Analyzer) checkType(line, what, expr string) {
+	if err := validateLuaType(expr); err != nil {
+		a.report(line, "%s: invalid type %q: %v", what, expr, err)
+	}
+}
+
+// checkEnumValue: reports a malformed enum member
+func (a *Analyzer) checkEnumValue(line string, enum *LuaEnum, value *LuaEnumValue) {
+	if !luaIdentifier.MatchString(value.Name) {
+		a.report(line, "@luaenum %s: invalid @luavalue name %q", enum.Name, value.Name)
+	}
+
+	for _, existing := range enum.Values {
+		if existing.Name == value.Name {
+			a.report(line, "@luaenum %s: duplicate @luavalue %s", enum.Name, value.Name)
+		}
+	}
+
+	if !isLuaLiteral(value.Value) {
+		a.report(line, "@luaenum %s: @luavalue %s: %q is not a string, number or boolean literal", enum.Name, value.Name, value.Value)
+	}
+}
+
+// resolveReferences: reports @luasee targets that are not a known symbol or a URL
+func (a *Analyzer) resolveReferences() {
+	known := a.knownSymbols()
+
+	for _, ref := range a.references {
+		if !strings.Contains(ref.target, "://") && !known[ref.target] {
+			a.reportAt(ref.comment, ref.line, "@luasee %s: unknown target", ref.target)
+		}
+	}
+}
+
+// isLuaLiteral: checks whether a token is a string, number or boolean literal
+func isLuaLiteral(token string) bool {
+	if token == "true" || token == "false" || isLuaNumber(token) {
+		return true
+	}
+	if len(token) >= 2 && (token[0] == '"' || token[0] == '\'') && token[len(token)-1] == token[0] {
+		return !strings.ContainsRune(token[1:len(token)-1], rune(token[0]))
+	}
+	return false
+}
+
+// knownSymbols: returns the names @luasee can refer to: modules, functions, constants,
+// classes, methods (Class:method), enums and aliases
+func (a *Analyzer) knownSymbols() map[string]bool {
+	known := make(map[string]bool)
+
+	for moduleName, module := range a.modules {
+		known[moduleName] = true
+		for _, fn := range module.Functions {
+			known[moduleName+"."+fn.Name] = true
+		}
+		for _, cnst := range module.Constants {
+			known[moduleName+"."+cnst.Name] = true
+		}
+		for _, class := range module.Classes {
+			known[class.Name] = true
+			for _, method := range class.Methods {
+				known[class.Name+":"+method.Name] = true
+			}
+		}
+		for _, enum := range module.Enums {
+			known[enum.Name] = true
+		}
+		for _, alias := range module.Aliases {
+			known[alias.Name] = true
+		}
+	}
+
+	return known
+}
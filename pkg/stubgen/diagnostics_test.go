@@ -0,0 +1,149 @@
+// Copyright (c) 2024-2025 Thomas Maurice
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+package stubgen
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestAnalyzer_Diagnostics(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	testCode := `package test
+
+// Loader: creates the broken module
+//
+// @luamodule broken
+func Loader(L *lua.LState) int {
+	return 1
+}
+
+// @luaalias broken.Mode "fast"|
+// @luaenum other.Level
+// @luavalue DEBUG debug
+// @luavalue DEBUG "debug"
+
+// @luaconst VERSION
+
+// badFunc: a function with invalid annotations
+//
+// @luafunc bad
+// @luaparam name
+// @luaparam ... string Values
+// @luaparam 1st number Not an identifier
+// @luareturn table<string Unclosed
+// @luageneric T-
+// @luaoverload string
+// @luasee broken.missing
+// @luasee broken.good
+// @luasee https://example.com/docs
+// @luadeprecate
+func badFunc(L *lua.LState) int {
+	return 0
+}
+
+// goodFunc: a function with valid annotations
+//
+// @luafunc good
+// @luaparam name? string The name
+func goodFunc(L *lua.LState) int {
+	return 0
+}
+
+/*
+badMethod: a method documented in a block comment
+
+@luamethod broken.Thing
+@luafield
+*/
+func badMethod(L *lua.LState) int {
+	return 0
+}
+`
+
+	if err := os.WriteFile(filepath.Join(tmpDir, "broken.go"), []byte(testCode), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	a := NewAnalyzer()
+	diagnostics, err := a.ScanDirectory(tmpDir)
+	if err != nil {
+		t.Fatalf("ScanDirectory failed: %v", err)
+	}
+
+	expected := []string{
+		`10:4: @luaalias broken.Mode: invalid type "\"fast\"|": unexpected end of type`,
+		"11:4: @luaenum other.Level: qualified enum names must start with broken.",
+		`12:4: @luaenum other.Level: @luavalue DEBUG: "debug" is not a string, number or boolean literal`,
+		"13:4: @luaenum other.Level: duplicate @luavalue DEBUG",
+		"15:4: @luaconst VERSION: missing type",
+		"20:4: @luaparam name: missing type",
+		`22:4: invalid @luaparam name "1st"`,
+		"22:4: @luaparam 1st: parameters cannot follow the vararg parameter ...",
+		`23:4: @luareturn: invalid type "table<string": expected ">" at end of type`,
+		`24:4: invalid @luageneric name "T-"`,
+		`25:4: invalid @luaoverload "string": expected a fun(...) type`,
+		"26:4: @luasee broken.missing: unknown target",
+		"29:4: unknown annotation @luadeprecate",
+		"45:1: @luamethod needs a class and a method name",
+		"46:1: @luafield has no arguments",
+	}
+
+	if len(diagnostics) != len(expected) {
+		for _, d := range diagnostics {
+			t.Log(d)
+		}
+		t.Fatalf("Expected %d diagnostics, got %d", len(expected), len(diagnostics))
+	}
+
+	for i, want := range expected {
+		got := diagnostics[i]
+		if filepath.Base(got.Pos.Filename) != "broken.go" {
+			t.Errorf("Expected diagnostic in broken.go, got %s", got.Pos.Filename)
+		}
+		if got := got.String()[len(got.Pos.Filename)+1:]; got != want {
+			t.Errorf("Diagnostic %d:\nexpected %s\ngot      %s", i, want, got)
+		}
+	}
+
+	// Malformed annotations are skipped, the rest of the module is still extracted
+	module := a.modules["broken"]
+	if module == nil || len(module.Functions) != 2 {
+		t.Fatalf("Expected module broken with 2 functions, got %+v", module)
+	}
+	if good := module.Functions[1]; len(good.Params) != 1 || !good.Params[0].Optional {
+		t.Errorf("Expected good to have an optional parameter, got %+v", good.Params)
+	}
+}
+
+func TestAnalyzer_DiagnosticsModules(t *testing.T) {
+	a := NewAnalyzer()
+
+	diagnostics, err := a.ScanDirectory("../modules")
+	if err != nil {
+		t.Fatalf("ScanDirectory failed: %v", err)
+	}
+
+	for _, d := range diagnostics {
+		t.Errorf("Unexpected diagnostic in bundled modules: %s", d)
+	}
+}
@@ -0,0 +1,463 @@
+// Copyright (c) 2024-2025 Thomas Maurice
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package stubgen
+
+import (
+	"fmt"
+	htmltemplate "html/template"
+	"regexp"
+	"sort"
+	"strings"
+	texttemplate "text/template"
+)
+
+// DocFormat: output format of the API reference
+type DocFormat string
+
+const (
+	// DocFormatMarkdown: one Markdown page per module
+	DocFormatMarkdown DocFormat = "markdown"
+	// DocFormatHTML: one standalone HTML page per module
+	DocFormatHTML DocFormat = "html"
+)
+
+// Extension: returns the file extension of pages in this format
+func (f DocFormat) Extension() string {
+	if f == DocFormatHTML {
+		return ".html"
+	}
+	return ".md"
+}
+
+// ParseDocFormat: parses a documentation format name
+func ParseDocFormat(name string) (DocFormat, error) {
+	switch DocFormat(name) {
+	case DocFormatMarkdown, DocFormatHTML:
+		return DocFormat(name), nil
+	case "md":
+		return DocFormatMarkdown, nil
+	}
+	return "", fmt.Errorf("unknown documentation format %q (expected markdown or html)", name)
+}
+
+// goNamePrefix: the "goName: " prefix of Go doc comments, not relevant to Lua users
+var goNamePrefix = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*: `)
+
+// docPage: the view model of a module reference page
+type docPage struct {
+	Module    string
+	Example   string
+	Functions []docFunction
+	Classes   []docClass
+	Constants []*LuaConst
+}
+
+// docFunction: the view model of a function or method
+type docFunction struct {
+	Heading     string // Markdown heading level, methods are nested in their class
+	Anchor      string
+	Name        string
+	Signature   string
+	Description string
+	Params      []*LuaParam
+	Returns     []*LuaReturn
+	Example     string
+}
+
+// docClass: the view model of a class
+type docClass struct {
+	Anchor      string
+	Name        string
+	Description string
+	Fields      []*LuaField
+	Methods     []docFunction
+}
+
+// docIndex: the view model of the index page
+type docIndex struct {
+	Modules []docIndexEntry
+}
+
+// docIndexEntry: a module listed on the index page
+type docIndexEntry struct {
+	Name      string
+	Link      string
+	Functions int
+	Classes   int
+}
+
+// GenerateModuleDocs: generates the API reference page of a module in the given format.
+// The page lists the functions (with their signature, parameters, return values and
+// example), the classes with their fields and methods, and the constants of the module.
+func (a *Analyzer) GenerateModuleDocs(moduleName string, format DocFormat) (string, error) {
+	module, exists := a.modules[moduleName]
+	if !exists {
+		return "", fmt.Errorf("module %s not found", moduleName)
+	}
+
+	page := docPage{
+		Module:    moduleName,
+		Example:   module.Example,
+		Constants: module.Constants,
+	}
+
+	for _, fn := range module.Functions {
+		page.Functions = append(page.Functions, docFunction{
+			Heading:     "###",
+			Anchor:      anchorName(moduleName + "." + fn.Name),
+			Name:        moduleName + "." + fn.Name,
+			Signature:   luaSignature(moduleName+"."+fn.Name, fn.Params, fn.Returns),
+			Description: docDescription(fn.Description),
+			Params:      fn.Params,
+			Returns:     fn.Returns,
+			Example:     fn.Example,
+		})
+	}
+
+	for _, class := range module.Classes {
+		dc := docClass{
+			Anchor:      anchorName(class.Name),
+			Name:        class.Name,
+			Description: docDescription(class.Description),
+			Fields:      class.Fields,
+		}
+
+		for _, method := range class.Methods {
+			var params []*LuaParam
+			for _, param := range method.Params {
+				if param.Name != "self" {
+					params = append(params, param)
+				}
+			}
+
+			dc.Methods = append(dc.Methods, docFunction{
+				Heading:     "####",
+				Anchor:      anchorName(class.Name + ":" + method.Name),
+				Name:        class.Name + ":" + method.Name,
+				Signature:   luaSignature(class.Name+":"+method.Name, params, method.Returns),
+				Description: docDescription(method.Description),
+				Params:      params,
+				Returns:     method.Returns,
+				Example:     method.Example,
+			})
+		}
+
+		sort.Slice(dc.Methods, func(i, j int) bool { return dc.Methods[i].Name < dc.Methods[j].Name })
+		page.Classes = append(page.Classes, dc)
+	}
+
+	sort.Slice(page.Functions, func(i, j int) bool { return page.Functions[i].Name < page.Functions[j].Name })
+	sort.Slice(page.Classes, func(i, j int) bool { return page.Classes[i].Name < page.Classes[j].Name })
+
+	return renderDocs(format, "page", page)
+}
+
+// GenerateDocsIndex: generates an index page linking to the page of every module
+func (a *Analyzer) GenerateDocsIndex(format DocFormat) (string, error) {
+	var index docIndex
+
+	names := make([]string, 0, len(a.modules))
+	for name := range a.modules {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		module := a.modules[name]
+		index.Modules = append(index.Modules, docIndexEntry{
+			Name:      name,
+			Link:      name + format.Extension(),
+			Functions: len(module.Functions),
+			Classes:   len(module.Classes),
+		})
+	}
+
+	return renderDocs(format, "index", index)
+}
+
+// renderDocs: executes a named documentation template in the given format
+func renderDocs(format DocFormat, name string, data interface{}) (string, error) {
+	var sb strings.Builder
+
+	switch format {
+	case DocFormatMarkdown:
+		if err := markdownTemplates.ExecuteTemplate(&sb, name, data); err != nil {
+			return "", fmt.Errorf("failed to render %s: %w", name, err)
+		}
+	case DocFormatHTML:
+		if err := htmlTemplates.ExecuteTemplate(&sb, name, data); err != nil {
+			return "", fmt.Errorf("failed to render %s: %w", name, err)
+		}
+	default:
+		return "", fmt.Errorf("unknown documentation format %q", format)
+	}
+
+	return sb.String(), nil
+}
+
+// luaSignature: formats a function signature, e.g. json.parse(str: string) -> table, string|nil
+func luaSignature(name string, params []*LuaParam, returns []*LuaReturn) string {
+	args := make([]string, len(params))
+	for i, param := range params {
+		name := param.Name
+		if param.Optional {
+			name += "?"
+		}
+		args[i] = name + ": " + param.Type
+	}
+
+	signature := name + "(" + strings.Join(args, ", ") + ")"
+	if len(returns) > 0 {
+		types := make([]string, len(returns))
+		for i, ret := range returns {
+			types[i] = ret.Type
+		}
+		signature += " -> " + strings.Join(types, ", ")
+	}
+
+	return signature
+}
+
+// docDescription: removes the Go identifier prefix from a doc comment description
+func docDescription(description string) string {
+	return goNamePrefix.ReplaceAllString(description, "")
+}
+
+// anchorName: returns the HTML anchor of a documented element (case-sensitive, so that
+// log.logger and log.Logger do not collide)
+func anchorName(name string) string {
+	return strings.NewReplacer(".", "-", ":", "-").Replace(name)
+}
+
+// markdownCell: escapes a value for use in a Markdown table cell
+func markdownCell(value string) string {
+	if value == "" {
+		return " "
+	}
+	return strings.NewReplacer("|", `\|`, "\n", " ").Replace(value)
+}
+
+// markdownTemplates: Markdown templates of the index and module pages
+var markdownTemplates = texttemplate.Must(texttemplate.New("markdown").
+	Funcs(texttemplate.FuncMap{"cell": markdownCell}).
+	Parse(`
+{{- define "index" -}}
+# Lua Modules
+
+| Module | Functions | Classes |
+|--------|-----------|---------|
+{{- range .Modules}}
+| [{{.Name}}]({{.Link}}) | {{.Functions}} | {{.Classes}} |
+{{- end}}
+{{end}}
+
+{{- define "params" -}}
+{{- if .Params}}
+
+**Parameters**
+
+| Name | Type | Description |
+|------|------|-------------|
+{{- range .Params}}
+| ` + "`{{.Name}}`" + ` | ` + "`{{cell .Type}}`" + ` | {{cell .Description}} |
+{{- end}}
+{{- end}}
+{{- if .Returns}}
+
+**Returns**
+
+| Name | Type | Description |
+|------|------|-------------|
+{{- range .Returns}}
+| {{if .Name}}` + "`{{.Name}}`" + `{{end}} | ` + "`{{cell .Type}}`" + ` | {{cell .Description}} |
+{{- end}}
+{{- end}}
+{{- if .Example}}
+
+**Example**
+
+` + "```lua" + `
+{{.Example}}
+` + "```" + `
+{{- end}}
+{{- end}}
+
+{{- define "function" -}}
+<a id="{{.Anchor}}"></a>
+{{.Heading}} {{.Name}}
+
+` + "```lua" + `
+{{.Signature}}
+` + "```" + `
+{{- if .Description}}
+
+{{.Description}}
+{{- end}}
+{{- template "params" .}}
+{{- end}}
+
+{{- define "page" -}}
+# {{.Module}}
+
+` + "```lua" + `
+{{if .Example}}{{.Example}}{{else}}local {{.Module}} = require("{{.Module}}"){{end}}
+` + "```" + `
+{{- if .Functions}}
+
+## Functions
+{{- range .Functions}}
+
+{{template "function" .}}
+{{- end}}
+{{- end}}
+{{- if .Classes}}
+
+## Classes
+{{- range .Classes}}
+
+<a id="{{.Anchor}}"></a>
+### {{.Name}}
+{{- if .Description}}
+
+{{.Description}}
+{{- end}}
+{{- if .Fields}}
+
+**Fields**
+
+| Name | Type | Description |
+|------|------|-------------|
+{{- range .Fields}}
+| ` + "`{{.Name}}`" + ` | ` + "`{{cell .Type}}`" + ` | {{cell .Description}} |
+{{- end}}
+{{- end}}
+{{- range .Methods}}
+
+{{template "function" .}}
+{{- end}}
+{{- end}}
+{{- end}}
+{{- if .Constants}}
+
+## Constants
+
+| Name | Type | Description |
+|------|------|-------------|
+{{- range .Constants}}
+| ` + "`{{.Name}}`" + ` | ` + "`{{cell .Type}}`" + ` | {{cell .Description}} |
+{{- end}}
+{{- end}}
+{{end}}
+`))
+
+// htmlTemplates: HTML templates of the index and module pages
+var htmlTemplates = htmltemplate.Must(htmltemplate.New("html").Parse(`
+{{- define "head" -}}
+<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>{{.}}</title>
+<style>
+body { font-family: sans-serif; max-width: 960px; margin: 2em auto; padding: 0 1em; color: #222; }
+code, pre { font-family: monospace; background: #f4f4f4; }
+pre { padding: 0.75em; overflow-x: auto; }
+table { border-collapse: collapse; margin: 0.5em 0; }
+th, td { border: 1px solid #ccc; padding: 0.3em 0.6em; text-align: left; vertical-align: top; }
+h3 { border-top: 1px solid #eee; padding-top: 0.75em; }
+</style>
+</head>
+<body>
+{{- end}}
+
+{{- define "index" -}}
+{{template "head" "Lua Modules"}}
+<h1>Lua Modules</h1>
+<table>
+<tr><th>Module</th><th>Functions</th><th>Classes</th></tr>
+{{- range .Modules}}
+<tr><td><a href="{{.Link}}">{{.Name}}</a></td><td>{{.Functions}}</td><td>{{.Classes}}</td></tr>
+{{- end}}
+</table>
+</body>
+</html>
+{{end}}
+
+{{- define "table" -}}
+<table>
+<tr><th>Name</th><th>Type</th><th>Description</th></tr>
+{{- range .}}
+<tr><td>{{if .Name}}<code>{{.Name}}</code>{{end}}</td><td><code>{{.Type}}</code></td><td>{{.Description}}</td></tr>
+{{- end}}
+</table>
+{{- end}}
+
+{{- define "function" -}}
+<h3 id="{{.Anchor}}">{{.Name}}</h3>
+<pre><code class="language-lua">{{.Signature}}</code></pre>
+{{- if .Description}}
+<p>{{.Description}}</p>
+{{- end}}
+{{- if .Params}}
+<h4>Parameters</h4>
+{{template "table" .Params}}
+{{- end}}
+{{- if .Returns}}
+<h4>Returns</h4>
+{{template "table" .Returns}}
+{{- end}}
+{{- if .Example}}
+<h4>Example</h4>
+<pre><code class="language-lua">{{.Example}}</code></pre>
+{{- end}}
+{{end}}
+
+{{- define "page" -}}
+{{template "head" .Module}}
+<p><a href="index.html">Modules</a></p>
+<h1>{{.Module}}</h1>
+<pre><code class="language-lua">{{if .Example}}{{.Example}}{{else}}local {{.Module}} = require("{{.Module}}"){{end}}</code></pre>
+{{- if .Functions}}
+<h2>Functions</h2>
+{{range .Functions}}{{template "function" .}}{{end}}
+{{- end}}
+{{- if .Classes}}
+<h2>Classes</h2>
+{{- range .Classes}}
+<h3 id="{{.Anchor}}">{{.Name}}</h3>
+{{- if .Description}}
+<p>{{.Description}}</p>
+{{- end}}
+{{- if .Fields}}
+<h4>Fields</h4>
+{{template "table" .Fields}}
+{{- end}}
+{{range .Methods}}{{template "function" .}}{{end}}
+{{- end}}
+{{- end}}
+{{- if .Constants}}
+<h2>Constants</h2>
+{{template "table" .Constants}}
+{{- end}}
+</body>
+</html>
+{{end}}
+`))
@@ -0,0 +1,236 @@
+// Copyright (c) 2024-2025 Thomas Maurice
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package stubgen
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// docsTestCode: a module exercising functions, classes, constants and examples
+const docsTestCode = `package test
+
+// Loader: creates the greeter module
+//
+// @luamodule greeter
+//
+// Example usage in Lua:
+//
+//	local greeter = require("greeter")
+//	print(greeter.hello("world"))
+func Loader(L *lua.LState) int {
+	return 1
+}
+
+// hello: greets someone.
+//
+// @luafunc hello
+// @luaparam name string The name to greet
+// @luareturn string greeting The greeting
+// @luareturn string|nil err Error message <if any>
+//
+// Example:
+//
+//	local greeting = greeter.hello("world")
+//	print(greeting)  -- prints "hello world"
+func hello(L *lua.LState) int {
+	return 1
+}
+
+// greeterShout: greets loudly.
+//
+// @luamethod greeter.Greeter shout
+// @luaparam self greeter.Greeter The greeter
+// @luaparam name string The name to greet
+func greeterShout(L *lua.LState) int {
+	return 0
+}
+
+// @luaclass greeter.Greeter
+// @luafield prefix string The greeting prefix
+
+// @luaconst VERSION string The module version
+`
+
+// newDocsTestAnalyzer: scans the docs test module
+func newDocsTestAnalyzer(t *testing.T) *Analyzer {
+	t.Helper()
+
+	tmpDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(tmpDir, "greeter.go"), []byte(docsTestCode), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	a := NewAnalyzer()
+	if _, err := a.ScanDirectory(tmpDir); err != nil {
+		t.Fatalf("ScanDirectory failed: %v", err)
+	}
+
+	return a
+}
+
+func TestAnalyzer_ExtractExample(t *testing.T) {
+	a := NewAnalyzer()
+
+	tests := []struct {
+		name     string
+		comment  string
+		expected string
+	}{
+		{
+			name:     "example block",
+			comment:  "desc\n\n@luafunc f\n\nExample:\n\n\tlocal x = f()\n\n\tprint(x)\n",
+			expected: "local x = f()\n\nprint(x)",
+		},
+		{
+			name:     "lua usage block",
+			comment:  "Example usage in Lua:\n\n\tlocal m = require(\"m\")\n",
+			expected: "local m = require(\"m\")",
+		},
+		{
+			name:     "block ends at unindented text",
+			comment:  "Example:\n\n\tf()\n\nMore text\n\n\tnot_example()\n",
+			expected: "f()",
+		},
+		{
+			name:     "no example",
+			comment:  "@luafunc f\n",
+			expected: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if result := a.extractExample(tt.comment); result != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestAnalyzer_GenerateModuleDocsMarkdown(t *testing.T) {
+	a := newDocsTestAnalyzer(t)
+
+	page, err := a.GenerateModuleDocs("greeter", DocFormatMarkdown)
+	if err != nil {
+		t.Fatalf("GenerateModuleDocs failed: %v", err)
+	}
+
+	expected := []string{
+		"# greeter\n",
+		"local greeter = require(\"greeter\")\nprint(greeter.hello(\"world\"))",
+		"## Functions",
+		"### greeter.hello\n",
+		"greeter.hello(name: string) -> string, string|nil",
+		"greets someone.",
+		"| `name` | `string` | The name to greet |",
+		"| `err` | `string\\|nil` | Error message <if any> |",
+		"**Example**\n\n```lua\nlocal greeting = greeter.hello(\"world\")\nprint(greeting)  -- prints \"hello world\"\n```",
+		"## Classes",
+		"### greeter.Greeter\n",
+		"| `prefix` | `string` | The greeting prefix |",
+		"#### greeter.Greeter:shout\n",
+		"greeter.Greeter:shout(name: string)",
+		"## Constants",
+		"| `VERSION` | `string` | The module version |",
+	}
+
+	for _, exp := range expected {
+		if !strings.Contains(page, exp) {
+			t.Errorf("Expected page to contain %q\nGot:\n%s", exp, page)
+		}
+	}
+
+	if strings.Contains(page, "hello: greets") {
+		t.Error("Expected the Go name prefix to be removed from descriptions")
+	}
+	if strings.Contains(page, "`self`") {
+		t.Error("Expected self to be omitted from method parameters")
+	}
+}
+
+func TestAnalyzer_GenerateModuleDocsHTML(t *testing.T) {
+	a := newDocsTestAnalyzer(t)
+
+	page, err := a.GenerateModuleDocs("greeter", DocFormatHTML)
+	if err != nil {
+		t.Fatalf("GenerateModuleDocs failed: %v", err)
+	}
+
+	expected := []string{
+		"<title>greeter</title>",
+		`<h3 id="greeter-hello">greeter.hello</h3>`,
+		"greeter.hello(name: string) -&gt; string, string|nil",
+		"Error message &lt;if any&gt;",
+		"print(greeting)  -- prints &#34;hello world&#34;",
+		`<h3 id="greeter-Greeter">greeter.Greeter</h3>`,
+		`<h3 id="greeter-Greeter-shout">greeter.Greeter:shout</h3>`,
+		"<h2>Constants</h2>",
+	}
+
+	for _, exp := range expected {
+		if !strings.Contains(page, exp) {
+			t.Errorf("Expected page to contain %q\nGot:\n%s", exp, page)
+		}
+	}
+}
+
+func TestAnalyzer_GenerateDocsIndex(t *testing.T) {
+	a := newDocsTestAnalyzer(t)
+
+	markdown, err := a.GenerateDocsIndex(DocFormatMarkdown)
+	if err != nil {
+		t.Fatalf("GenerateDocsIndex failed: %v", err)
+	}
+	if !strings.Contains(markdown, "| [greeter](greeter.md) | 1 | 1 |") {
+		t.Errorf("Unexpected Markdown index:\n%s", markdown)
+	}
+
+	html, err := a.GenerateDocsIndex(DocFormatHTML)
+	if err != nil {
+		t.Fatalf("GenerateDocsIndex failed: %v", err)
+	}
+	if !strings.Contains(html, `<a href="greeter.html">greeter</a>`) {
+		t.Errorf("Unexpected HTML index:\n%s", html)
+	}
+}
+
+func TestAnalyzer_GenerateModuleDocsErrors(t *testing.T) {
+	a := newDocsTestAnalyzer(t)
+
+	if _, err := a.GenerateModuleDocs("missing", DocFormatMarkdown); err == nil {
+		t.Error("Expected error for unknown module, got nil")
+	}
+
+	if _, err := a.GenerateModuleDocs("greeter", DocFormat("pdf")); err == nil {
+		t.Error("Expected error for unknown format, got nil")
+	}
+
+	if _, err := ParseDocFormat("pdf"); err == nil {
+		t.Error("Expected error parsing unknown format, got nil")
+	}
+
+	if format, err := ParseDocFormat("md"); err != nil || format != DocFormatMarkdown {
+		t.Errorf("Expected md to parse as markdown, got %q, %v", format, err)
+	}
+}
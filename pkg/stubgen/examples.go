@@ -0,0 +1,291 @@
+// Copyright (c) 2024-2025 Thomas Maurice
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package stubgen
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	lua "github.com/yuin/gopher-lua"
+	"github.com/yuin/gopher-lua/parse"
+)
+
+// DocExample: a Lua example extracted from the doc comment of a module, function or method
+type DocExample struct {
+	Name string // Module name, module.function or Class:method
+	Code string
+}
+
+// ExampleOptions: how to run the examples of a module
+type ExampleOptions struct {
+	// Setup is called on every fresh state before an example runs, e.g. to preload
+	// other modules or define the globals an example expects
+	Setup func(L *lua.LState) error
+	// Skip lists the names of examples that must not run (e.g. "log.fatal")
+	Skip []string
+}
+
+// exampleExpectation: an expected output declared by a "-- prints" or "-- returns" comment
+type exampleExpectation struct {
+	line    int
+	kind    string // "prints" or "returns"
+	literal string // Lua literal of the expected value
+}
+
+var (
+	// expectationComment: a "-- prints <value>" or "-- returns <value>" comment
+	expectationComment = regexp.MustCompile(`--\s*(prints|returns)\s+(.*)$`)
+	// printCall: a call to the print builtin (not spew.print or sprint)
+	printCall = regexp.MustCompile(`(?:^|[^A-Za-z0-9_.:])print\(`)
+	// assignedVariable: the first variable assigned by a statement
+	assignedVariable = regexp.MustCompile(`^\s*(?:local\s+)?([A-Za-z_][A-Za-z0-9_]*)\s*(?:,[^=]*)?=[^=]`)
+	// luaLiteral: literals that can be checked, other expectations are descriptive ("current timestamp")
+	luaLiteral = regexp.MustCompile(`^(?:"(?:[^"\\]|\\.)*"|'(?:[^'\\]|\\.)*'|-?[0-9][0-9.eExX+-]*|true|false|nil)$`)
+)
+
+// Examples: returns the examples of a module: the "Example usage in Lua:" block of the module,
+// then the "Example:" blocks of its functions and methods, sorted by name
+func (a *Analyzer) Examples(moduleName string) ([]DocExample, error) {
+	module, exists := a.modules[moduleName]
+	if !exists {
+		return nil, fmt.Errorf("module %s not found", moduleName)
+	}
+
+	var examples []DocExample
+	for _, fn := range module.Functions {
+		if fn.Example != "" {
+			examples = append(examples, DocExample{Name: moduleName + "." + fn.Name, Code: fn.Example})
+		}
+	}
+	for _, class := range module.Classes {
+		for _, method := range class.Methods {
+			if method.Example != "" {
+				examples = append(examples, DocExample{Name: class.Name + ":" + method.Name, Code: method.Example})
+			}
+		}
+	}
+	sort.Slice(examples, func(i, j int) bool { return examples[i].Name < examples[j].Name })
+
+	if module.Example != "" {
+		examples = append([]DocExample{{Name: moduleName, Code: module.Example}}, examples...)
+	}
+
+	return examples, nil
+}
+
+// RunExample: runs an example in a fresh LState with the module preloaded and available as
+// a global named after it. print is captured instead of writing to stdout. Lines ending with
+// "-- prints <literal>" check what the print call of the line writes, and lines ending with
+// "-- returns <literal>" check the first variable assigned by the line, which must run at least
+// once. Expectations that are not Lua literals ("-- prints current timestamp") are descriptive
+// and ignored.
+// Returns the captured output, and an error if the example fails or an expectation is not met.
+func RunExample(example DocExample, moduleName string, loader lua.LGFunction, opts ExampleOptions) (string, error) {
+	L := lua.NewState()
+	defer L.Close()
+
+	L.PreloadModule(moduleName, loader)
+	if opts.Setup != nil {
+		if err := opts.Setup(L); err != nil {
+			return "", fmt.Errorf("%s: setup failed: %w", example.Name, err)
+		}
+	}
+
+	var output strings.Builder
+	printed := make(map[int][]string)
+	returned := make(map[int][]lua.LValue)
+
+	printArgs := func(L *lua.LState, first int) string {
+		parts := make([]string, 0, L.GetTop())
+		for i := first; i <= L.GetTop(); i++ {
+			parts = append(parts, L.ToStringMeta(L.Get(i)).String())
+		}
+		line := strings.Join(parts, "\t")
+		output.WriteString(line + "\n")
+		return line
+	}
+
+	L.SetGlobal("print", L.NewFunction(func(L *lua.LState) int {
+		printArgs(L, 1)
+		return 0
+	}))
+	L.SetGlobal("__doc_print", L.NewFunction(func(L *lua.LState) int {
+		line := L.CheckInt(1)
+		printed[line] = append(printed[line], printArgs(L, 2))
+		return 0
+	}))
+	L.SetGlobal("__doc_returns", L.NewFunction(func(L *lua.LState) int {
+		line := L.CheckInt(1)
+		returned[line] = append(returned[line], L.Get(2))
+		return 0
+	}))
+
+	code, expectations, err := instrumentExample(example.Code)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", example.Name, err)
+	}
+	prelude := fmt.Sprintf("%s = require(%q); ", moduleName, moduleName)
+
+	if err := L.DoString(prelude + code); err != nil {
+		return output.String(), fmt.Errorf("%s: %w", example.Name, err)
+	}
+
+	for _, exp := range expectations {
+		expected, ok := evalLiteral(L, exp.literal)
+		if !ok {
+			continue
+		}
+
+		if len(printed[exp.line]) == 0 && len(returned[exp.line]) == 0 {
+			return output.String(), fmt.Errorf("%s: line %d never ran (-- %s %s)", example.Name, exp.line, exp.kind, exp.literal)
+		}
+
+		switch exp.kind {
+		case "prints":
+			for _, actual := range printed[exp.line] {
+				if actual != L.ToStringMeta(expected).String() {
+					return output.String(), fmt.Errorf("%s: line %d prints %q, expected %s", example.Name, exp.line, actual, exp.literal)
+				}
+			}
+		case "returns":
+			for _, actual := range returned[exp.line] {
+				if actual.Type() != expected.Type() || actual != expected {
+					return output.String(), fmt.Errorf("%s: line %d returns %s, expected %s", example.Name, exp.line, describeValue(actual), exp.literal)
+				}
+			}
+		}
+	}
+
+	return output.String(), nil
+}
+
+// instrumentExample: rewrites the statements carrying expectations so their values are recorded,
+// keeping line numbers unchanged. A returns expectation is recorded after the whole assignment,
+// which may span several lines. A returns expectation with a literal that does not follow an
+// assignment starting on its line is reported instead of being ignored.
+func instrumentExample(code string) (string, []exampleExpectation, error) {
+	lines := strings.Split(code, "\n")
+	original := append([]string(nil), lines...)
+	recorded := make(map[int][]string) // Recording calls run after the statement ending on a line
+	var expectations []exampleExpectation
+
+	for i, line := range original {
+		loc := expectationComment.FindStringSubmatchIndex(line)
+		if loc == nil {
+			continue
+		}
+
+		kind := line[loc[2]:loc[3]]
+		literal := strings.TrimSpace(line[loc[4]:loc[5]])
+		stmt := line[:loc[0]]
+		lineNumber := i + 1
+		checked := luaLiteral.MatchString(literal)
+
+		switch kind {
+		case "prints":
+			loc := printCall.FindStringIndex(stmt)
+			if loc == nil {
+				// Output of other functions, such as spew.print, is not checked
+				continue
+			}
+			idx := loc[1] - len("print(")
+			rest := stmt[idx+len("print("):]
+			separator := ", "
+			if strings.HasPrefix(strings.TrimSpace(rest), ")") {
+				separator = ""
+			}
+			lines[i] = fmt.Sprintf("%s__doc_print(%d%s%s", stmt[:idx], lineNumber, separator, rest)
+		case "returns":
+			match := assignedVariable.FindStringSubmatch(stmt)
+			if match == nil {
+				if checked {
+					return "", nil, fmt.Errorf("line %d: -- returns must follow an assignment starting on the same line", lineNumber)
+				}
+				continue
+			}
+			last, ok := statementEnd(original, i, stmt)
+			if !ok {
+				return "", nil, fmt.Errorf("line %d: cannot find the end of the statement (-- returns %s)", lineNumber, literal)
+			}
+			lines[i] = stmt
+			recorded[last] = append(recorded[last], fmt.Sprintf("__doc_returns(%d, %s);", lineNumber, match[1]))
+		}
+
+		expectations = append(expectations, exampleExpectation{line: lineNumber, kind: kind, literal: literal})
+	}
+
+	// Prepended to the next line, so that a comment ending the statement cannot swallow them
+	for last, calls := range recorded {
+		if last+1 == len(lines) {
+			lines = append(lines, "")
+		}
+		lines[last+1] = strings.Join(calls, " ") + " " + lines[last+1]
+	}
+
+	return strings.Join(lines, "\n"), expectations, nil
+}
+
+// statementEnd: returns the index of the line ending the statement that starts on line first,
+// the first line from which the statement parses on its own
+func statementEnd(lines []string, first int, stmt string) (int, bool) {
+	chunk := stmt
+	for last := first; last < len(lines); last++ {
+		if last > first {
+			chunk += "\n" + lines[last]
+		}
+		if _, err := parse.Parse(strings.NewReader(chunk), "example"); err == nil {
+			return last, true
+		}
+	}
+
+	return 0, false
+}
+
+// evalLiteral: evaluates the Lua literal of an expectation, returns false if it is not a literal
+func evalLiteral(L *lua.LState, literal string) (lua.LValue, bool) {
+	if !luaLiteral.MatchString(literal) {
+		return nil, false
+	}
+
+	fn, err := L.LoadString("return " + literal)
+	if err != nil {
+		return nil, false
+	}
+
+	L.Push(fn)
+	if err := L.PCall(0, 1, nil); err != nil {
+		return nil, false
+	}
+
+	value := L.Get(-1)
+	L.Pop(1)
+	return value, true
+}
+
+// describeValue: formats a Lua value for error messages
+func describeValue(lv lua.LValue) string {
+	if s, ok := lv.(lua.LString); ok {
+		return fmt.Sprintf("%q", string(s))
+	}
+	return fmt.Sprintf("%s (%s)", lv.String(), lv.Type())
+}
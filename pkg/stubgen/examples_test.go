@@ -0,0 +1,253 @@
+// Copyright (c) 2024-2025 Thomas Maurice
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package stubgen
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	lua "github.com/yuin/gopher-lua"
+)
+
+// greeterLoader: a minimal module used to run examples
+func greeterLoader(L *lua.LState) int {
+	mod := L.SetFuncs(L.NewTable(), map[string]lua.LGFunction{
+		"hello": func(L *lua.LState) int {
+			L.Push(lua.LString("hello " + L.CheckString(1)))
+			return 1
+		},
+		"double": func(L *lua.LState) int {
+			L.Push(L.CheckNumber(1) * 2)
+			return 1
+		},
+	})
+	L.Push(mod)
+	return 1
+}
+
+func TestInstrumentExample(t *testing.T) {
+	code := strings.Join([]string{
+		`local s = greeter.hello("a")  -- returns "hello a"`,
+		`print(s)  -- prints "hello a"`,
+		`print()  -- prints ""`,
+		`spew.print(s)  -- prints "ignored"`,
+		`print(os.time())  -- prints current timestamp`,
+		`local n = greeter.double(  -- returns 4`,
+		`    2)  -- the argument`,
+	}, "\n")
+
+	instrumented, expectations, err := instrumentExample(code)
+	if err != nil {
+		t.Fatalf("instrumentExample failed: %v", err)
+	}
+
+	expected := strings.Join([]string{
+		`local s = greeter.hello("a")  `,
+		`__doc_returns(1, s); __doc_print(2, s)  `,
+		`__doc_print(3)  `,
+		`spew.print(s)  -- prints "ignored"`,
+		`__doc_print(5, os.time())  `,
+		`local n = greeter.double(  `,
+		`    2)  -- the argument`,
+		`__doc_returns(6, n); `,
+	}, "\n")
+
+	if instrumented != expected {
+		t.Errorf("Unexpected instrumented code:\n%s\nExpected:\n%s", instrumented, expected)
+	}
+
+	if len(expectations) != 5 {
+		t.Fatalf("Expected 5 expectations, got %d", len(expectations))
+	}
+
+	if expectations[0].kind != "returns" || expectations[0].literal != `"hello a"` || expectations[0].line != 1 {
+		t.Errorf("Unexpected first expectation: %+v", expectations[0])
+	}
+
+	// Checked expectations that cannot be attached to an assignment are reported
+	for _, code := range []string{
+		"local n = greeter.double(\n    2)  -- returns 4",
+		"local n = greeter.double(2  -- returns 4",
+	} {
+		if _, _, err := instrumentExample(code); err == nil {
+			t.Errorf("Expected an error for:\n%s", code)
+		}
+	}
+}
+
+func TestRunExample(t *testing.T) {
+	tests := []struct {
+		name    string
+		code    string
+		output  string
+		wantErr string
+	}{
+		{
+			name:   "matching expectations",
+			code:   "local s = greeter.hello(\"world\")  -- returns \"hello world\"\nprint(s)  -- prints \"hello world\"\nprint(greeter.double(21))  -- prints 42",
+			output: "hello world\n42\n",
+		},
+		{
+			name:   "descriptive expectations are ignored",
+			code:   "print(greeter.double(2))  -- prints the doubled value",
+			output: "4\n",
+		},
+		{
+			name:   "multi-line statement",
+			code:   "local n = greeter.double(  -- returns 42\n    21)\nprint(n)",
+			output: "42\n",
+		},
+		{
+			name:    "branch not taken",
+			code:    "if false then\n    print(\"never\")  -- prints \"something else\"\nend",
+			wantErr: `line 2 never ran (-- prints "something else")`,
+		},
+		{
+			name:    "function never called",
+			code:    "local function f()\n    local n = greeter.double(1)  -- returns 2\nend",
+			wantErr: "line 2 never ran (-- returns 2)",
+		},
+		{
+			name:    "wrong printed value",
+			code:    "print(greeter.hello(\"world\"))  -- prints \"hello moon\"",
+			wantErr: `line 1 prints "hello world", expected "hello moon"`,
+		},
+		{
+			name:    "wrong returned value",
+			code:    "local n = greeter.double(2)  -- returns 5",
+			wantErr: "line 1 returns 4 (number), expected 5",
+		},
+		{
+			name:    "returned value of another type",
+			code:    "local n = greeter.double(2)  -- returns \"4\"",
+			wantErr: `line 1 returns 4 (number), expected "4"`,
+		},
+		{
+			name:    "lua error",
+			code:    "greeter.missing()",
+			wantErr: "attempt to call a non-function object",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output, err := RunExample(DocExample{Name: "greeter.test", Code: tt.code}, "greeter", greeterLoader, ExampleOptions{})
+
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Expected error containing %q, got %v", tt.wantErr, err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("RunExample failed: %v", err)
+			}
+			if output != tt.output {
+				t.Errorf("Expected output %q, got %q", tt.output, output)
+			}
+		})
+	}
+}
+
+func TestRunExampleSetup(t *testing.T) {
+	opts := ExampleOptions{
+		Setup: func(L *lua.LState) error {
+			return L.DoString(`name = "setup"`)
+		},
+	}
+
+	output, err := RunExample(DocExample{Name: "greeter", Code: "print(greeter.hello(name))"}, "greeter", greeterLoader, opts)
+	if err != nil {
+		t.Fatalf("RunExample failed: %v", err)
+	}
+	if output != "hello setup\n" {
+		t.Errorf("Unexpected output %q", output)
+	}
+}
+
+func TestAnalyzer_Examples(t *testing.T) {
+	tmpDir := t.TempDir()
+	code := `package test
+
+// Loader: creates the greeter module
+//
+// @luamodule greeter
+//
+// Example usage in Lua:
+//
+//	print(greeter.hello("module"))  -- prints "hello module"
+func Loader(L *lua.LState) int {
+	return 1
+}
+
+// hello: greets someone
+//
+// @luafunc hello
+// @luaparam name string The name
+// @luareturn string greeting The greeting
+//
+// Example:
+//
+//	local greeting = greeter.hello("world")  -- returns "hello world"
+func hello(L *lua.LState) int {
+	return 1
+}
+
+// double: doubles a number
+//
+// @luafunc double
+// @luaparam n number The number
+// @luareturn number result The doubled number
+func double(L *lua.LState) int {
+	return 1
+}
+`
+	if err := os.WriteFile(filepath.Join(tmpDir, "greeter.go"), []byte(code), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	a := NewAnalyzer()
+	if _, err := a.ScanDirectory(tmpDir); err != nil {
+		t.Fatalf("ScanDirectory failed: %v", err)
+	}
+
+	examples, err := a.Examples("greeter")
+	if err != nil {
+		t.Fatalf("Examples failed: %v", err)
+	}
+
+	if len(examples) != 2 || examples[0].Name != "greeter" || examples[1].Name != "greeter.hello" {
+		t.Fatalf("Unexpected examples: %+v", examples)
+	}
+
+	for _, example := range examples {
+		if _, err := RunExample(example, "greeter", greeterLoader, ExampleOptions{}); err != nil {
+			t.Errorf("RunExample failed: %v", err)
+		}
+	}
+
+	if _, err := a.Examples("missing"); err == nil {
+		t.Error("Expected error for unknown module, got nil")
+	}
+}
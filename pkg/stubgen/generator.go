@@ -43,8 +43,9 @@ func NewGenerator() *Generator {
 	}
 }
 
-// ScanDirectory: scans a directory for Go files with @luafunc annotations
-func (g *Generator) ScanDirectory(dir string) error {
+// ScanDirectory: scans a directory for Go files with @luafunc annotations.
+// Returns diagnostics for the malformed annotations that were skipped.
+func (g *Generator) ScanDirectory(dir string) ([]Diagnostic, error) {
 	return g.analyzer.ScanDirectory(dir)
 }
 
@@ -88,6 +89,22 @@ func (g *Generator) GenerateModule(moduleName string) (string, error) {
 	return sb.String(), nil
 }
 
+// GenerateModuleTeal: generates Teal declarations (.d.tl) for a module and the registered types.
+// The global records describing registered types come first, followed by the module record.
+func (g *Generator) GenerateModuleTeal(moduleName string) (string, error) {
+	typeDecls, err := g.typeRegistry.GenerateTealDeclarations()
+	if err != nil {
+		return "", fmt.Errorf("failed to generate type declarations: %w", err)
+	}
+
+	moduleDecls, err := g.analyzer.GenerateModuleTeal(moduleName)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate module declarations: %w", err)
+	}
+
+	return typeDecls + moduleDecls, nil
+}
+
 // GenerateConfig: configuration for stub generation
 type GenerateConfig struct {
 	// ScanDir is the directory to scan for Go files with @luafunc annotations
@@ -100,6 +117,14 @@ type GenerateConfig struct {
 	OutputFile string
 	// Types is an optional list of types to register for stub generation
 	Types []interface{}
+	// TealOutputFile is the optional name of a Teal declaration file to generate
+	// alongside the Lua stubs (e.g., "k8sclient.d.tl")
+	TealOutputFile string
+	// SnapshotFile is the optional name of a JSON snapshot of the registered types
+	// to write, for comparison with `stubgen diff` (e.g., "kubernetes.snapshot.json")
+	SnapshotFile string
+	// Strict makes Generate fail on malformed annotations instead of skipping them
+	Strict bool
 }
 
 // Generate: generates Lua stubs for a module based on the provided configuration.
@@ -108,15 +133,24 @@ type GenerateConfig struct {
 // 2. Registers any provided types
 // 3. Processes type dependencies
 // 4. Generates combined stubs
-// 5. Writes output to the specified file
+// 5. Writes output to the specified file (and the Teal declarations and snapshot, if requested)
 //
-// Returns the path to the generated file or an error.
+// Returns the path to the generated Lua file or an error.
 func (g *Generator) Generate(config GenerateConfig) (string, error) {
 	// Scan directory for function annotations
-	if err := g.ScanDirectory(config.ScanDir); err != nil {
+	diagnostics, err := g.ScanDirectory(config.ScanDir)
+	if err != nil {
 		return "", fmt.Errorf("error scanning directory: %w", err)
 	}
 
+	if config.Strict && len(diagnostics) > 0 {
+		problems := make([]string, len(diagnostics))
+		for i, d := range diagnostics {
+			problems[i] = d.String()
+		}
+		return "", fmt.Errorf("malformed annotations:\n%s", strings.Join(problems, "\n"))
+	}
+
 	// Register types if provided
 	for _, t := range config.Types {
 		if err := g.RegisterType(t); err != nil {
@@ -145,5 +179,29 @@ func (g *Generator) Generate(config GenerateConfig) (string, error) {
 		return "", fmt.Errorf("error writing output: %w", err)
 	}
 
+	if config.TealOutputFile != "" {
+		decls, err := g.GenerateModuleTeal(config.ModuleName)
+		if err != nil {
+			return "", fmt.Errorf("error generating Teal declarations: %w", err)
+		}
+
+		tealPath := fmt.Sprintf("%s/%s", config.OutputDir, config.TealOutputFile)
+		if err := os.WriteFile(tealPath, []byte(decls), 0644); err != nil {
+			return "", fmt.Errorf("error writing Teal declarations: %w", err)
+		}
+	}
+
+	if config.SnapshotFile != "" {
+		snapshot, err := g.typeRegistry.MarshalSnapshot()
+		if err != nil {
+			return "", fmt.Errorf("error generating snapshot: %w", err)
+		}
+
+		snapshotPath := fmt.Sprintf("%s/%s", config.OutputDir, config.SnapshotFile)
+		if err := os.WriteFile(snapshotPath, snapshot, 0644); err != nil {
+			return "", fmt.Errorf("error writing snapshot: %w", err)
+		}
+	}
+
 	return outputPath, nil
 }
@@ -25,6 +25,8 @@ import (
 	"path/filepath"
 	"strings"
 	"testing"
+
+	"github.com/thomas-maurice/glua/pkg/glua"
 )
 
 // TestNewGenerator: tests that NewGenerator creates a valid generator instance
@@ -46,7 +48,7 @@ func TestScanDirectory(t *testing.T) {
 	gen := NewGenerator()
 
 	// Use testdata directory which should exist
-	err := gen.ScanDirectory("testdata")
+	_, err := gen.ScanDirectory("testdata")
 	if err != nil {
 		t.Fatalf("ScanDirectory failed: %v", err)
 	}
@@ -95,7 +97,7 @@ func TestGenerateModule(t *testing.T) {
 	gen := NewGenerator()
 
 	// Scan testdata to get some modules
-	if err := gen.ScanDirectory("testdata"); err != nil {
+	if _, err := gen.ScanDirectory("testdata"); err != nil {
 		t.Fatalf("ScanDirectory failed: %v", err)
 	}
 
@@ -134,7 +136,7 @@ func TestGenerate(t *testing.T) {
 	tmpDir := t.TempDir()
 
 	// First scan to find an actual module
-	if err := gen.ScanDirectory("testdata"); err != nil {
+	if _, err := gen.ScanDirectory("testdata"); err != nil {
 		t.Fatalf("ScanDirectory failed: %v", err)
 	}
 
@@ -199,7 +201,7 @@ func TestGenerateWithTypes(t *testing.T) {
 	tmpDir := t.TempDir()
 
 	// First scan to find an actual module
-	if err := gen.ScanDirectory("testdata"); err != nil {
+	if _, err := gen.ScanDirectory("testdata"); err != nil {
 		t.Fatalf("ScanDirectory failed: %v", err)
 	}
 
@@ -272,7 +274,7 @@ func TestGenerateOutputCreation(t *testing.T) {
 	outputDir := filepath.Join(tmpDir, "nested", "output", "dir")
 
 	// First scan to find an actual module
-	if err := gen.ScanDirectory("testdata"); err != nil {
+	if _, err := gen.ScanDirectory("testdata"); err != nil {
 		t.Fatalf("ScanDirectory failed: %v", err)
 	}
 
@@ -313,3 +315,87 @@ func TestGenerateOutputCreation(t *testing.T) {
 		t.Errorf("Output file %s was not created", outputPath)
 	}
 }
+
+// TestGenerateWithSnapshot: tests that Generate writes a snapshot of the registered types
+func TestGenerateWithSnapshot(t *testing.T) {
+	type Settings struct {
+		Name string `json:"name"`
+	}
+
+	tmpDir := t.TempDir()
+	gen := NewGenerator()
+
+	_, err := gen.Generate(GenerateConfig{
+		ScanDir:      "testdata",
+		OutputDir:    tmpDir,
+		ModuleName:   "custom_annotations",
+		OutputFile:   "custom_annotations.gen.lua",
+		SnapshotFile: "custom_annotations.snapshot.json",
+		Types:        []interface{}{Settings{}},
+	})
+	if err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(tmpDir, "custom_annotations.snapshot.json"))
+	if err != nil {
+		t.Fatalf("Failed to read snapshot: %v", err)
+	}
+
+	snapshot, err := glua.UnmarshalSnapshot(data)
+	if err != nil {
+		t.Fatalf("UnmarshalSnapshot failed: %v", err)
+	}
+
+	if snapshot.Classes["stubgen.Settings"]["name"] != "string" {
+		t.Errorf("Expected snapshot to contain stubgen.Settings.name, got %+v", snapshot.Classes)
+	}
+}
+
+// TestGenerateStrict: tests that malformed annotations only fail generation in strict mode
+func TestGenerateStrict(t *testing.T) {
+	scanDir := t.TempDir()
+
+	testCode := `package test
+
+// Loader: creates the sloppy module
+//
+// @luamodule sloppy
+func Loader(L *lua.LState) int {
+	return 1
+}
+
+// sloppyFunc: a function with a malformed parameter
+//
+// @luafunc sloppy
+// @luaparam value
+func sloppyFunc(L *lua.LState) int {
+	return 0
+}
+`
+
+	if err := os.WriteFile(filepath.Join(scanDir, "sloppy.go"), []byte(testCode), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	config := GenerateConfig{
+		ScanDir:    scanDir,
+		OutputDir:  t.TempDir(),
+		ModuleName: "sloppy",
+		OutputFile: "sloppy.gen.lua",
+	}
+
+	// Malformed annotations are skipped by default
+	if _, err := NewGenerator().Generate(config); err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+
+	config.Strict = true
+	_, err := NewGenerator().Generate(config)
+	if err == nil {
+		t.Fatal("Expected Generate to fail in strict mode")
+	}
+	if !strings.Contains(err.Error(), "sloppy.go:13:4: @luaparam value: missing type") {
+		t.Errorf("Expected a positioned diagnostic, got: %v", err)
+	}
+}
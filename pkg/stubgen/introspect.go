@@ -0,0 +1,360 @@
+// Copyright (c) 2024-2025 Thomas Maurice
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+package stubgen
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	lua "github.com/yuin/gopher-lua"
+)
+
+// undocumentedDescription: description of the members discovered at runtime
+const undocumentedDescription = "Undocumented, discovered at runtime"
+
+// introspection: the state of IntrospectModule for one module
+type introspection struct {
+	analyzer    *Analyzer
+	L           *lua.LState
+	module      *LuaModule
+	types       map[*lua.LTable]string // Type metatables registered with L.NewTypeMetatable, by table
+	visited     map[*lua.LTable]bool
+	classes     map[*lua.LTable]bool // Type metatables whose methods were already merged
+	diagnostics []Diagnostic
+}
+
+// IntrospectModule: requires a module in a live LState and merges the shape of the table it returns
+// into the annotations of the module, scanned beforehand or not. This covers modules assembled at
+// runtime, e.g. by a Loader closure like k8sclient.Loader(config), which the comment scanner cannot see.
+//
+// Functions, constants and nested tables of the module table are compared with the @luafunc and
+// @luaconst annotations (nested members by their dotted name, e.g. "errors.is_not_found"), and the
+// methods of userdata metatables registered with L.NewTypeMetatable (while requiring the module, or
+// documented as a class of the module) with the @luamethod annotations. Members without annotations
+// are added to the module with generic signatures and reported as diagnostics, positioned at the
+// @luamodule annotation when the module was scanned.
+func (a *Analyzer) IntrospectModule(L *lua.LState, moduleName string) ([]Diagnostic, error) {
+	before := typeMetatables(L)
+
+	if err := L.CallByParam(lua.P{Fn: L.GetGlobal("require"), NRet: 1, Protect: true}, lua.LString(moduleName)); err != nil {
+		return nil, fmt.Errorf("failed to require %s: %w", moduleName, err)
+	}
+	value := L.Get(-1)
+	L.Pop(1)
+
+	table, ok := value.(*lua.LTable)
+	if !ok {
+		return nil, fmt.Errorf("module %s returned a %s, expected a table", moduleName, value.Type())
+	}
+
+	module, exists := a.modules[moduleName]
+	if !exists {
+		module = &LuaModule{
+			Name:      moduleName,
+			Functions: make([]*LuaFunction, 0),
+			Classes:   make([]*LuaClass, 0),
+		}
+		a.modules[moduleName] = module
+	}
+
+	in := &introspection{
+		analyzer: a,
+		L:        L,
+		module:   module,
+		types:    typeMetatables(L),
+		visited:  map[*lua.LTable]bool{table: true},
+		classes:  make(map[*lua.LTable]bool),
+	}
+
+	in.walkTable(table, "")
+
+	// Types registered by the module, and types of the module registered before it was required
+	for _, mt := range sortedTypes(in.types) {
+		name := in.types[mt]
+		if _, existed := before[mt]; !existed || module.findClass(name) != nil || module.findClass(moduleName+"."+name) != nil {
+			in.walkType(mt)
+		}
+	}
+
+	return in.diagnostics, nil
+}
+
+// walkTable: compares the members of a table of the module with the annotations, prefix is the
+// dotted path of the table ("" for the module table itself)
+func (in *introspection) walkTable(table *lua.LTable, prefix string) {
+	for _, key := range sortedKeys(table) {
+		name := prefix + key
+		value := table.RawGetString(key)
+
+		switch v := value.(type) {
+		case *lua.LFunction:
+			if in.hasFunction(name) {
+				continue
+			}
+			in.module.Functions = append(in.module.Functions, &LuaFunction{
+				Name:        name,
+				Description: undocumentedDescription,
+				Params:      functionParams(v, false),
+				Returns:     []*LuaReturn{{Type: "any", Name: "..."}},
+			})
+			in.report("function %s.%s exists at runtime but is not documented", in.module.Name, name)
+
+		case *lua.LTable:
+			if in.visited[v] || in.hasTable(name) {
+				continue
+			}
+			in.visited[v] = true
+
+			// Nested tables are declared so that the functions added under them resolve
+			if !in.hasConstant(name) && !in.hasMembers(name) {
+				in.module.Constants = append(in.module.Constants, &LuaConst{Name: name, Type: "table", Description: undocumentedDescription})
+				in.report("table %s.%s exists at runtime but is not documented", in.module.Name, name)
+			}
+			in.walkTable(v, name+".")
+
+		default:
+			if ud, ok := value.(*lua.LUserData); ok {
+				if mt, ok := ud.Metatable.(*lua.LTable); ok {
+					in.walkType(mt)
+				}
+			}
+			if in.hasConstant(name) {
+				continue
+			}
+			in.module.Constants = append(in.module.Constants, &LuaConst{Name: name, Type: in.valueType(value), Description: undocumentedDescription})
+			in.report("constant %s.%s exists at runtime but is not documented", in.module.Name, name)
+		}
+	}
+}
+
+// walkType: compares the methods of a userdata metatable with the @luamethod annotations
+// of the class it is registered as
+func (in *introspection) walkType(mt *lua.LTable) {
+	typeName, registered := in.types[mt]
+	if !registered || in.classes[mt] {
+		return
+	}
+	in.classes[mt] = true
+
+	methods := mt
+	if index, ok := mt.RawGetString("__index").(*lua.LTable); ok {
+		methods = index
+	}
+
+	class := in.module.findClass(typeName)
+	if class == nil {
+		class = in.module.findClass(in.module.Name + "." + typeName)
+	}
+
+	for _, key := range sortedKeys(methods) {
+		fn, ok := methods.RawGetString(key).(*lua.LFunction)
+		if !ok || strings.HasPrefix(key, "__") {
+			continue
+		}
+
+		if class == nil {
+			name := typeName
+			if !strings.Contains(name, ".") {
+				name = in.module.Name + "." + name
+			}
+			class = &LuaClass{
+				Name:              name,
+				Description:       undocumentedDescription,
+				Methods:           make([]*LuaMethod, 0),
+				Fields:            make([]*LuaField, 0),
+				CustomAnnotations: make([]string, 0),
+			}
+			in.module.Classes = append(in.module.Classes, class)
+		} else if hasMethod(class, key) {
+			continue
+		}
+
+		class.Methods = append(class.Methods, &LuaMethod{
+			Name:        key,
+			Description: undocumentedDescription,
+			Params:      functionParams(fn, true),
+			Returns:     []*LuaReturn{{Type: "any", Name: "..."}},
+		})
+		in.report("method %s:%s exists at runtime but is not documented", class.Name, key)
+	}
+}
+
+// report: records a diagnostic at the @luamodule annotation of the module
+func (in *introspection) report(format string, args ...interface{}) {
+	in.diagnostics = append(in.diagnostics, Diagnostic{Pos: in.module.Pos, Message: fmt.Sprintf(format, args...)})
+}
+
+// hasFunction: checks whether the module documents a function
+func (in *introspection) hasFunction(name string) bool {
+	for _, fn := range in.module.Functions {
+		if fn.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// hasConstant: checks whether the module documents a constant
+func (in *introspection) hasConstant(name string) bool {
+	for _, cnst := range in.module.Constants {
+		if cnst.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// hasTable: checks whether a nested table is documented as a whole, as an enum or a class of the module
+func (in *introspection) hasTable(name string) bool {
+	for _, enum := range in.module.Enums {
+		if in.analyzer.getClassLocalName(enum.Name, in.module.Name) == name {
+			return true
+		}
+	}
+	return in.module.findClass(in.module.Name+"."+name) != nil
+}
+
+// hasMembers: checks whether functions or constants are documented under a nested table
+func (in *introspection) hasMembers(name string) bool {
+	for _, fn := range in.module.Functions {
+		if strings.HasPrefix(fn.Name, name+".") {
+			return true
+		}
+	}
+	for _, cnst := range in.module.Constants {
+		if strings.HasPrefix(cnst.Name, name+".") {
+			return true
+		}
+	}
+	return false
+}
+
+// valueType: returns the Lua type annotation of a constant value
+func (in *introspection) valueType(value lua.LValue) string {
+	switch v := value.(type) {
+	case lua.LString:
+		return "string"
+	case lua.LNumber:
+		if float64(v) == float64(int64(v)) {
+			return "integer"
+		}
+		return "number"
+	case lua.LBool:
+		return "boolean"
+	case *lua.LUserData:
+		if mt, ok := v.Metatable.(*lua.LTable); ok {
+			if name, ok := in.types[mt]; ok {
+				if class := in.module.findClass(name); class != nil {
+					return class.Name
+				}
+				if class := in.module.findClass(in.module.Name + "." + name); class != nil {
+					return class.Name
+				}
+			}
+		}
+		return "userdata"
+	case *lua.LChannel:
+		return "channel"
+	case *lua.LState:
+		return "thread"
+	}
+	return "any"
+}
+
+// hasMethod: checks whether a class documents a method
+func hasMethod(class *LuaClass, name string) bool {
+	for _, method := range class.Methods {
+		if method.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// functionParams: returns the parameters of a function, named after its arguments for Lua functions,
+// and a single vararg for Go functions whose arguments cannot be known
+func functionParams(fn *lua.LFunction, method bool) []*LuaParam {
+	vararg := &LuaParam{Name: "...", Type: "any", Vararg: true}
+	if fn.IsG || fn.Proto == nil {
+		if method {
+			return []*LuaParam{{Name: "self", Type: "any"}, vararg}
+		}
+		return []*LuaParam{vararg}
+	}
+
+	params := make([]*LuaParam, 0, fn.Proto.NumParameters+1)
+	for i := 0; i < int(fn.Proto.NumParameters); i++ {
+		name := fmt.Sprintf("arg%d", i+1)
+		if i < len(fn.Proto.DbgLocals) {
+			name = fn.Proto.DbgLocals[i].Name
+		}
+		params = append(params, &LuaParam{Name: name, Type: "any"})
+	}
+	if fn.Proto.IsVarArg != 0 {
+		params = append(params, vararg)
+	}
+
+	return params
+}
+
+// typeMetatables: returns the metatables registered with L.NewTypeMetatable, by table
+func typeMetatables(L *lua.LState) map[*lua.LTable]string {
+	types := make(map[*lua.LTable]string)
+
+	registry, ok := L.Get(lua.RegistryIndex).(*lua.LTable)
+	if !ok {
+		return types
+	}
+
+	registry.ForEach(func(key, value lua.LValue) {
+		name, ok := key.(lua.LString)
+		if !ok || strings.HasPrefix(string(name), "_") {
+			return
+		}
+		if mt, ok := value.(*lua.LTable); ok {
+			types[mt] = string(name)
+		}
+	})
+
+	return types
+}
+
+// sortedTypes: returns the metatables sorted by type name
+func sortedTypes(types map[*lua.LTable]string) []*lua.LTable {
+	tables := make([]*lua.LTable, 0, len(types))
+	for mt := range types {
+		tables = append(tables, mt)
+	}
+	sort.Slice(tables, func(i, j int) bool { return types[tables[i]] < types[tables[j]] })
+	return tables
+}
+
+// sortedKeys: returns the string keys of a table, sorted
+func sortedKeys(table *lua.LTable) []string {
+	var keys []string
+	table.ForEach(func(key, _ lua.LValue) {
+		if s, ok := key.(lua.LString); ok {
+			keys = append(keys, string(s))
+		}
+	})
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,225 @@
+// Copyright (c) 2024-2025 Thomas Maurice
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+package stubgen
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	lua "github.com/yuin/gopher-lua"
+)
+
+// introspectTestCode: the documented part of a module assembled at runtime
+const introspectTestCode = `package test
+
+// Loader: creates the client module
+//
+// @luamodule client
+func Loader(L *lua.LState) int {
+	return 1
+}
+
+// get: gets an object
+//
+// @luafunc get
+// @luaparam name string The object name
+// @luareturn table object The object
+func get(L *lua.LState) int {
+	return 1
+}
+
+// is_not_found: checks an error
+//
+// @luafunc errors.is_not_found
+// @luaparam err string The error
+// @luareturn boolean found Whether the object was not found
+func isNotFound(L *lua.LState) int {
+	return 1
+}
+
+// clientClose: closes the connection
+//
+// @luamethod client.Connection close
+func clientClose(L *lua.LState) int {
+	return 0
+}
+`
+
+// introspectTestLoader: builds the module table in a closure, with undocumented members
+func introspectTestLoader(L *lua.LState) int {
+	fn := func(L *lua.LState) int { return 0 }
+
+	mt := L.NewTypeMetatable("Connection")
+	L.SetField(mt, "__index", L.SetFuncs(L.NewTable(), map[string]lua.LGFunction{
+		"close": fn,
+		"ping":  fn,
+	}))
+	L.SetField(mt, "__tostring", L.NewFunction(fn))
+
+	conn := L.NewUserData()
+	L.SetMetatable(conn, mt)
+
+	errors := L.NewTable()
+	L.SetField(errors, "is_not_found", L.NewFunction(fn))
+	L.SetField(errors, "is_conflict", L.NewFunction(fn))
+
+	mod := L.NewTable()
+	L.SetField(mod, "get", L.NewFunction(fn))
+	L.SetField(mod, "watch", L.NewFunction(fn))
+	L.SetField(mod, "errors", errors)
+	L.SetField(mod, "VERSION", lua.LString("v1"))
+	L.SetField(mod, "TIMEOUT", lua.LNumber(30))
+	L.SetField(mod, "default", conn)
+	L.SetField(mod, "self", mod)
+	L.Push(mod)
+	return 1
+}
+
+func TestAnalyzer_IntrospectModule(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "client.go"), []byte(introspectTestCode), 0644); err != nil {
+		t.Fatalf("Failed to write test file: %v", err)
+	}
+
+	a := NewAnalyzer()
+	if _, err := a.ScanDirectory(dir); err != nil {
+		t.Fatalf("ScanDirectory failed: %v", err)
+	}
+
+	L := lua.NewState()
+	defer L.Close()
+
+	L.PreloadModule("client", introspectTestLoader)
+
+	// A bundled state also contains helper functions written in Lua
+	if err := L.DoString(`package.preload["client"] = (function(load)
+		return function()
+			local mod = load()
+			function mod.retry(attempts, delay, ...) end
+			return mod
+		end
+	end)(package.preload["client"])`); err != nil {
+		t.Fatalf("Failed to wrap the loader: %v", err)
+	}
+
+	diagnostics, err := a.IntrospectModule(L, "client")
+	if err != nil {
+		t.Fatalf("IntrospectModule failed: %v", err)
+	}
+
+	expected := []string{
+		"constant client.TIMEOUT exists at runtime but is not documented",
+		"constant client.VERSION exists at runtime but is not documented",
+		"method client.Connection:ping exists at runtime but is not documented",
+		"constant client.default exists at runtime but is not documented",
+		"function client.errors.is_conflict exists at runtime but is not documented",
+		"function client.retry exists at runtime but is not documented",
+		"function client.watch exists at runtime but is not documented",
+	}
+
+	if len(diagnostics) != len(expected) {
+		for _, d := range diagnostics {
+			t.Log(d)
+		}
+		t.Fatalf("Expected %d diagnostics, got %d", len(expected), len(diagnostics))
+	}
+	for i, d := range diagnostics {
+		if d.Message != expected[i] {
+			t.Errorf("Diagnostic %d: expected %q, got %q", i, expected[i], d.Message)
+		}
+		if filepath.Base(d.Pos.Filename) != "client.go" || d.Pos.Line != 5 {
+			t.Errorf("Diagnostic %d: expected client.go:5, got %s", i, d.Pos)
+		}
+	}
+
+	stub, err := a.GenerateModuleStub("client")
+	if err != nil {
+		t.Fatalf("GenerateModuleStub failed: %v", err)
+	}
+
+	for _, want := range []string{
+		"---@param name string The object name\n---@return table object The object\nfunction client.get(name) end",
+		"---@param ... any\n---@return any ...\nfunction client.watch(...) end",
+		"---@param attempts any\n---@param delay any\n---@param ... any\n---@return any ...\nfunction client.retry(attempts, delay, ...) end",
+		"function client.errors.is_conflict(...) end",
+		"function Connection:close() end",
+		"---@return any ...\nfunction Connection:ping(...) end",
+		"---@type integer Undocumented, discovered at runtime\nclient.TIMEOUT = nil",
+		"---@type string Undocumented, discovered at runtime\nclient.VERSION = nil",
+		"---@type client.Connection Undocumented, discovered at runtime\nclient.default = nil",
+	} {
+		if !strings.Contains(stub, want) {
+			t.Errorf("Expected stub to contain:\n%s\n\nGot:\n%s", want, stub)
+		}
+	}
+
+	if strings.Contains(stub, "__tostring") || strings.Contains(stub, "client.self") {
+		t.Errorf("Expected metamethods and cycles to be skipped, got:\n%s", stub)
+	}
+}
+
+func TestAnalyzer_IntrospectModuleWithoutAnnotations(t *testing.T) {
+	L := lua.NewState()
+	defer L.Close()
+
+	L.PreloadModule("runtime", func(L *lua.LState) int {
+		mod := L.NewTable()
+		L.SetField(mod, "run", L.NewFunction(func(L *lua.LState) int { return 0 }))
+		L.Push(mod)
+		return 1
+	})
+
+	a := NewAnalyzer()
+	diagnostics, err := a.IntrospectModule(L, "runtime")
+	if err != nil {
+		t.Fatalf("IntrospectModule failed: %v", err)
+	}
+
+	if len(diagnostics) != 1 || diagnostics[0].Message != "function runtime.run exists at runtime but is not documented" {
+		t.Errorf("Unexpected diagnostics: %v", diagnostics)
+	}
+	if a.ModuleCount() != 1 {
+		t.Errorf("Expected the module to be added, got %d modules", a.ModuleCount())
+	}
+}
+
+func TestAnalyzer_IntrospectModuleErrors(t *testing.T) {
+	L := lua.NewState()
+	defer L.Close()
+
+	L.PreloadModule("broken", func(L *lua.LState) int {
+		L.RaiseError("no configuration")
+		return 0
+	})
+	L.PreloadModule("scalar", func(L *lua.LState) int {
+		L.Push(lua.LNumber(1))
+		return 1
+	})
+
+	a := NewAnalyzer()
+	if _, err := a.IntrospectModule(L, "broken"); err == nil || !strings.Contains(err.Error(), "no configuration") {
+		t.Errorf("Expected the loader error, got %v", err)
+	}
+	if _, err := a.IntrospectModule(L, "scalar"); err == nil || !strings.Contains(err.Error(), "expected a table") {
+		t.Errorf("Expected a table error, got %v", err)
+	}
+}
@@ -0,0 +1,342 @@
+// Copyright (c) 2024-2025 Thomas Maurice
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package stubgen
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"sort"
+	"strconv"
+)
+
+// exportEntry: a Go function exported to Lua under a name
+type exportEntry struct {
+	name   string    // Lua name
+	target string    // Go function name, empty for closures
+	pos    token.Pos // Position of the name in the source
+}
+
+// lintPackage: the files of one Go package, as seen by CheckExports
+type lintPackage struct {
+	files []*ast.File
+	funcs map[string]*ast.FuncDecl     // Functions and methods by name
+	vars  map[string]*ast.CompositeLit // Variables initialized with a composite literal
+}
+
+// CheckExports: compares the functions each module exports with L.SetFuncs (and
+// L.SetField(tbl, "name", L.NewFunction(fn))) with its @luafunc and @luamethod annotations.
+// It reports exported functions without annotations, annotated functions that are never
+// exported, and functions exported under a different name than the documented one.
+// Returns the problems found, sorted by position.
+func (a *Analyzer) CheckExports(dir string) ([]Diagnostic, error) {
+	return a.checkExports(func(fn func(path string) error) error {
+		return walkDirectory(dir, fn)
+	})
+}
+
+// CheckExportsPatterns: like CheckExports, for the Go files matched by package patterns
+// and not excluded (see ScanPatterns)
+func (a *Analyzer) CheckExportsPatterns(patterns, excludes []string) ([]Diagnostic, error) {
+	return a.checkExports(func(fn func(path string) error) error {
+		return walkPatterns(patterns, excludes, fn)
+	})
+}
+
+// checkExports: lints the packages of the files found by walk
+func (a *Analyzer) checkExports(walk func(fn func(path string) error) error) ([]Diagnostic, error) {
+	fset := token.NewFileSet()
+
+	keys, files, err := parsePackages(fset, walk)
+	if err != nil {
+		return nil, err
+	}
+
+	var diagnostics []Diagnostic
+	for _, key := range keys {
+		pkg := &lintPackage{
+			files: files[key],
+			funcs: make(map[string]*ast.FuncDecl),
+			vars:  make(map[string]*ast.CompositeLit),
+		}
+		diagnostics = append(diagnostics, a.lintPackage(fset, pkg)...)
+	}
+
+	sortDiagnostics(diagnostics)
+
+	return diagnostics, nil
+}
+
+// lintPackage: compares the exports and annotations of a single package
+func (a *Analyzer) lintPackage(fset *token.FileSet, pkg *lintPackage) []Diagnostic {
+	pkg.index()
+
+	var diagnostics []Diagnostic
+	report := func(pos token.Pos, format string, args ...interface{}) {
+		diagnostics = append(diagnostics, Diagnostic{Pos: fset.Position(pos), Message: fmt.Sprintf(format, args...)})
+	}
+
+	exports := pkg.exports()
+	exported := make(map[string]bool)
+
+	for _, entry := range exports {
+		if entry.target == "" {
+			report(entry.pos, "exported function %q is a closure and cannot be documented, use a named function", entry.name)
+			continue
+		}
+		exported[entry.target] = true
+
+		funcDecl, ok := pkg.funcs[entry.target]
+		if !ok {
+			// Declared in another package
+			continue
+		}
+
+		kind, name := a.documentedName(funcDecl)
+		switch {
+		case kind == "":
+			report(entry.pos, "exported function %q (%s) has no @luafunc or @luamethod annotation", entry.name, entry.target)
+		case name != entry.name:
+			report(entry.pos, "exported function %q (%s) is documented as %s %s", entry.name, entry.target, kind, name)
+		}
+	}
+
+	// Packages without exports only carry documentation, e.g. helpers of another package
+	if len(exports) == 0 {
+		return diagnostics
+	}
+
+	for _, file := range pkg.files {
+		for _, decl := range file.Decls {
+			funcDecl, ok := decl.(*ast.FuncDecl)
+			if !ok || exported[funcDecl.Name.Name] {
+				continue
+			}
+
+			if kind, name := a.documentedName(funcDecl); kind != "" {
+				report(funcDecl.Name.Pos(), "%s is documented as %s %s but never exported", funcDecl.Name.Name, kind, name)
+			}
+		}
+	}
+
+	return diagnostics
+}
+
+// documentedName: returns the annotation kind (@luafunc or @luamethod) and Lua name of a function
+func (a *Analyzer) documentedName(funcDecl *ast.FuncDecl) (string, string) {
+	if funcDecl.Doc == nil {
+		return "", ""
+	}
+
+	comment := funcDecl.Doc.Text()
+	if fn := a.extractFunction(comment); fn != nil {
+		return "@luafunc", fn.Name
+	}
+	if method := a.extractMethod(comment); method != nil {
+		return "@luamethod", method.method.Name
+	}
+
+	return "", ""
+}
+
+// index: records the functions and literal-initialized variables of the package
+func (p *lintPackage) index() {
+	for _, file := range p.files {
+		ast.Inspect(file, func(n ast.Node) bool {
+			switch node := n.(type) {
+			case *ast.FuncDecl:
+				p.funcs[node.Name.Name] = node
+			case *ast.ValueSpec:
+				for i, name := range node.Names {
+					if i < len(node.Values) {
+						if lit, ok := node.Values[i].(*ast.CompositeLit); ok {
+							p.vars[name.Name] = lit
+						}
+					}
+				}
+			case *ast.AssignStmt:
+				for i, lhs := range node.Lhs {
+					ident, ok := lhs.(*ast.Ident)
+					if !ok || i >= len(node.Rhs) {
+						continue
+					}
+					if lit, ok := node.Rhs[i].(*ast.CompositeLit); ok {
+						p.vars[ident.Name] = lit
+					}
+				}
+			}
+			return true
+		})
+	}
+}
+
+// exports: collects the functions exported with L.SetFuncs and L.SetField
+func (p *lintPackage) exports() []exportEntry {
+	var entries []exportEntry
+	seen := make(map[*ast.CompositeLit]bool)
+
+	for _, file := range p.files {
+		ast.Inspect(file, func(n ast.Node) bool {
+			call, ok := n.(*ast.CallExpr)
+			if !ok {
+				return true
+			}
+
+			sel, ok := call.Fun.(*ast.SelectorExpr)
+			if !ok {
+				return true
+			}
+
+			switch {
+			case sel.Sel.Name == "SetFuncs" && len(call.Args) >= 2:
+				lit := p.resolveLiteral(call.Args[1])
+				if lit == nil || seen[lit] {
+					return true
+				}
+				seen[lit] = true
+
+				for _, elt := range lit.Elts {
+					kv, ok := elt.(*ast.KeyValueExpr)
+					if !ok {
+						continue
+					}
+					if entry, ok := exportedFunction(kv.Key, kv.Value); ok {
+						entries = append(entries, entry)
+					}
+				}
+
+			case sel.Sel.Name == "SetField" && len(call.Args) == 3:
+				inner, ok := call.Args[2].(*ast.CallExpr)
+				if !ok {
+					return true
+				}
+				if innerSel, ok := inner.Fun.(*ast.SelectorExpr); !ok || innerSel.Sel.Name != "NewFunction" || len(inner.Args) != 1 {
+					return true
+				}
+				if entry, ok := exportedFunction(call.Args[1], inner.Args[0]); ok {
+					entries = append(entries, entry)
+				}
+			}
+
+			return true
+		})
+	}
+
+	return entries
+}
+
+// resolveLiteral: finds the map literal passed to L.SetFuncs, directly, through a
+// variable or through a function returning it
+func (p *lintPackage) resolveLiteral(expr ast.Expr) *ast.CompositeLit {
+	switch e := expr.(type) {
+	case *ast.CompositeLit:
+		return e
+	case *ast.Ident:
+		return p.vars[e.Name]
+	case *ast.CallExpr:
+		ident, ok := e.Fun.(*ast.Ident)
+		if !ok {
+			return nil
+		}
+
+		funcDecl, ok := p.funcs[ident.Name]
+		if !ok || funcDecl.Body == nil {
+			return nil
+		}
+
+		var lit *ast.CompositeLit
+		ast.Inspect(funcDecl.Body, func(n ast.Node) bool {
+			if ret, ok := n.(*ast.ReturnStmt); ok && len(ret.Results) == 1 && lit == nil {
+				lit = p.resolveLiteral(ret.Results[0])
+			}
+			return lit == nil
+		})
+		return lit
+	}
+
+	return nil
+}
+
+// exportedFunction: builds an export entry from a string key and a function value
+func exportedFunction(key, value ast.Expr) (exportEntry, bool) {
+	basic, ok := key.(*ast.BasicLit)
+	if !ok || basic.Kind != token.STRING {
+		return exportEntry{}, false
+	}
+
+	name, err := strconv.Unquote(basic.Value)
+	if err != nil {
+		return exportEntry{}, false
+	}
+
+	entry := exportEntry{name: name, pos: basic.Pos()}
+	switch v := value.(type) {
+	case *ast.Ident:
+		entry.target = v.Name
+	case *ast.SelectorExpr:
+		// Method values (client.get) and functions of other packages
+		entry.target = v.Sel.Name
+	case *ast.FuncLit:
+		entry.target = wrappedFunction(v)
+	default:
+		return exportEntry{}, false
+	}
+
+	return entry, true
+}
+
+// wrappedFunction: returns the function called by a closure of the form
+// func(L *lua.LState) int { return fn(L, ...) }, used to bind extra arguments
+func wrappedFunction(lit *ast.FuncLit) string {
+	if len(lit.Body.List) != 1 {
+		return ""
+	}
+
+	ret, ok := lit.Body.List[0].(*ast.ReturnStmt)
+	if !ok || len(ret.Results) != 1 {
+		return ""
+	}
+
+	call, ok := ret.Results[0].(*ast.CallExpr)
+	if !ok {
+		return ""
+	}
+
+	switch fn := call.Fun.(type) {
+	case *ast.Ident:
+		return fn.Name
+	case *ast.SelectorExpr:
+		return fn.Sel.Name
+	}
+
+	return ""
+}
+
+// sortDiagnostics: sorts diagnostics by file and line
+func sortDiagnostics(diagnostics []Diagnostic) {
+	sort.SliceStable(diagnostics, func(i, j int) bool {
+		pi, pj := diagnostics[i].Pos, diagnostics[j].Pos
+		if pi.Filename != pj.Filename {
+			return pi.Filename < pj.Filename
+		}
+		return pi.Line < pj.Line
+	})
+}
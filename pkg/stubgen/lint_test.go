@@ -0,0 +1,75 @@
+// Copyright (c) 2024-2025 Thomas Maurice
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package stubgen
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestAnalyzer_CheckExports(t *testing.T) {
+	a := NewAnalyzer()
+	diagnostics, err := a.CheckExports("testdata/lint")
+	if err != nil {
+		t.Fatalf("CheckExports failed: %v", err)
+	}
+
+	expected := []struct {
+		line    int
+		message string
+	}{
+		{41, `exported function "anonymous" is a closure and cannot be documented, use a named function`},
+		{51, `exported function "upper" (lower) is documented as @luafunc to_lower`},
+		{52, `exported function "trim" (trim) has no @luafunc or @luamethod annotation`},
+		{60, `exported function "reset" (bufferReset) is documented as @luamethod clear`},
+		{95, `reverse is documented as @luafunc reverse but never exported`},
+	}
+
+	if len(diagnostics) != len(expected) {
+		for _, d := range diagnostics {
+			t.Log(d)
+		}
+		t.Fatalf("Expected %d diagnostics, got %d", len(expected), len(diagnostics))
+	}
+
+	for i, exp := range expected {
+		d := diagnostics[i]
+		if filepath.Base(d.Pos.Filename) != "lint.go" || d.Pos.Line != exp.line {
+			t.Errorf("Diagnostic %d: expected lint.go:%d, got %s:%d", i, exp.line, d.Pos.Filename, d.Pos.Line)
+		}
+		if d.Message != exp.message {
+			t.Errorf("Diagnostic %d: expected %q, got %q", i, exp.message, d.Message)
+		}
+	}
+}
+
+func TestAnalyzer_CheckExportsModules(t *testing.T) {
+	// Every function exported by the bundled modules must be documented under its Lua name
+	a := NewAnalyzer()
+	diagnostics, err := a.CheckExports("../modules")
+	if err != nil {
+		t.Fatalf("CheckExports failed: %v", err)
+	}
+
+	for _, d := range diagnostics {
+		t.Errorf("Unexpected diagnostic: %s", d)
+	}
+}
@@ -0,0 +1,222 @@
+// Copyright (c) 2024-2025 Thomas Maurice
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+package stubgen
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// DefaultRuntimeVersion: the Lua version implemented by gopher-lua
+const DefaultRuntimeVersion = "Lua 5.1"
+
+// DefaultDisabledDiagnostics: LuaLS diagnostics that are false positives on generated stubs.
+// duplicate-doc-field is raised by the Kubernetes classes, many of which share field names.
+var DefaultDisabledDiagnostics = []string{"duplicate-doc-field"}
+
+// LuarcConfig: the LuaLS settings stubgen manages in a .luarc.json
+type LuarcConfig struct {
+	Library        []string // Generated stub directories or files, relative to the .luarc.json
+	Globals        []string // Globals injected into scripts by the host
+	Disable        []string // Diagnostics to disable, DefaultDisabledDiagnostics if nil
+	RuntimeVersion string   // DefaultRuntimeVersion if empty
+}
+
+// MergeLuarc: merges the settings of config into the content of an existing .luarc.json (nil or empty
+// to start from scratch) and returns the new content.
+//
+// User settings are never removed: lists are extended with the missing entries, and the runtime version
+// and checkThirdParty are only set when absent. Settings can be nested ("workspace": {"library": ...}) or
+// dotted ("workspace.library", "Lua.workspace.library"); existing ones are updated where they are.
+func MergeLuarc(existing []byte, config LuarcConfig) ([]byte, error) {
+	settings := make(map[string]interface{})
+
+	if len(bytes.TrimSpace(existing)) > 0 {
+		decoder := json.NewDecoder(bytes.NewReader(existing))
+		decoder.UseNumber()
+		if err := decoder.Decode(&settings); err != nil {
+			return nil, fmt.Errorf("invalid .luarc.json: %w", err)
+		}
+	}
+
+	runtimeVersion := config.RuntimeVersion
+	if runtimeVersion == "" {
+		runtimeVersion = DefaultRuntimeVersion
+	}
+	disable := config.Disable
+	if disable == nil {
+		disable = DefaultDisabledDiagnostics
+	}
+
+	if err := setDefault(settings, "runtime.version", runtimeVersion); err != nil {
+		return nil, err
+	}
+	if err := mergeList(settings, "workspace.library", config.Library); err != nil {
+		return nil, err
+	}
+	if len(config.Library) > 0 {
+		if err := setDefault(settings, "workspace.checkThirdParty", false); err != nil {
+			return nil, err
+		}
+	}
+	if err := mergeList(settings, "diagnostics.globals", config.Globals); err != nil {
+		return nil, err
+	}
+	if err := mergeList(settings, "diagnostics.disable", disable); err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(settings); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// WriteLuarc: creates or updates the .luarc.json at path with MergeLuarc
+func WriteLuarc(path string, config LuarcConfig) error {
+	existing, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	content, err := MergeLuarc(existing, config)
+	if err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+
+	return os.WriteFile(path, content, 0644)
+}
+
+// luarcSetting: a setting found in a .luarc.json, with the object holding it
+type luarcSetting struct {
+	parent map[string]interface{}
+	key    string
+	value  interface{}
+	found  bool
+}
+
+// findSetting: looks up a setting by its dotted name, in its dotted, "Lua."-prefixed and nested forms.
+// When it is absent, the returned setting points at where the nested form goes.
+func findSetting(settings map[string]interface{}, name string) (luarcSetting, error) {
+	for _, key := range []string{name, "Lua." + name} {
+		if value, ok := settings[key]; ok {
+			return luarcSetting{parent: settings, key: key, value: value, found: true}, nil
+		}
+	}
+
+	parts := strings.Split(name, ".")
+	parent := settings
+	for _, part := range parts[:len(parts)-1] {
+		child, ok := parent[part]
+		if !ok {
+			child = make(map[string]interface{})
+			parent[part] = child
+		}
+
+		object, ok := child.(map[string]interface{})
+		if !ok {
+			return luarcSetting{}, fmt.Errorf("%s: expected an object, got %s", part, jsonKind(child))
+		}
+		parent = object
+	}
+
+	key := parts[len(parts)-1]
+	value, found := parent[key]
+	return luarcSetting{parent: parent, key: key, value: value, found: found}, nil
+}
+
+// setDefault: sets a setting unless the user already did
+func setDefault(settings map[string]interface{}, name string, value interface{}) error {
+	setting, err := findSetting(settings, name)
+	if err != nil {
+		return err
+	}
+
+	if !setting.found {
+		setting.parent[setting.key] = value
+	}
+	return nil
+}
+
+// mergeList: appends the missing values to a list setting, keeping the user's entries first
+func mergeList(settings map[string]interface{}, name string, values []string) error {
+	if len(values) == 0 {
+		return nil
+	}
+
+	setting, err := findSetting(settings, name)
+	if err != nil {
+		return err
+	}
+
+	var list []interface{}
+	if setting.found {
+		existing, ok := setting.value.([]interface{})
+		if !ok {
+			return fmt.Errorf("%s: expected an array, got %s", name, jsonKind(setting.value))
+		}
+		list = existing
+	}
+
+	present := make(map[string]bool)
+	for _, item := range list {
+		if s, ok := item.(string); ok {
+			present[s] = true
+		}
+	}
+
+	for _, value := range values {
+		if !present[value] {
+			present[value] = true
+			list = append(list, value)
+		}
+	}
+
+	setting.parent[setting.key] = list
+	return nil
+}
+
+// jsonKind: describes the JSON type of a decoded value, for error messages
+func jsonKind(value interface{}) string {
+	switch value.(type) {
+	case map[string]interface{}:
+		return "an object"
+	case []interface{}:
+		return "an array"
+	case string:
+		return "a string"
+	case bool:
+		return "a boolean"
+	case json.Number:
+		return "a number"
+	case nil:
+		return "null"
+	}
+	return fmt.Sprintf("%T", value)
+}
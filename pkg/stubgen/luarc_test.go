@@ -0,0 +1,171 @@
+// Copyright (c) 2024-2025 Thomas Maurice
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+package stubgen
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestMergeLuarc(t *testing.T) {
+	config := LuarcConfig{
+		Library: []string{"library"},
+		Globals: []string{"pod"},
+	}
+
+	tests := []struct {
+		name     string
+		existing string
+		expected string
+	}{
+		{
+			name:     "new file",
+			existing: "",
+			expected: `{
+  "diagnostics": {"disable": ["duplicate-doc-field"], "globals": ["pod"]},
+  "runtime": {"version": "Lua 5.1"},
+  "workspace": {"checkThirdParty": false, "library": ["library"]}
+}`,
+		},
+		{
+			name: "keeps user settings",
+			existing: `{
+  "runtime": {"version": "LuaJIT", "path": ["?.lua"]},
+  "workspace": {"library": ["vendor/lua", "library"], "checkThirdParty": true},
+  "diagnostics": {"disable": ["lowercase-global"], "severity": {"unused-local": "Hint"}},
+  "hint": {"enable": true, "arrayIndex": 2}
+}`,
+			expected: `{
+  "diagnostics": {
+    "disable": ["lowercase-global", "duplicate-doc-field"],
+    "globals": ["pod"],
+    "severity": {"unused-local": "Hint"}
+  },
+  "hint": {"arrayIndex": 2, "enable": true},
+  "runtime": {"path": ["?.lua"], "version": "LuaJIT"},
+  "workspace": {"checkThirdParty": true, "library": ["vendor/lua", "library"]}
+}`,
+		},
+		{
+			name: "dotted settings",
+			existing: `{
+  "Lua.workspace.library": ["vendor/lua"],
+  "diagnostics.globals": ["pod", "request"],
+  "runtime.version": "Lua 5.1"
+}`,
+			expected: `{
+  "Lua.workspace.library": ["vendor/lua", "library"],
+  "diagnostics": {"disable": ["duplicate-doc-field"]},
+  "diagnostics.globals": ["pod", "request"],
+  "runtime.version": "Lua 5.1",
+  "workspace": {"checkThirdParty": false}
+}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			merged, err := MergeLuarc([]byte(tt.existing), config)
+			if err != nil {
+				t.Fatalf("MergeLuarc failed: %v", err)
+			}
+
+			var got, expected interface{}
+			if err := json.Unmarshal(merged, &got); err != nil {
+				t.Fatalf("MergeLuarc returned invalid JSON: %v\n%s", err, merged)
+			}
+			if err := json.Unmarshal([]byte(tt.expected), &expected); err != nil {
+				t.Fatalf("Invalid expected JSON: %v", err)
+			}
+			if !reflect.DeepEqual(got, expected) {
+				t.Errorf("Expected:\n%s\nGot:\n%s", tt.expected, merged)
+			}
+
+			// Merging again changes nothing
+			again, err := MergeLuarc(merged, config)
+			if err != nil {
+				t.Fatalf("MergeLuarc failed on its own output: %v", err)
+			}
+			if string(again) != string(merged) {
+				t.Errorf("Expected merging to be idempotent, got:\n%s", again)
+			}
+		})
+	}
+}
+
+func TestMergeLuarcErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		existing string
+		errMsg   string
+	}{
+		{"invalid JSON", `{"workspace": `, "invalid .luarc.json"},
+		{"list of another type", `{"workspace": {"library": "library"}}`, "workspace.library: expected an array, got a string"},
+		{"object of another type", `{"diagnostics": true}`, "diagnostics: expected an object, got a boolean"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := MergeLuarc([]byte(tt.existing), LuarcConfig{Library: []string{"library"}, Globals: []string{"pod"}})
+			if err == nil || !strings.Contains(err.Error(), tt.errMsg) {
+				t.Errorf("Expected error containing %q, got %v", tt.errMsg, err)
+			}
+		})
+	}
+}
+
+func TestWriteLuarc(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".luarc.json")
+
+	if err := WriteLuarc(path, LuarcConfig{Library: []string{"library"}}); err != nil {
+		t.Fatalf("WriteLuarc failed: %v", err)
+	}
+	if err := WriteLuarc(path, LuarcConfig{Library: []string{"types"}, Disable: []string{}}); err != nil {
+		t.Fatalf("WriteLuarc failed on an existing file: %v", err)
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read %s: %v", path, err)
+	}
+
+	var settings struct {
+		Workspace struct {
+			Library []string `json:"library"`
+		} `json:"workspace"`
+		Diagnostics struct {
+			Disable []string `json:"disable"`
+		} `json:"diagnostics"`
+	}
+	if err := json.Unmarshal(content, &settings); err != nil {
+		t.Fatalf("Invalid .luarc.json: %v", err)
+	}
+
+	if !reflect.DeepEqual(settings.Workspace.Library, []string{"library", "types"}) {
+		t.Errorf("Expected library [library types], got %v", settings.Workspace.Library)
+	}
+	if !reflect.DeepEqual(settings.Diagnostics.Disable, []string{"duplicate-doc-field"}) {
+		t.Errorf("Expected disable [duplicate-doc-field], got %v", settings.Diagnostics.Disable)
+	}
+}
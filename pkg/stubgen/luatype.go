@@ -0,0 +1,346 @@
+// Copyright (c) 2024-2025 Thomas Maurice
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+package stubgen
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// luaIdentifier: matches a Lua identifier
+var luaIdentifier = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// luaQualifiedName: matches a dotted Lua name, such as log.Logger
+var luaQualifiedName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*(\.[A-Za-z_][A-Za-z0-9_]*)*$`)
+
+// luaTypeParser: recursive descent parser for the LuaLS type expressions used in annotations.
+// It only validates the syntax, names are not resolved.
+//
+// Grammar:
+//
+//	type    = postfix { "|" postfix }
+//	postfix = primary { "[]" | "?" }
+//	primary = name [ "<" type { "," type } ">" ] | "fun" "(" [ params ] ")" [ ":" type { "," type } ]
+//	        | "{" [ field { "," field } ] "}" | "(" type ")" | string | number | "..."
+//	params  = param { "," param }
+//	param   = ( name [ "?" ] | "..." ) [ ":" type ]
+//	field   = ( name | "[" type "]" ) ":" type
+type luaTypeParser struct {
+	tokens []string
+	pos    int
+}
+
+// validateLuaType: checks that a string is a well-formed Lua type expression
+func validateLuaType(expr string) error {
+	tokens, err := tokenizeLuaType(expr)
+	if err != nil {
+		return err
+	}
+	if len(tokens) == 0 {
+		return fmt.Errorf("empty type")
+	}
+
+	p := &luaTypeParser{tokens: tokens}
+	if err := p.parseType(); err != nil {
+		return err
+	}
+	if p.pos < len(p.tokens) {
+		return fmt.Errorf("unexpected %q", p.tokens[p.pos])
+	}
+	return nil
+}
+
+// validateFunctionType: checks that a string is a well-formed fun(...) type, as used by @luaoverload
+func validateFunctionType(expr string) error {
+	if !strings.HasPrefix(strings.TrimSpace(expr), "fun(") {
+		return fmt.Errorf("expected a fun(...) type")
+	}
+	return validateLuaType(expr)
+}
+
+// tokenizeLuaType: splits a type expression into names, literals and punctuation
+func tokenizeLuaType(expr string) ([]string, error) {
+	var tokens []string
+
+	for i := 0; i < len(expr); {
+		c := rune(expr[i])
+
+		switch {
+		case unicode.IsSpace(c):
+			i++
+		case strings.HasPrefix(expr[i:], "..."):
+			tokens = append(tokens, "...")
+			i += 3
+		case strings.HasPrefix(expr[i:], "[]"):
+			tokens = append(tokens, "[]")
+			i += 2
+		case strings.ContainsRune("|?[]<>(){},:", c):
+			tokens = append(tokens, string(c))
+			i++
+		case c == '"' || c == '\'' || c == '`':
+			end := strings.IndexRune(expr[i+1:], c)
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated string literal")
+			}
+			tokens = append(tokens, expr[i:i+end+2])
+			i += end + 2
+		case c == '_' || c == '-' || unicode.IsLetter(c) || unicode.IsDigit(c):
+			j := i + 1
+			for j < len(expr) {
+				d := rune(expr[j])
+				if d != '_' && d != '.' && !unicode.IsLetter(d) && !unicode.IsDigit(d) {
+					break
+				}
+				j++
+			}
+			tokens = append(tokens, expr[i:j])
+			i = j
+		default:
+			return nil, fmt.Errorf("unexpected character %q", c)
+		}
+	}
+
+	return tokens, nil
+}
+
+// peek: returns the current token, or an empty string at the end of the input
+func (p *luaTypeParser) peek() string {
+	if p.pos < len(p.tokens) {
+		return p.tokens[p.pos]
+	}
+	return ""
+}
+
+// expect: consumes the given token or fails
+func (p *luaTypeParser) expect(token string) error {
+	if p.peek() != token {
+		if p.pos >= len(p.tokens) {
+			return fmt.Errorf("expected %q at end of type", token)
+		}
+		return fmt.Errorf("expected %q, got %q", token, p.peek())
+	}
+	p.pos++
+	return nil
+}
+
+// parseType: parses a union of types
+func (p *luaTypeParser) parseType() error {
+	if err := p.parsePostfix(); err != nil {
+		return err
+	}
+	for p.peek() == "|" {
+		p.pos++
+		if err := p.parsePostfix(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// parsePostfix: parses a type followed by array ([]) or optional (?) markers
+func (p *luaTypeParser) parsePostfix() error {
+	if err := p.parsePrimary(); err != nil {
+		return err
+	}
+	for p.peek() == "[]" || p.peek() == "?" {
+		p.pos++
+	}
+	return nil
+}
+
+// parsePrimary: parses a named, function, table, parenthesized or literal type
+func (p *luaTypeParser) parsePrimary() error {
+	token := p.peek()
+
+	switch {
+	case token == "":
+		return fmt.Errorf("unexpected end of type")
+	case token == "fun":
+		p.pos++
+		return p.parseFunction()
+	case token == "{":
+		p.pos++
+		return p.parseTable()
+	case token == "(":
+		p.pos++
+		if err := p.parseType(); err != nil {
+			return err
+		}
+		return p.expect(")")
+	case token == "...":
+		p.pos++
+		return nil
+	case strings.ContainsAny(token[:1], "\"'`"):
+		p.pos++
+		return nil
+	case isLuaNumber(token):
+		p.pos++
+		return nil
+	case luaQualifiedName.MatchString(token):
+		p.pos++
+		if p.peek() != "<" {
+			return nil
+		}
+		p.pos++
+		if err := p.parseType(); err != nil {
+			return err
+		}
+		for p.peek() == "," {
+			p.pos++
+			if err := p.parseType(); err != nil {
+				return err
+			}
+		}
+		return p.expect(">")
+	}
+
+	return fmt.Errorf("unexpected %q", token)
+}
+
+// parseFunction: parses the parameters and returns of a fun(...) type
+func (p *luaTypeParser) parseFunction() error {
+	if err := p.expect("("); err != nil {
+		return err
+	}
+
+	for p.peek() != ")" {
+		name := p.peek()
+		switch {
+		case name == "...":
+			p.pos++
+		case luaIdentifier.MatchString(name):
+			p.pos++
+			if p.peek() == "?" {
+				p.pos++
+			}
+		default:
+			return fmt.Errorf("invalid parameter name %q", name)
+		}
+
+		if p.peek() == ":" {
+			p.pos++
+			if err := p.parseType(); err != nil {
+				return err
+			}
+		}
+
+		if p.peek() != "," {
+			break
+		}
+		p.pos++
+	}
+
+	if err := p.expect(")"); err != nil {
+		return err
+	}
+
+	if p.peek() != ":" {
+		return nil
+	}
+	p.pos++
+
+	if err := p.parseType(); err != nil {
+		return err
+	}
+	for p.peek() == "," {
+		p.pos++
+		if err := p.parseType(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// parseTable: parses the fields of a { ... } table literal type
+func (p *luaTypeParser) parseTable() error {
+	for p.peek() != "}" {
+		if p.peek() == "[" {
+			p.pos++
+			if err := p.parseType(); err != nil {
+				return err
+			}
+			if err := p.expect("]"); err != nil {
+				return err
+			}
+		} else if luaIdentifier.MatchString(p.peek()) {
+			p.pos++
+		} else {
+			return fmt.Errorf("invalid table field %q", p.peek())
+		}
+
+		if err := p.expect(":"); err != nil {
+			return err
+		}
+		if err := p.parseType(); err != nil {
+			return err
+		}
+
+		if p.peek() != "," {
+			break
+		}
+		p.pos++
+	}
+
+	return p.expect("}")
+}
+
+// cutLuaType: splits an annotation into its leading type and the rest, so that types with spaces
+// inside brackets or after a separator (table<string, number>, fun(a: string): boolean) are kept whole
+func cutLuaType(s string) (string, string) {
+	s = strings.TrimSpace(s)
+	depth := 0
+	for i, r := range s {
+		switch {
+		case strings.ContainsRune("<({[", r):
+			depth++
+		case strings.ContainsRune(">)}]", r):
+			depth--
+		case unicode.IsSpace(r) && depth <= 0:
+			before := strings.TrimRightFunc(s[:i], unicode.IsSpace)
+			after := strings.TrimSpace(s[i:])
+			if strings.HasSuffix(before, ":") || strings.HasSuffix(before, ",") || strings.HasSuffix(before, "|") ||
+				strings.HasPrefix(after, ":") || strings.HasPrefix(after, "|") {
+				continue
+			}
+			return before, after
+		}
+	}
+
+	// Unbalanced brackets are reported by validateLuaType, on the first word only
+	if depth > 0 {
+		if i := strings.IndexFunc(s, unicode.IsSpace); i >= 0 {
+			return s[:i], strings.TrimSpace(s[i:])
+		}
+	}
+
+	return s, ""
+}
+
+// isLuaNumber: checks whether a token is a numeric literal
+func isLuaNumber(token string) bool {
+	if _, err := strconv.ParseInt(token, 0, 64); err == nil {
+		return true
+	}
+	_, err := strconv.ParseFloat(token, 64)
+	return err == nil
+}
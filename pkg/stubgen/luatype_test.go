@@ -0,0 +1,97 @@
+// Copyright (c) 2024-2025 Thomas Maurice
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+package stubgen
+
+import "testing"
+
+func TestValidateLuaType(t *testing.T) {
+	tests := []struct {
+		expr  string
+		valid bool
+	}{
+		{"string", true},
+		{"string|nil", true},
+		{"table[]|nil", true},
+		{"log.Logger", true},
+		{"string?", true},
+		{"table<string, number>", true},
+		{"fun(id: ID): boolean", true},
+		{"fun(a: string, b?: number, ...: any): string, string|nil", true},
+		{"fun()", true},
+		{"{ name: string, [string]: any }", true},
+		{"(string|number)[]", true},
+		{`"fast"|"safe"|1|-1`, true},
+		{"`T`", true},
+		{"", false},
+		{"string|", false},
+		{"table<string", false},
+		{"fun(: string)", false},
+		{"{ name string }", false},
+		{"string]", false},
+		{`"unterminated`, false},
+		{"string$", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			err := validateLuaType(tt.expr)
+			if tt.valid && err != nil {
+				t.Errorf("Expected %q to be valid, got %v", tt.expr, err)
+			}
+			if !tt.valid && err == nil {
+				t.Errorf("Expected %q to be invalid", tt.expr)
+			}
+		})
+	}
+}
+
+func TestValidateFunctionType(t *testing.T) {
+	if err := validateFunctionType("fun(key: string): any"); err != nil {
+		t.Errorf("Expected valid overload, got %v", err)
+	}
+	if err := validateFunctionType("string"); err == nil {
+		t.Error("Expected non-function overload to be rejected")
+	}
+}
+
+func TestCutLuaType(t *testing.T) {
+	tests := []struct {
+		annotation string
+		typ        string
+		rest       string
+	}{
+		{"string The name", "string", "The name"},
+		{"table<string, number> weights Weights by name", "table<string, number>", "weights Weights by name"},
+		{"fun(a: string, b: number): boolean callback", "fun(a: string, b: number): boolean", "callback"},
+		{"{ name: string } options", "{ name: string }", "options"},
+		{"string | nil err", "string | nil", "err"},
+		{"table<string Unclosed", "table<string", "Unclosed"},
+		{"integer", "integer", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.annotation, func(t *testing.T) {
+			typ, rest := cutLuaType(tt.annotation)
+			if typ != tt.typ || rest != tt.rest {
+				t.Errorf("Expected (%q, %q), got (%q, %q)", tt.typ, tt.rest, typ, rest)
+			}
+		})
+	}
+}
@@ -0,0 +1,212 @@
+// Copyright (c) 2024-2025 Thomas Maurice
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+package stubgen
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// ScanPatterns: scans the Go files matched by package patterns, like the go tool does:
+// "./pkg/modules/json" scans a single directory, "./pkg/modules/..." a directory and all its
+// subdirectories except testdata, vendor and those starting with "." or "_".
+// Files and directories matching one of the exclude globs are skipped. Globs use path.Match
+// syntax plus "**" for any number of directories, and globs without a "/" match base names,
+// so "stubgen" skips every stubgen directory.
+//
+// Annotations are grouped by @luamodule across all the roots; a module name declared by
+// two packages is reported as a diagnostic, like the other annotation problems.
+func (a *Analyzer) ScanPatterns(patterns []string, excludes []string) ([]Diagnostic, error) {
+	a.beginScan()
+
+	if err := walkPatterns(patterns, excludes, a.parseFile); err != nil {
+		return nil, err
+	}
+
+	return a.endScan(), nil
+}
+
+// walkPatterns: calls fn with every Go file, tests excluded, matched by the patterns, see ScanPatterns
+func walkPatterns(patterns, excludes []string, fn func(path string) error) error {
+	for _, glob := range excludes {
+		if _, err := path.Match(glob, ""); err != nil {
+			return fmt.Errorf("invalid exclude pattern %q: %w", glob, err)
+		}
+	}
+
+	for _, pattern := range patterns {
+		root, recursive := splitPattern(pattern)
+		if err := walkRoot(root, recursive, excludes, fn); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// walkDirectory: calls fn with every Go file, tests excluded, under dir and all its subdirectories
+func walkDirectory(dir string, fn func(path string) error) error {
+	return filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if info.IsDir() || !strings.HasSuffix(path, ".go") || strings.HasSuffix(path, "_test.go") {
+			return nil
+		}
+
+		return fn(path)
+	})
+}
+
+// parsePackages: parses the Go files found by walk (walkDirectory or walkPatterns) once each,
+// and groups them by directory and package name. Returns the group keys in walk order.
+func parsePackages(fset *token.FileSet, walk func(fn func(path string) error) error) ([]string, map[string][]*ast.File, error) {
+	packages := make(map[string][]*ast.File)
+	parsed := make(map[string]bool)
+	var keys []string
+
+	err := walk(func(path string) error {
+		if parsed[path] {
+			return nil
+		}
+		parsed[path] = true
+
+		file, err := parser.ParseFile(fset, path, nil, parser.ParseComments)
+		if err != nil {
+			return fmt.Errorf("failed to parse %s: %w", path, err)
+		}
+
+		key := filepath.Dir(path) + ":" + file.Name.Name
+		if _, exists := packages[key]; !exists {
+			keys = append(keys, key)
+		}
+		packages[key] = append(packages[key], file)
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return keys, packages, nil
+}
+
+// walkRoot: calls fn with the Go files of root, and of its subdirectories if recursive
+func walkRoot(root string, recursive bool, excludes []string, fn func(path string) error) error {
+	info, err := os.Stat(root)
+	if err != nil {
+		return err
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("%s is not a directory", root)
+	}
+
+	return filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if d.IsDir() {
+			if p == root {
+				return nil
+			}
+			if !recursive || skipDirectory(d.Name()) || excluded(p, excludes) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		if !strings.HasSuffix(p, ".go") || strings.HasSuffix(p, "_test.go") || excluded(p, excludes) {
+			return nil
+		}
+
+		return fn(p)
+	})
+}
+
+// splitPattern: returns the root directory of a pattern and whether it is recursive
+func splitPattern(pattern string) (string, bool) {
+	if pattern == "..." {
+		return ".", true
+	}
+	if root, ok := strings.CutSuffix(pattern, "/..."); ok {
+		if root == "" {
+			root = "/"
+		}
+		return filepath.Clean(root), true
+	}
+	return filepath.Clean(pattern), false
+}
+
+// skipDirectory: checks whether a recursive pattern ignores a directory, like the go tool
+func skipDirectory(name string) bool {
+	return name == "testdata" || name == "vendor" || strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_")
+}
+
+// excluded: checks whether a path matches one of the exclude globs
+func excluded(p string, excludes []string) bool {
+	p = path.Clean(filepath.ToSlash(p))
+
+	for _, glob := range excludes {
+		if !strings.Contains(glob, "/") {
+			if ok, _ := path.Match(glob, path.Base(p)); ok {
+				return true
+			}
+			continue
+		}
+
+		if matchGlob(strings.Split(path.Clean(glob), "/"), strings.Split(p, "/")) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// matchGlob: matches path segments against glob segments, "**" matching any number of segments
+func matchGlob(glob, segments []string) bool {
+	for len(glob) > 0 {
+		if glob[0] == "**" {
+			for i := 0; i <= len(segments); i++ {
+				if matchGlob(glob[1:], segments[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+
+		if len(segments) == 0 {
+			return false
+		}
+		if ok, _ := path.Match(glob[0], segments[0]); !ok {
+			return false
+		}
+		glob, segments = glob[1:], segments[1:]
+	}
+
+	return len(segments) == 0
+}
@@ -0,0 +1,311 @@
+// Copyright (c) 2024-2025 Thomas Maurice
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+package stubgen
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// writeModuleTree: writes Go files declaring modules under a temporary directory
+func writeModuleTree(t *testing.T) string {
+	t.Helper()
+
+	files := map[string]string{
+		"a/a.go": `package a
+
+// Loader: creates the alpha module
+//
+// @luamodule alpha
+func Loader(L *lua.LState) int { return 1 }
+
+// first: first function
+//
+// @luafunc first
+func first(L *lua.LState) int { return 0 }
+`,
+		"a/extra.go": `package a
+
+// second: declared in another file of the package
+//
+// @luafunc second
+func second(L *lua.LState) int { return 0 }
+`,
+		"a/testdata/fixture.go": `package fixture
+
+// Loader: ignored by recursive patterns
+//
+// @luamodule fixture
+func Loader(L *lua.LState) int { return 1 }
+`,
+		"b/b.go": `package b
+
+// Loader: declares alpha again
+//
+// @luamodule alpha
+func Loader(L *lua.LState) int { return 1 }
+
+// third: belongs to the duplicate declaration
+//
+// @luafunc third
+func third(L *lua.LState) int { return 0 }
+`,
+		"c/c.go": `package c
+
+// Loader: creates the gamma module
+//
+// @luamodule gamma
+func Loader(L *lua.LState) int { return 1 }
+`,
+		"c/sub/d.go": `package sub
+
+// Loader: creates the delta module
+//
+// @luamodule delta
+func Loader(L *lua.LState) int { return 1 }
+`,
+	}
+
+	root := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+
+	return root
+}
+
+func TestAnalyzer_ScanPatterns(t *testing.T) {
+	root := writeModuleTree(t)
+
+	tests := []struct {
+		name        string
+		patterns    []string
+		excludes    []string
+		modules     []string
+		diagnostics []string
+	}{
+		{
+			name:        "recursive",
+			patterns:    []string{root + "/..."},
+			modules:     []string{"alpha", "delta", "gamma"},
+			diagnostics: []string{"module alpha is already declared in " + filepath.Join(root, "a")},
+		},
+		{
+			name:     "single directory",
+			patterns: []string{filepath.Join(root, "c")},
+			modules:  []string{"gamma"},
+		},
+		{
+			name:     "multiple roots",
+			patterns: []string{filepath.Join(root, "a"), filepath.Join(root, "c") + "/..."},
+			modules:  []string{"alpha", "delta", "gamma"},
+		},
+		{
+			name:     "overlapping roots",
+			patterns: []string{root + "/...", filepath.Join(root, "a")},
+			excludes: []string{"b"},
+			modules:  []string{"alpha", "delta", "gamma"},
+		},
+		{
+			name:     "exclude globs",
+			patterns: []string{root + "/..."},
+			excludes: []string{"b", "**/c/sub", "*/a/extra.go"},
+			modules:  []string{"alpha", "gamma"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := NewAnalyzer()
+			diagnostics, err := a.ScanPatterns(tt.patterns, tt.excludes)
+			if err != nil {
+				t.Fatalf("ScanPatterns failed: %v", err)
+			}
+
+			var modules []string
+			for name := range a.GetModules() {
+				modules = append(modules, name)
+			}
+			sort.Strings(modules)
+
+			if strings.Join(modules, ",") != strings.Join(tt.modules, ",") {
+				t.Errorf("Expected modules %v, got %v", tt.modules, modules)
+			}
+
+			if len(diagnostics) != len(tt.diagnostics) {
+				t.Fatalf("Expected diagnostics %v, got %v", tt.diagnostics, diagnostics)
+			}
+			for i, want := range tt.diagnostics {
+				if diagnostics[i].Message != want {
+					t.Errorf("Expected diagnostic %q, got %q", want, diagnostics[i].Message)
+				}
+			}
+		})
+	}
+}
+
+func TestAnalyzer_ScanPatternsGroupsPackageFiles(t *testing.T) {
+	root := writeModuleTree(t)
+
+	a := NewAnalyzer()
+	if _, err := a.ScanPatterns([]string{root + "/...", filepath.Join(root, "a")}, nil); err != nil {
+		t.Fatalf("ScanPatterns failed: %v", err)
+	}
+
+	alpha := a.GetModules()["alpha"]
+	if alpha.Dir != filepath.Join(root, "a") {
+		t.Errorf("Expected alpha to be declared in %s, got %s", filepath.Join(root, "a"), alpha.Dir)
+	}
+
+	// second comes from another file of the package, third from the duplicate declaration
+	var functions []string
+	for _, fn := range alpha.Functions {
+		functions = append(functions, fn.Name)
+	}
+	if strings.Join(functions, ",") != "first,second" {
+		t.Errorf("Expected functions first,second, got %v", functions)
+	}
+}
+
+func TestAnalyzer_ScanPatternsErrors(t *testing.T) {
+	a := NewAnalyzer()
+
+	if _, err := a.ScanPatterns([]string{"./does-not-exist/..."}, nil); err == nil {
+		t.Error("Expected an error for a missing directory")
+	}
+
+	if _, err := a.ScanPatterns([]string{"."}, []string{"[bad"}); err == nil {
+		t.Error("Expected an error for an invalid exclude pattern")
+	}
+}
+
+func TestMatchGlob(t *testing.T) {
+	tests := []struct {
+		glob  string
+		path  string
+		match bool
+	}{
+		{"pkg/modules/k8sclient", "pkg/modules/k8sclient", true},
+		{"pkg/modules/*", "pkg/modules/json", true},
+		{"pkg/modules/*", "pkg/modules/json/stubgen", false},
+		{"**/stubgen", "pkg/modules/json/stubgen", true},
+		{"**/stubgen", "stubgen", true},
+		{"pkg/**/main.go", "pkg/modules/json/stubgen/main.go", true},
+		{"pkg/**", "pkg", true},
+		{"pkg/**/*.go", "cmd/stubgen/main.go", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.glob+" "+tt.path, func(t *testing.T) {
+			if got := excluded(tt.path, []string{tt.glob}); got != tt.match {
+				t.Errorf("Expected %v, got %v", tt.match, got)
+			}
+		})
+	}
+}
+
+func TestAnalyzer_CheckPatterns(t *testing.T) {
+	tests := []struct {
+		name     string
+		patterns []string
+		excludes []string
+		exports  []string
+		types    []string
+	}{
+		{
+			name:     "recursive",
+			patterns: []string{"testdata/..."},
+			exports:  []string{"lint.go", "vocabulary.go"},
+			types:    []string{"custom_annotations.go", "lint.go", "typecheck.go", "vocabulary.go"},
+		},
+		{
+			name:     "single directory",
+			patterns: []string{"testdata"},
+			exports:  []string{"vocabulary.go"},
+			types:    []string{"custom_annotations.go", "vocabulary.go"},
+		},
+		{
+			name:     "excluded directory",
+			patterns: []string{"testdata/..."},
+			excludes: []string{"lint"},
+			exports:  []string{"vocabulary.go"},
+			types:    []string{"custom_annotations.go", "typecheck.go", "vocabulary.go"},
+		},
+	}
+
+	// files: the sorted base names of the files with diagnostics
+	files := func(diagnostics []Diagnostic) []string {
+		seen := make(map[string]bool)
+		var names []string
+		for _, d := range diagnostics {
+			if name := filepath.Base(d.Pos.Filename); !seen[name] {
+				seen[name] = true
+				names = append(names, name)
+			}
+		}
+		sort.Strings(names)
+		return names
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := NewAnalyzer()
+
+			exports, err := a.CheckExportsPatterns(tt.patterns, tt.excludes)
+			if err != nil {
+				t.Fatalf("CheckExportsPatterns failed: %v", err)
+			}
+			if got := files(exports); strings.Join(got, ",") != strings.Join(tt.exports, ",") {
+				t.Errorf("Expected export diagnostics in %v, got %v", tt.exports, exports)
+			}
+
+			signatures, err := a.CheckSignaturesPatterns(tt.patterns, tt.excludes)
+			if err != nil {
+				t.Fatalf("CheckSignaturesPatterns failed: %v", err)
+			}
+			if got := files(signatures); strings.Join(got, ",") != strings.Join(tt.types, ",") {
+				t.Errorf("Expected signature diagnostics in %v, got %v", tt.types, signatures)
+			}
+		})
+	}
+
+	// Files matched by overlapping roots are only checked once
+	a := NewAnalyzer()
+	once, err := a.CheckExportsPatterns([]string{"testdata/lint"}, nil)
+	if err != nil {
+		t.Fatalf("CheckExportsPatterns failed: %v", err)
+	}
+	twice, err := a.CheckExportsPatterns([]string{"testdata/lint", "testdata/lint"}, nil)
+	if err != nil {
+		t.Fatalf("CheckExportsPatterns failed: %v", err)
+	}
+	if len(once) == 0 || len(once) != len(twice) {
+		t.Errorf("Expected the same diagnostics for overlapping roots, got %d and %d", len(once), len(twice))
+	}
+}
@@ -0,0 +1,69 @@
+// Copyright (c) 2024-2025 Thomas Maurice
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package stubgentest
+
+import (
+	"testing"
+
+	"github.com/thomas-maurice/glua/pkg/stubgen"
+	lua "github.com/yuin/gopher-lua"
+)
+
+// RunExampleTests: runs every example of a module found in dir as a subtest of t, see stubgen.RunExample.
+// It is meant to be called from the tests of a module:
+//
+//	func TestExamples(t *testing.T) {
+//		stubgentest.RunExampleTests(t, ".", "base64", Loader, stubgen.ExampleOptions{})
+//	}
+func RunExampleTests(t *testing.T, dir, moduleName string, loader lua.LGFunction, opts stubgen.ExampleOptions) {
+	t.Helper()
+
+	a := stubgen.NewAnalyzer()
+	if _, err := a.ScanDirectory(dir); err != nil {
+		t.Fatalf("Failed to scan %s: %v", dir, err)
+	}
+
+	examples, err := a.Examples(moduleName)
+	if err != nil {
+		t.Fatalf("Failed to extract examples: %v", err)
+	}
+
+	if len(examples) == 0 {
+		t.Fatalf("No examples found for module %s", moduleName)
+	}
+
+	skip := make(map[string]bool)
+	for _, name := range opts.Skip {
+		skip[name] = true
+	}
+
+	for _, example := range examples {
+		t.Run(example.Name, func(t *testing.T) {
+			if skip[example.Name] {
+				t.Skip("skipped by ExampleOptions")
+			}
+
+			if output, err := stubgen.RunExample(example, moduleName, loader, opts); err != nil {
+				t.Errorf("Example failed: %v\nCode:\n%s\nOutput:\n%s", err, example.Code, output)
+			}
+		})
+	}
+}
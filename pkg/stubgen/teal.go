@@ -0,0 +1,248 @@
+// Copyright (c) 2024-2025 Thomas Maurice
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package stubgen
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/thomas-maurice/glua/pkg/glua"
+)
+
+// GenerateModuleTeal: generates a Teal declaration file (.d.tl) for a single module.
+// The module becomes a local record holding its functions, constants and classes,
+// and LuaLS type annotations are translated to their Teal equivalents.
+//
+// Example output:
+//
+//	local record strings
+//	   has_prefix: function(s: string, prefix: string): boolean
+//	end
+//
+//	return strings
+func (a *Analyzer) GenerateModuleTeal(moduleName string) (string, error) {
+	module, exists := a.modules[moduleName]
+	if !exists {
+		return "", fmt.Errorf("module %s not found", moduleName)
+	}
+
+	var sb strings.Builder
+
+	fmt.Fprintf(&sb, "local record %s\n", moduleName)
+
+	// Classes become nested records
+	for _, class := range module.Classes {
+		a.writeTealClass(&sb, class, moduleName)
+	}
+
+	for _, fn := range module.Functions {
+		fmt.Fprintf(&sb, "   %s: %s\n", glua.LuaFieldName(fn.Name), tealFunctionType(fn.Params, fn.Returns, ""))
+	}
+
+	for _, cnst := range module.Constants {
+		fmt.Fprintf(&sb, "   %s: %s\n", glua.LuaFieldName(cnst.Name), luaTypeToTeal(cnst.Type))
+	}
+
+	sb.WriteString("end\n\n")
+	fmt.Fprintf(&sb, "return %s\n", moduleName)
+
+	// Classes namespaced under the module (e.g. log.Logger) are nested records,
+	// referenced by their short name inside the module record
+	qualified := regexp.MustCompile(`\b` + regexp.QuoteMeta(moduleName) + `\.([A-Za-z_][A-Za-z0-9_]*)`)
+
+	return qualified.ReplaceAllString(sb.String(), "$1"), nil
+}
+
+// writeTealClass: writes a class as a nested Teal record with fields and methods
+func (a *Analyzer) writeTealClass(sb *strings.Builder, class *LuaClass, moduleName string) {
+	recordName := a.getClassLocalName(class.Name, moduleName)
+	// Teal nested record names cannot be qualified
+	recordName = strings.ReplaceAll(recordName, ".", "_")
+
+	fmt.Fprintf(sb, "   record %s\n", recordName)
+
+	for _, field := range class.Fields {
+		fmt.Fprintf(sb, "      %s: %s\n", glua.LuaFieldName(field.Name), luaTypeToTeal(field.Type))
+	}
+
+	for _, method := range class.Methods {
+		fmt.Fprintf(sb, "      %s: %s\n", glua.LuaFieldName(method.Name), tealFunctionType(method.Params, method.Returns, recordName))
+	}
+
+	sb.WriteString("   end\n\n")
+}
+
+// tealFunctionType: builds a Teal function type from parameters and returns.
+// When selfType is set, the function is a method and takes self as first argument.
+func tealFunctionType(params []*LuaParam, returns []*LuaReturn, selfType string) string {
+	var args []string
+
+	if selfType != "" {
+		args = append(args, "self: "+selfType)
+	}
+
+	for _, param := range params {
+		if param.Name == "self" {
+			continue
+		}
+
+		if param.Vararg || param.Name == "..." {
+			args = append(args, "...: "+luaTypeToTeal(param.Type))
+			continue
+		}
+
+		name := param.Name
+		if param.Optional || isNilable(param.Type) {
+			name += "?"
+		}
+		args = append(args, name+": "+luaTypeToTeal(param.Type))
+	}
+
+	signature := "function(" + strings.Join(args, ", ") + ")"
+
+	if len(returns) > 0 {
+		rets := make([]string, len(returns))
+		for i, ret := range returns {
+			rets[i] = luaTypeToTeal(ret.Type)
+		}
+		signature += ": " + strings.Join(rets, ", ")
+	}
+
+	return signature
+}
+
+// isNilable: checks whether a LuaLS type accepts nil
+func isNilable(luaType string) bool {
+	if strings.HasSuffix(luaType, "?") {
+		return true
+	}
+
+	for _, part := range splitTopLevel(luaType, '|') {
+		if strings.TrimSpace(part) == "nil" {
+			return true
+		}
+	}
+
+	return false
+}
+
+// luaTypeToTeal: translates a LuaLS type annotation to a Teal type.
+// Teal values are always nilable, so nil members of unions are dropped.
+//
+// Examples:
+//
+//	string|nil          -> string
+//	string[]            -> {string}
+//	table<string, any>  -> {string:any}
+//	table               -> {any:any}
+//	fun(x: string)      -> function
+func luaTypeToTeal(luaType string) string {
+	luaType = strings.TrimSpace(luaType)
+	luaType = strings.TrimSuffix(luaType, "?")
+
+	// Unions
+	if parts := splitTopLevel(luaType, '|'); len(parts) > 1 {
+		var converted []string
+		for _, part := range parts {
+			part = strings.TrimSpace(part)
+			if part == "nil" {
+				continue
+			}
+			converted = append(converted, luaTypeToTeal(part))
+		}
+
+		if len(converted) == 0 {
+			return "nil"
+		}
+		return strings.Join(converted, " | ")
+	}
+
+	// Parenthesized types
+	if strings.HasPrefix(luaType, "(") && strings.HasSuffix(luaType, ")") {
+		return luaTypeToTeal(luaType[1 : len(luaType)-1])
+	}
+
+	// Arrays
+	if strings.HasSuffix(luaType, "[]") {
+		return "{" + luaTypeToTeal(strings.TrimSuffix(luaType, "[]")) + "}"
+	}
+
+	// Maps
+	if strings.HasPrefix(luaType, "table<") && strings.HasSuffix(luaType, ">") {
+		inner := luaType[len("table<") : len(luaType)-1]
+		kv := splitTopLevel(inner, ',')
+		if len(kv) == 2 {
+			return "{" + luaTypeToTeal(kv[0]) + ":" + luaTypeToTeal(kv[1]) + "}"
+		}
+	}
+
+	// Table literal types have no anonymous Teal equivalent
+	if strings.HasPrefix(luaType, "{") {
+		return "{string:any}"
+	}
+
+	if strings.HasPrefix(luaType, "fun(") || luaType == "function" {
+		return "function"
+	}
+
+	// String literal types
+	if strings.HasPrefix(luaType, `"`) || strings.HasPrefix(luaType, "'") {
+		return "string"
+	}
+
+	switch luaType {
+	case "table":
+		return "{any:any}"
+	case "", "unknown":
+		return "any"
+	case "lightuserdata":
+		return "userdata"
+	}
+
+	return luaType
+}
+
+// splitTopLevel: splits a type annotation on sep, ignoring separators nested in
+// parentheses, angle brackets or braces
+func splitTopLevel(s string, sep rune) []string {
+	var (
+		parts []string
+		depth int
+		start int
+	)
+
+	for i, ch := range s {
+		switch ch {
+		case '(', '<', '{', '[':
+			depth++
+		case ')', '>', '}', ']':
+			depth--
+		case sep:
+			if depth == 0 {
+				parts = append(parts, s[start:i])
+				start = i + 1
+			}
+		}
+	}
+
+	return append(parts, s[start:])
+}
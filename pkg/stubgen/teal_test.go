@@ -0,0 +1,174 @@
+// Copyright (c) 2024-2025 Thomas Maurice
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package stubgen
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLuaTypeToTeal(t *testing.T) {
+	tests := []struct {
+		luaType  string
+		expected string
+	}{
+		{"string", "string"},
+		{"number", "number"},
+		{"any", "any"},
+		{"string|nil", "string"},
+		{"string?", "string"},
+		{"string|number", "string | number"},
+		{"string[]", "{string}"},
+		{"corev1.Container[]", "{corev1.Container}"},
+		{"table<string, number>", "{string:number}"},
+		{"table<string, string[]>", "{string:{string}}"},
+		{"table", "{any:any}"},
+		{"{ name: string }", "{string:any}"},
+		{"fun(id: ID): boolean", "function"},
+		{`"Always"|"Never"`, "string | string"},
+		{"(string|number)[]", "{string | number}"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.luaType, func(t *testing.T) {
+			if result := luaTypeToTeal(tt.luaType); result != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestAnalyzer_GenerateModuleTeal(t *testing.T) {
+	a := NewAnalyzer()
+
+	a.modules["counter"] = &LuaModule{
+		Name: "counter",
+		Functions: []*LuaFunction{
+			{
+				Name: "new",
+				Params: []*LuaParam{
+					{Name: "initial", Type: "number|nil"},
+				},
+				Returns: []*LuaReturn{
+					{Type: "counter.Counter"},
+				},
+			},
+			{
+				Name: "sum",
+				Params: []*LuaParam{
+					{Name: "...", Type: "number"},
+				},
+				Returns: []*LuaReturn{
+					{Type: "number"},
+					{Type: "string|nil"},
+				},
+			},
+		},
+		Classes: []*LuaClass{
+			{
+				Name: "counter.Counter",
+				Fields: []*LuaField{
+					{Name: "step", Type: "number"},
+				},
+				Methods: []*LuaMethod{
+					{
+						Name: "increment",
+						Params: []*LuaParam{
+							{Name: "self", Type: "counter.Counter"},
+							{Name: "amount", Type: "number"},
+						},
+					},
+				},
+			},
+		},
+		Constants: []*LuaConst{
+			{Name: "MAX", Type: "number"},
+		},
+	}
+
+	decls, err := a.GenerateModuleTeal("counter")
+	if err != nil {
+		t.Fatalf("GenerateModuleTeal failed: %v", err)
+	}
+
+	expected := `local record counter
+   record Counter
+      step: number
+      increment: function(self: Counter, amount: number)
+   end
+
+   new: function(initial?: number): Counter
+   sum: function(...: number): number, string
+   MAX: number
+end
+
+return counter
+`
+
+	if decls != expected {
+		t.Errorf("Unexpected Teal declarations.\nExpected:\n%s\nGot:\n%s", expected, decls)
+	}
+
+	if _, err := a.GenerateModuleTeal("missing"); err == nil {
+		t.Error("Expected error for non-existent module, got nil")
+	}
+}
+
+func TestGenerateWithTeal(t *testing.T) {
+	type Settings struct {
+		Name string `json:"name"`
+	}
+
+	tmpDir := t.TempDir()
+	gen := NewGenerator()
+
+	_, err := gen.Generate(GenerateConfig{
+		ScanDir:        "testdata",
+		OutputDir:      tmpDir,
+		ModuleName:     "custom_annotations",
+		OutputFile:     "custom_annotations.gen.lua",
+		TealOutputFile: "custom_annotations.d.tl",
+		Types:          []interface{}{Settings{}},
+	})
+	if err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+
+	content, err := os.ReadFile(filepath.Join(tmpDir, "custom_annotations.d.tl"))
+	if err != nil {
+		t.Fatalf("Failed to read Teal declarations: %v", err)
+	}
+
+	expectedStrings := []string{
+		"global record stubgen\n   record Settings\n      name: string\n   end\n",
+		"local record custom_annotations\n",
+		"   process_id: function(id: ID): boolean\n",
+		"return custom_annotations\n",
+	}
+
+	for _, expected := range expectedStrings {
+		if !strings.Contains(string(content), expected) {
+			t.Errorf("Expected Teal declarations to contain %q, got:\n%s", expected, content)
+		}
+	}
+}
@@ -0,0 +1,118 @@
+// Copyright (c) 2024-2025 Thomas Maurice
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package lint
+
+import (
+	"strings"
+
+	lua "github.com/yuin/gopher-lua"
+)
+
+// Loader: loads the lint module
+//
+// @luamodule lint
+func Loader(L *lua.LState) int {
+	mod := L.SetFuncs(L.NewTable(), exports)
+
+	mt := L.NewTypeMetatable("lint.Buffer")
+	L.SetField(mt, "__index", L.SetFuncs(L.NewTable(), bufferMethods()))
+
+	L.SetField(mod, "shout", L.NewFunction(func(L *lua.LState) int {
+		return upper(L, "!")
+	}))
+	L.SetField(mod, "anonymous", L.NewFunction(func(L *lua.LState) int {
+		L.Push(lua.LString("anonymous"))
+		return 1
+	}))
+
+	L.Push(mod)
+	return 1
+}
+
+var exports = map[string]lua.LGFunction{
+	"upper":    lower,
+	"trim":     trim,
+	"to_lower": lower,
+}
+
+// bufferMethods: returns the methods of lint.Buffer
+func bufferMethods() map[string]lua.LGFunction {
+	return map[string]lua.LGFunction{
+		"len":   bufferLen,
+		"reset": bufferReset,
+	}
+}
+
+// lower: lowercases a string
+//
+// @luafunc to_lower
+// @luaparam str string The string
+// @luareturn string result The lowercased string
+func lower(L *lua.LState) int {
+	L.Push(lua.LString(strings.ToLower(L.CheckString(1))))
+	return 1
+}
+
+// trim: not annotated
+func trim(L *lua.LState) int {
+	L.Push(lua.LString(strings.TrimSpace(L.CheckString(1))))
+	return 1
+}
+
+// upper: uppercases a string and appends a suffix
+//
+// @luafunc shout
+// @luaparam str string The string
+// @luareturn string result The uppercased string
+func upper(L *lua.LState, suffix string) int {
+	L.Push(lua.LString(strings.ToUpper(L.CheckString(1)) + suffix))
+	return 1
+}
+
+// reverse: documented but never exported
+//
+// @luafunc reverse
+// @luaparam str string The string
+// @luareturn string result The reversed string
+func reverse(L *lua.LState) int {
+	runes := []rune(L.CheckString(1))
+	for i, j := 0, len(runes)-1; i < j; i, j = i+1, j-1 {
+		runes[i], runes[j] = runes[j], runes[i]
+	}
+	L.Push(lua.LString(string(runes)))
+	return 1
+}
+
+// bufferLen: returns the length of the buffer
+//
+// @luamethod lint.Buffer len
+// @luareturn integer n The length
+func bufferLen(L *lua.LState) int {
+	L.Push(lua.LNumber(0))
+	return 1
+}
+
+// bufferReset: empties the buffer
+//
+// @luamethod lint.Buffer clear
+func bufferReset(L *lua.LState) int {
+	return 0
+}
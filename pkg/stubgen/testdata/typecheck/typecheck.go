@@ -0,0 +1,145 @@
+// Copyright (c) 2024-2025 Thomas Maurice
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package typecheck
+
+import (
+	"fmt"
+
+	lua "github.com/yuin/gopher-lua"
+)
+
+// Loader: loads the typecheck module
+//
+// @luamodule typecheck
+func Loader(L *lua.LState) int {
+	mod := L.SetFuncs(L.NewTable(), map[string]lua.LGFunction{
+		"concat":    concat,
+		"repeat":    repeat,
+		"lookup":    lookup,
+		"count":     count,
+		"split":     split,
+		"variadic":  variadic,
+		"undefined": undefined,
+	})
+	L.Push(mod)
+	return 1
+}
+
+// concat: annotations match the implementation
+//
+// @luafunc concat
+// @luaparam a string The first string
+// @luaparam b string|nil The optional second string
+// @luareturn string result The concatenation
+func concat(L *lua.LState) int {
+	a := L.CheckString(1)
+	b := L.OptString(2, "")
+	L.Push(lua.LString(a + b))
+	return 1
+}
+
+// repeat: documents a string where a number is read
+//
+// @luafunc repeat
+// @luaparam str string The string to repeat
+// @luaparam times string How many times
+// @luareturn string result The repeated string
+func repeat(L *lua.LState) int {
+	str := L.CheckString(1)
+	times := L.CheckInt(2)
+	result := ""
+	for i := 0; i < times; i++ {
+		result += str
+	}
+	L.Push(lua.LString(result))
+	return 1
+}
+
+// lookup: reads an undocumented argument
+//
+// @luafunc lookup
+// @luaparam tbl table The table
+// @luareturn any value The value
+func lookup(L *lua.LState) int {
+	tbl := L.CheckTable(1)
+	key := L.CheckString(2)
+	L.Push(tbl.RawGetString(key))
+	return 1
+}
+
+// count: documents more parameters and returns than it uses
+//
+// @luafunc count
+// @luaparam tbl table The table
+// @luaparam filter function The filter
+// @luareturn integer n The count
+// @luareturn string|nil err The error
+func count(L *lua.LState) int {
+	tbl := L.CheckTable(1)
+	L.Push(lua.LNumber(tbl.Len()))
+	return 1
+}
+
+// split: returns a count that does not match its pushes on one path
+//
+// @luafunc split
+// @luaparam str string The string
+// @luareturn table parts The parts
+// @luareturn string|nil err The error
+func split(L *lua.LState) int {
+	str := L.CheckString(1)
+	if str == "" {
+		L.Push(lua.LNil)
+		return 2
+	}
+	L.Push(L.NewTable())
+	L.Push(lua.LNil)
+	return 2
+}
+
+// variadic: reads a variable number of arguments
+//
+// @luafunc variadic
+// @luaparam first string The first value
+// @luaparam rest string The other values
+// @luareturn string result The values joined
+func variadic(L *lua.LState) int {
+	result := ""
+	for i := 1; i <= L.GetTop(); i++ {
+		result += L.CheckString(i)
+	}
+	L.Push(lua.LString(result))
+	return 1
+}
+
+// undefined: raises an error on invalid input
+//
+// @luafunc undefined
+// @luaparam value number The value
+// @luareturn number result The value
+func undefined(L *lua.LState) int {
+	value := L.CheckNumber(1)
+	if value < 0 {
+		L.RaiseError("%s", fmt.Sprintf("negative value %v", value))
+	}
+	L.Push(value)
+	return 1
+}
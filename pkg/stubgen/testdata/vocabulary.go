@@ -0,0 +1,109 @@
+// Copyright (c) 2024-2025 Thomas Maurice
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+package testdata
+
+import lua "github.com/yuin/gopher-lua"
+
+// LoadVocabularyModule: loads the vocabulary module
+//
+// @luamodule vocabulary
+func LoadVocabularyModule(L *lua.LState) int {
+	mod := L.NewTable()
+
+	L.SetField(mod, "get", L.NewFunction(vocabularyGet))
+	L.SetField(mod, "first", L.NewFunction(vocabularyFirst))
+	L.SetField(mod, "join", L.NewFunction(vocabularyJoin))
+	L.SetField(mod, "old_join", L.NewFunction(vocabularyOldJoin))
+
+	L.Push(mod)
+	return 1
+}
+
+// @luaalias vocabulary.Key string|number Key of a stored value
+// @luaalias vocabulary.Visitor fun(key:vocabulary.Key,value:any):boolean
+
+// @luaenum vocabulary.Level Severity of a message
+// @luavalue DEBUG "debug" Verbose output
+// @luavalue INFO "info"
+// @luavalue ERROR "error" Failures only
+
+// @luaclass vocabulary.Store
+// @luafield size integer Number of stored values
+
+// vocabularyGet: returns the value stored under a key
+//
+// @luafunc get
+// @luaparam key vocabulary.Key The key to look up
+// @luaparam default? any Value returned when the key is missing
+// @luareturn any value The stored value
+// @luaoverload fun(key: vocabulary.Key): any
+// @luasee vocabulary.first
+func vocabularyGet(L *lua.LState) int {
+	L.CheckAny(1)
+	L.Push(L.OptString(2, ""))
+	return 1
+}
+
+// vocabularyFirst: returns the first element of a list
+//
+// @luafunc first
+// @luageneric T
+// @luaparam list T[] The list
+// @luareturn T|nil value The first element
+func vocabularyFirst(L *lua.LState) int {
+	L.Push(L.CheckTable(1).RawGetInt(1))
+	return 1
+}
+
+// vocabularyJoin: joins strings with a separator
+//
+// @luafunc join
+// @luaparam sep string The separator
+// @luaparam ... string The strings to join
+// @luareturn string result The joined string
+// @luasee vocabulary.Store:keys
+func vocabularyJoin(L *lua.LState) int {
+	L.Push(lua.LString(L.CheckString(1)))
+	return 1
+}
+
+// vocabularyOldJoin: joins strings with a separator
+//
+// @luafunc old_join
+// @luadeprecated Use vocabulary.join instead
+// @luaparam sep string The separator
+// @luaparam ... string The strings to join
+// @luareturn string result The joined string
+func vocabularyOldJoin(L *lua.LState) int {
+	return vocabularyJoin(L)
+}
+
+// storeKeys: lists the keys of a store
+//
+// @luamethod vocabulary.Store keys
+// @luageneric K vocabulary.Key
+// @luaparam self vocabulary.Store
+// @luaparam filter? fun(key:K):boolean Only keep keys for which filter returns true
+// @luareturn K[] keys The matching keys
+// @luadeprecated
+func storeKeys(L *lua.LState) int {
+	L.Push(L.NewTable())
+	return 1
+}
@@ -0,0 +1,641 @@
+// Copyright (c) 2024-2025 Thomas Maurice
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package stubgen
+
+import (
+	"fmt"
+	"go/ast"
+	"go/constant"
+	"go/importer"
+	"go/token"
+	"go/types"
+	pathpkg "path"
+	"strings"
+)
+
+// gopherLuaPath: import path of the gopher-lua package
+const gopherLuaPath = "github.com/yuin/gopher-lua"
+
+// Diagnostic: a problem found in the Go source of a Lua module
+type Diagnostic struct {
+	Pos     token.Position
+	Message string
+}
+
+// String: formats the diagnostic as file:line:column: message
+func (d Diagnostic) String() string {
+	return fmt.Sprintf("%s: %s", d.Pos, d.Message)
+}
+
+// argumentReaders: LState methods reading a function argument, with the Lua type they expect
+var argumentReaders = map[string]string{
+	"CheckAny":      "any",
+	"CheckBool":     "boolean",
+	"CheckChannel":  "channel",
+	"CheckFunction": "function",
+	"CheckInt":      "integer",
+	"CheckInt64":    "integer",
+	"CheckNumber":   "number",
+	"CheckOption":   "string",
+	"CheckString":   "string",
+	"CheckTable":    "table",
+	"CheckThread":   "thread",
+	"CheckUserData": "userdata",
+	"OptBool":       "boolean",
+	"OptChannel":    "channel",
+	"OptFunction":   "function",
+	"OptInt":        "integer",
+	"OptInt64":      "integer",
+	"OptNumber":     "number",
+	"OptString":     "string",
+	"OptTable":      "table",
+	"OptUserData":   "userdata",
+	// Lenient readers accept any value
+	"Get":        "any",
+	"ToBool":     "any",
+	"ToFunction": "any",
+	"ToInt":      "any",
+	"ToInt64":    "any",
+	"ToNumber":   "any",
+	"ToString":   "any",
+	"ToTable":    "any",
+	"ToUserData": "any",
+}
+
+// luaBuiltinTypes: LuaLS builtin type names that can be compared with inferred types
+var luaBuiltinTypes = map[string]bool{
+	"any": true, "boolean": true, "channel": true, "function": true, "integer": true,
+	"lightuserdata": true, "nil": true, "number": true, "string": true, "table": true,
+	"thread": true, "userdata": true,
+}
+
+// argumentRead: an argument read by a Lua function implementation
+type argumentRead struct {
+	method  string // LState method used, e.g. CheckString
+	luaType string // Lua type expected by the method
+}
+
+// returnSite: a return statement of a Lua function implementation
+type returnSite struct {
+	pos    token.Pos
+	count  int // Constant number of returned values, -1 if not constant
+	pushes int // Values pushed on the path to the return, -1 if unknown
+}
+
+// signatureInfo: what a Lua function implementation actually does with the stack
+type signatureInfo struct {
+	reads   map[int]argumentRead
+	dynamic bool // Arguments are accessed in ways that cannot be counted
+	returns []returnSite
+}
+
+// CheckSignatures: type-checks the Go packages under dir and cross-checks every @luafunc
+// and @luamethod against its implementation. The arguments read with L.Check*/L.Opt* are
+// compared with the @luaparam annotations (count and type), and the values returned are
+// compared with the L.Push calls and the @luareturn annotations.
+// Returns the mismatches found, sorted by position.
+func (a *Analyzer) CheckSignatures(dir string) ([]Diagnostic, error) {
+	return a.checkSignatures(func(fn func(path string) error) error {
+		return walkDirectory(dir, fn)
+	})
+}
+
+// CheckSignaturesPatterns: like CheckSignatures, for the Go files matched by package patterns
+// and not excluded (see ScanPatterns)
+func (a *Analyzer) CheckSignaturesPatterns(patterns, excludes []string) ([]Diagnostic, error) {
+	return a.checkSignatures(func(fn func(path string) error) error {
+		return walkPatterns(patterns, excludes, fn)
+	})
+}
+
+// checkSignatures: type-checks the packages of the files found by walk
+func (a *Analyzer) checkSignatures(walk func(fn func(path string) error) error) ([]Diagnostic, error) {
+	fset := token.NewFileSet()
+
+	// Go files grouped by directory and package name
+	keys, packageFiles, err := parsePackages(fset, walk)
+	if err != nil {
+		return nil, err
+	}
+
+	imp := newLenientImporter(fset)
+
+	var diagnostics []Diagnostic
+	for _, key := range keys {
+		files := packageFiles[key]
+		info := &types.Info{
+			Types: make(map[ast.Expr]types.TypeAndValue),
+			Defs:  make(map[*ast.Ident]types.Object),
+			Uses:  make(map[*ast.Ident]types.Object),
+		}
+
+		conf := types.Config{
+			Importer: imp,
+			// Only gopher-lua is resolved, other errors are expected
+			Error: func(error) {},
+		}
+		_, _ = conf.Check(files[0].Name.Name, fset, files, info)
+
+		for _, file := range files {
+			diagnostics = append(diagnostics, a.checkFile(fset, info, file)...)
+		}
+	}
+
+	sortDiagnostics(diagnostics)
+
+	return diagnostics, nil
+}
+
+// lenientImporter: imports gopher-lua from source and every other package as an empty
+// package, which is enough to recognize *lua.LState without compiling all dependencies
+type lenientImporter struct {
+	source   types.ImporterFrom
+	packages map[string]*types.Package
+}
+
+// newLenientImporter: creates a new lenientImporter
+func newLenientImporter(fset *token.FileSet) *lenientImporter {
+	return &lenientImporter{
+		source:   importer.ForCompiler(fset, "source", nil).(types.ImporterFrom),
+		packages: make(map[string]*types.Package),
+	}
+}
+
+// Import: implements types.Importer
+func (i *lenientImporter) Import(path string) (*types.Package, error) {
+	return i.ImportFrom(path, "", 0)
+}
+
+// ImportFrom: implements types.ImporterFrom
+func (i *lenientImporter) ImportFrom(path, dir string, mode types.ImportMode) (*types.Package, error) {
+	if path == gopherLuaPath {
+		if pkg, err := i.source.ImportFrom(path, dir, mode); err == nil {
+			return pkg, nil
+		}
+	}
+
+	if pkg, exists := i.packages[path]; exists {
+		return pkg, nil
+	}
+
+	pkg := types.NewPackage(path, pathpkg.Base(path))
+	pkg.MarkComplete()
+	i.packages[path] = pkg
+	return pkg, nil
+}
+
+// checkFile: cross-checks the annotated functions of a single file
+func (a *Analyzer) checkFile(fset *token.FileSet, info *types.Info, file *ast.File) []Diagnostic {
+	var (
+		diagnostics []Diagnostic
+		moduleName  string
+	)
+
+	for _, decl := range file.Decls {
+		funcDecl, ok := decl.(*ast.FuncDecl)
+		if !ok || funcDecl.Doc == nil || funcDecl.Body == nil {
+			continue
+		}
+
+		comment := funcDecl.Doc.Text()
+
+		if name := a.extractModuleName(comment); name != "" {
+			moduleName = name
+			continue
+		}
+
+		var (
+			name    string
+			params  []*LuaParam
+			returns []*LuaReturn
+			offset  int // Stack index of the first documented parameter minus one
+		)
+
+		if fn := a.extractFunction(comment); fn != nil {
+			name = fn.Name
+			if moduleName != "" {
+				name = moduleName + "." + fn.Name
+			}
+			params, returns = fn.Params, fn.Returns
+		} else if method := a.extractMethod(comment); method != nil {
+			name = method.className + ":" + method.method.Name
+			params, returns = method.method.Params, method.method.Returns
+			// The receiver is always the first argument, documented or not
+			if len(params) == 0 || params[0].Name != "self" {
+				offset = 1
+			}
+		} else {
+			continue
+		}
+
+		sig := inspectSignature(info, funcDecl.Body)
+		report := func(pos token.Pos, format string, args ...interface{}) {
+			diagnostics = append(diagnostics, Diagnostic{
+				Pos:     fset.Position(pos),
+				Message: name + ": " + fmt.Sprintf(format, args...),
+			})
+		}
+
+		checkParams(sig, params, offset, funcDecl.Name.Pos(), report)
+		checkReturns(sig, returns, funcDecl.Name.Pos(), report)
+	}
+
+	return diagnostics
+}
+
+// checkParams: compares the arguments read by an implementation with its @luaparam annotations
+func checkParams(sig *signatureInfo, params []*LuaParam, offset int, pos token.Pos, report func(token.Pos, string, ...interface{})) {
+	documented := len(params) + offset
+
+	// A trailing vararg accepts any number of arguments, including none
+	vararg := len(params) > 0 && params[len(params)-1].Vararg
+	if vararg {
+		documented--
+	}
+
+	maxIndex := 0
+	for index := range sig.reads {
+		maxIndex = max(maxIndex, index)
+	}
+
+	if maxIndex > documented && !vararg {
+		report(pos, "reads argument %d but only %d parameter(s) are documented with @luaparam", maxIndex, documented)
+	} else if maxIndex < documented && !sig.dynamic {
+		report(pos, "documents %d parameter(s) with @luaparam but only reads %d argument(s)", documented, maxIndex)
+	}
+
+	for index := 1; index <= maxIndex; index++ {
+		read, ok := sig.reads[index]
+		if !ok || index <= offset {
+			continue
+		}
+
+		position := min(index-offset, len(params))
+		if index-offset > len(params) && !vararg {
+			continue
+		}
+
+		param := params[position-1]
+		if !luaTypeAccepts(param.Type, read.luaType) {
+			report(pos, "argument %d (%s) is read with L.%s but documented as %s", index, param.Name, read.method, param.Type)
+		}
+	}
+}
+
+// checkReturns: compares the values returned by an implementation with its pushes and @luareturn annotations
+func checkReturns(sig *signatureInfo, returns []*LuaReturn, pos token.Pos, report func(token.Pos, string, ...interface{})) {
+	maxCount := -1
+	for _, site := range sig.returns {
+		if site.count < 0 {
+			continue
+		}
+		maxCount = max(maxCount, site.count)
+
+		if site.pushes >= 0 && site.pushes != site.count {
+			report(site.pos, "returns %d value(s) but pushes %d", site.count, site.pushes)
+		}
+	}
+
+	if maxCount >= 0 && maxCount != len(returns) {
+		report(pos, "returns up to %d value(s) but %d are documented with @luareturn", maxCount, len(returns))
+	}
+}
+
+// luaTypeAccepts: checks whether an annotated Lua type accepts values of an inferred type.
+// Class names and aliases cannot be resolved here, so they accept anything.
+func luaTypeAccepts(annotated, inferred string) bool {
+	if inferred == "any" {
+		return true
+	}
+
+	for _, alt := range strings.Split(annotated, "|") {
+		alt = strings.TrimSuffix(strings.TrimSpace(alt), "?")
+
+		switch {
+		case alt == "any" || alt == inferred:
+			return true
+		case alt == "nil":
+			continue
+		case strings.HasPrefix(alt, "fun("):
+			if inferred == "function" {
+				return true
+			}
+		case strings.HasPrefix(alt, "table<") || strings.HasPrefix(alt, "{") || strings.HasSuffix(alt, "[]"):
+			if inferred == "table" {
+				return true
+			}
+		case strings.HasPrefix(alt, `"`) || strings.HasPrefix(alt, "'"):
+			if inferred == "string" {
+				return true
+			}
+		case alt == "true" || alt == "false":
+			if inferred == "boolean" {
+				return true
+			}
+		case alt == "number" || alt == "integer":
+			if inferred == "number" || inferred == "integer" {
+				return true
+			}
+		case alt == "lightuserdata":
+			if inferred == "userdata" {
+				return true
+			}
+		case !luaBuiltinTypes[alt]:
+			return true
+		}
+	}
+
+	return false
+}
+
+// inspectSignature: collects the argument reads and return sites of a function body
+func inspectSignature(info *types.Info, body *ast.BlockStmt) *signatureInfo {
+	sig := &signatureInfo{reads: make(map[int]argumentRead)}
+
+	ast.Inspect(body, func(n ast.Node) bool {
+		// Closures have their own stack
+		if _, ok := n.(*ast.FuncLit); ok {
+			return false
+		}
+
+		call, ok := n.(*ast.CallExpr)
+		if !ok {
+			return true
+		}
+
+		if method, ok := lstateMethod(info, call); ok {
+			if method == "GetTop" {
+				sig.dynamic = true
+			}
+
+			luaType, isReader := argumentReaders[method]
+			if !isReader || len(call.Args) == 0 {
+				return true
+			}
+
+			index, ok := constantInt(info, call.Args[0])
+			if !ok {
+				sig.dynamic = true
+				return true
+			}
+
+			if _, seen := sig.reads[index]; index > 0 && (!seen || sig.reads[index].luaType == "any") {
+				sig.reads[index] = argumentRead{method: method, luaType: luaType}
+			}
+			return true
+		}
+
+		// Helpers receiving the state may read arguments themselves
+		if passesLState(info, call) {
+			sig.dynamic = true
+		}
+
+		return true
+	})
+
+	w := &stackWalker{info: info, sig: sig}
+	w.walkBlock(body.List, 0)
+
+	return sig
+}
+
+// stackWalker: follows the statements of a function body, counting L.Push calls on each path
+type stackWalker struct {
+	info *types.Info
+	sig  *signatureInfo
+}
+
+// walkBlock: walks a statement list starting with a known push count (-1 if unknown).
+// Returns the push count at the end of the list and whether the list always terminates.
+func (w *stackWalker) walkBlock(stmts []ast.Stmt, pushes int) (int, bool) {
+	for _, stmt := range stmts {
+		var terminated bool
+		pushes, terminated = w.walkStmt(stmt, pushes)
+		if terminated {
+			return pushes, true
+		}
+	}
+	return pushes, false
+}
+
+// walkStmt: walks a single statement, see walkBlock
+func (w *stackWalker) walkStmt(stmt ast.Stmt, pushes int) (int, bool) {
+	switch s := stmt.(type) {
+	case *ast.ExprStmt:
+		call, ok := s.X.(*ast.CallExpr)
+		if !ok {
+			return pushes, false
+		}
+
+		method, isLState := lstateMethod(w.info, call)
+		switch {
+		case isLState && method == "Push":
+			if pushes >= 0 {
+				pushes++
+			}
+		case isLState && (method == "RaiseError" || method == "ArgError" || method == "TypeError"):
+			return pushes, true
+		case isPanic(call):
+			return pushes, true
+		case passesLState(w.info, call):
+			// Helpers receiving the state may push values
+			pushes = -1
+		}
+		return pushes, false
+
+	case *ast.ReturnStmt:
+		site := returnSite{pos: s.Pos(), count: -1, pushes: pushes}
+		if len(s.Results) == 1 {
+			if count, ok := constantInt(w.info, s.Results[0]); ok {
+				site.count = count
+			}
+		}
+		w.sig.returns = append(w.sig.returns, site)
+		return pushes, true
+
+	case *ast.BlockStmt:
+		return w.walkBlock(s.List, pushes)
+
+	case *ast.LabeledStmt:
+		return w.walkStmt(s.Stmt, pushes)
+
+	case *ast.IfStmt:
+		var branches []ast.Stmt
+		branches = append(branches, s.Body)
+		if s.Else != nil {
+			branches = append(branches, s.Else)
+		}
+		return w.walkBranches(branches, s.Else == nil, pushes)
+
+	case *ast.SwitchStmt:
+		return w.walkClauses(s.Body, pushes)
+
+	case *ast.TypeSwitchStmt:
+		return w.walkClauses(s.Body, pushes)
+
+	case *ast.SelectStmt:
+		return w.walkClauses(s.Body, pushes)
+
+	case *ast.ForStmt:
+		return w.walkLoop(s.Body, pushes), false
+
+	case *ast.RangeStmt:
+		return w.walkLoop(s.Body, pushes), false
+	}
+
+	return pushes, false
+}
+
+// walkBranches: walks alternative branches and merges their push counts.
+// fallsThrough tells whether control can skip all the branches.
+func (w *stackWalker) walkBranches(branches []ast.Stmt, fallsThrough bool, pushes int) (int, bool) {
+	var outcomes []int
+	if fallsThrough {
+		outcomes = append(outcomes, pushes)
+	}
+
+	for _, branch := range branches {
+		result, terminated := w.walkStmt(branch, pushes)
+		if !terminated {
+			outcomes = append(outcomes, result)
+		}
+	}
+
+	if len(outcomes) == 0 {
+		return pushes, true
+	}
+
+	for _, outcome := range outcomes[1:] {
+		if outcome != outcomes[0] {
+			return -1, false
+		}
+	}
+	return outcomes[0], false
+}
+
+// walkClauses: walks the clauses of a switch or select statement
+func (w *stackWalker) walkClauses(body *ast.BlockStmt, pushes int) (int, bool) {
+	var (
+		branches   []ast.Stmt
+		hasDefault bool
+	)
+
+	for _, stmt := range body.List {
+		switch clause := stmt.(type) {
+		case *ast.CaseClause:
+			hasDefault = hasDefault || clause.List == nil
+			branches = append(branches, &ast.BlockStmt{List: clause.Body})
+		case *ast.CommClause:
+			hasDefault = hasDefault || clause.Comm == nil
+			branches = append(branches, &ast.BlockStmt{List: clause.Body})
+		}
+	}
+
+	return w.walkBranches(branches, !hasDefault, pushes)
+}
+
+// walkLoop: walks a loop body, pushes inside a loop make the count unknown
+func (w *stackWalker) walkLoop(body *ast.BlockStmt, pushes int) int {
+	start := pushes
+	if w.containsPush(body) {
+		start = -1
+	}
+
+	result, _ := w.walkBlock(body.List, start)
+	if result != pushes {
+		return -1
+	}
+	return pushes
+}
+
+// containsPush: checks whether a node contains a L.Push call outside closures
+func (w *stackWalker) containsPush(node ast.Node) bool {
+	found := false
+	ast.Inspect(node, func(n ast.Node) bool {
+		if _, ok := n.(*ast.FuncLit); ok || found {
+			return false
+		}
+		if call, ok := n.(*ast.CallExpr); ok {
+			if method, ok := lstateMethod(w.info, call); ok && method == "Push" {
+				found = true
+			}
+		}
+		return true
+	})
+	return found
+}
+
+// lstateMethod: returns the name of the *lua.LState method called, if any
+func lstateMethod(info *types.Info, call *ast.CallExpr) (string, bool) {
+	sel, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok {
+		return "", false
+	}
+
+	if !isLState(info.TypeOf(sel.X)) {
+		return "", false
+	}
+
+	return sel.Sel.Name, true
+}
+
+// passesLState: checks whether a call (other than a LState method) receives a *lua.LState
+func passesLState(info *types.Info, call *ast.CallExpr) bool {
+	for _, arg := range call.Args {
+		if isLState(info.TypeOf(arg)) {
+			return true
+		}
+	}
+	return false
+}
+
+// isLState: checks whether a type is *lua.LState
+func isLState(t types.Type) bool {
+	ptr, ok := t.(*types.Pointer)
+	if !ok {
+		return false
+	}
+
+	named, ok := ptr.Elem().(*types.Named)
+	if !ok {
+		return false
+	}
+
+	obj := named.Obj()
+	return obj.Name() == "LState" && obj.Pkg() != nil && obj.Pkg().Path() == gopherLuaPath
+}
+
+// isPanic: checks whether a call is the panic builtin
+func isPanic(call *ast.CallExpr) bool {
+	ident, ok := call.Fun.(*ast.Ident)
+	return ok && ident.Name == "panic"
+}
+
+// constantInt: returns the value of a constant integer expression
+func constantInt(info *types.Info, expr ast.Expr) (int, bool) {
+	tv, ok := info.Types[expr]
+	if !ok || tv.Value == nil || tv.Value.Kind() != constant.Int {
+		return 0, false
+	}
+
+	value, exact := constant.Int64Val(tv.Value)
+	return int(value), exact
+}
@@ -0,0 +1,113 @@
+// Copyright (c) 2024-2025 Thomas Maurice
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package stubgen
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestAnalyzer_CheckSignatures(t *testing.T) {
+	a := NewAnalyzer()
+	diagnostics, err := a.CheckSignatures("testdata/typecheck")
+	if err != nil {
+		t.Fatalf("CheckSignatures failed: %v", err)
+	}
+
+	expected := []struct {
+		line    int
+		message string
+	}{
+		{65, "typecheck.repeat: argument 2 (times) is read with L.CheckInt but documented as string"},
+		{81, "typecheck.lookup: reads argument 2 but only 1 parameter(s) are documented with @luaparam"},
+		{95, "typecheck.count: documents 2 parameter(s) with @luaparam but only reads 1 argument(s)"},
+		{95, "typecheck.count: returns up to 1 value(s) but 2 are documented with @luareturn"},
+		{111, "typecheck.split: returns 2 value(s) but pushes 1"},
+	}
+
+	if len(diagnostics) != len(expected) {
+		for _, d := range diagnostics {
+			t.Log(d)
+		}
+		t.Fatalf("Expected %d diagnostics, got %d", len(expected), len(diagnostics))
+	}
+
+	for i, exp := range expected {
+		d := diagnostics[i]
+		if filepath.Base(d.Pos.Filename) != "typecheck.go" || d.Pos.Line != exp.line {
+			t.Errorf("Diagnostic %d: expected typecheck.go:%d, got %s:%d", i, exp.line, d.Pos.Filename, d.Pos.Line)
+		}
+		if d.Message != exp.message {
+			t.Errorf("Diagnostic %d: expected %q, got %q", i, exp.message, d.Message)
+		}
+	}
+
+	if !strings.HasSuffix(diagnostics[0].String(), "typecheck.go:65:6: "+expected[0].message) {
+		t.Errorf("Unexpected diagnostic format: %s", diagnostics[0])
+	}
+}
+
+func TestAnalyzer_CheckSignaturesModules(t *testing.T) {
+	// The bundled modules must be consistent with their annotations
+	a := NewAnalyzer()
+	diagnostics, err := a.CheckSignatures("../modules")
+	if err != nil {
+		t.Fatalf("CheckSignatures failed: %v", err)
+	}
+
+	for _, d := range diagnostics {
+		t.Errorf("Unexpected diagnostic: %s", d)
+	}
+}
+
+func TestLuaTypeAccepts(t *testing.T) {
+	tests := []struct {
+		annotated string
+		inferred  string
+		expected  bool
+	}{
+		{"string", "string", true},
+		{"string", "number", false},
+		{"number", "integer", true},
+		{"integer", "number", true},
+		{"string|nil", "string", true},
+		{"string?", "string", true},
+		{"table<string, any>", "table", true},
+		{"string[]", "table", true},
+		{"{ name: string }", "table", true},
+		{"fun(x: number): boolean", "function", true},
+		{"function", "table", false},
+		{`"a"|"b"`, "string", true},
+		{"corev1.Pod", "table", true},
+		{"any", "userdata", true},
+		{"boolean", "any", true},
+		{"nil", "string", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.annotated+"/"+tt.inferred, func(t *testing.T) {
+			if result := luaTypeAccepts(tt.annotated, tt.inferred); result != tt.expected {
+				t.Errorf("luaTypeAccepts(%q, %q) = %v, expected %v", tt.annotated, tt.inferred, result, tt.expected)
+			}
+		})
+	}
+}
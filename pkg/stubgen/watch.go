@@ -0,0 +1,239 @@
+// Copyright (c) 2024-2025 Thomas Maurice
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+package stubgen
+
+import (
+	"context"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ModuleChange: how a module differs between two scans
+type ModuleChange struct {
+	Module  string
+	Kind    string   // "added", "removed" or "changed"
+	Added   []string // Functions and Class:method methods, sorted
+	Removed []string
+}
+
+// String: formats the change as "module: +added -removed"
+func (c ModuleChange) String() string {
+	parts := []string{c.Module + ":"}
+
+	switch {
+	case c.Kind == "added":
+		parts = append(parts, "new module")
+	case c.Kind == "removed":
+		parts = append(parts, "module removed")
+	case len(c.Added) == 0 && len(c.Removed) == 0:
+		parts = append(parts, "annotations changed")
+	}
+
+	for _, name := range c.Added {
+		parts = append(parts, "+"+name)
+	}
+	for _, name := range c.Removed {
+		parts = append(parts, "-"+name)
+	}
+
+	return strings.Join(parts, " ")
+}
+
+// DiffModules: compares the modules of two analyzers, previous being nil before the first scan.
+// A module is changed when its generated stub differs; its added and removed functions and
+// methods are listed. Returns the changes sorted by module name.
+func DiffModules(previous, current *Analyzer) []ModuleChange {
+	oldModules := make(map[string]*LuaModule)
+	if previous != nil {
+		oldModules = previous.modules
+	}
+	newModules := current.modules
+
+	var changes []ModuleChange
+	for _, name := range unionKeys(oldModules, newModules) {
+		oldModule, newModule := oldModules[name], newModules[name]
+
+		switch {
+		case oldModule == nil:
+			changes = append(changes, ModuleChange{Module: name, Kind: "added", Added: moduleMembers(newModule)})
+		case newModule == nil:
+			changes = append(changes, ModuleChange{Module: name, Kind: "removed", Removed: moduleMembers(oldModule)})
+		default:
+			oldStub, _ := previous.GenerateModuleStub(name)
+			newStub, _ := current.GenerateModuleStub(name)
+			if oldStub == newStub {
+				continue
+			}
+
+			added, removed := diffMembers(moduleMembers(oldModule), moduleMembers(newModule))
+			changes = append(changes, ModuleChange{Module: name, Kind: "changed", Added: added, Removed: removed})
+		}
+	}
+
+	return changes
+}
+
+// moduleMembers: returns the functions and Class:method methods of a module, sorted
+func moduleMembers(module *LuaModule) []string {
+	var members []string
+	for _, fn := range module.Functions {
+		members = append(members, fn.Name)
+	}
+	for _, class := range module.Classes {
+		for _, method := range class.Methods {
+			members = append(members, class.Name+":"+method.Name)
+		}
+	}
+	sort.Strings(members)
+	return members
+}
+
+// diffMembers: returns the members only in current, and those only in previous
+func diffMembers(previous, current []string) ([]string, []string) {
+	inPrevious := make(map[string]bool)
+	for _, name := range previous {
+		inPrevious[name] = true
+	}
+	inCurrent := make(map[string]bool)
+	for _, name := range current {
+		inCurrent[name] = true
+	}
+
+	var added, removed []string
+	for _, name := range current {
+		if !inPrevious[name] {
+			added = append(added, name)
+		}
+	}
+	for _, name := range previous {
+		if !inCurrent[name] {
+			removed = append(removed, name)
+		}
+	}
+
+	return added, removed
+}
+
+// unionKeys: returns the keys of both maps, sorted
+func unionKeys(a, b map[string]*LuaModule) []string {
+	var keys []string
+	for key := range a {
+		keys = append(keys, key)
+	}
+	for key := range b {
+		if _, ok := a[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// fileStamp: what changes when a file is edited
+type fileStamp struct {
+	modTime time.Time
+	size    int64
+}
+
+// Watcher: polls the Go files matched by package patterns (see ScanPatterns) for changes.
+// Polling needs no platform support and sees files created in new subdirectories.
+type Watcher struct {
+	patterns []string
+	excludes []string
+	stamps   map[string]fileStamp
+}
+
+// NewWatcher: creates a watcher, recording the current state of the files
+func NewWatcher(patterns, excludes []string) (*Watcher, error) {
+	w := &Watcher{patterns: patterns, excludes: excludes}
+
+	stamps, err := w.stat()
+	if err != nil {
+		return nil, err
+	}
+	w.stamps = stamps
+
+	return w, nil
+}
+
+// Poll: returns the files created, modified or removed since the previous poll, sorted
+func (w *Watcher) Poll() ([]string, error) {
+	stamps, err := w.stat()
+	if err != nil {
+		return nil, err
+	}
+
+	var changed []string
+	for path, stamp := range stamps {
+		if previous, ok := w.stamps[path]; !ok || previous != stamp {
+			changed = append(changed, path)
+		}
+	}
+	for path := range w.stamps {
+		if _, ok := stamps[path]; !ok {
+			changed = append(changed, path)
+		}
+	}
+	sort.Strings(changed)
+
+	w.stamps = stamps
+	return changed, nil
+}
+
+// Run: polls every interval and calls fn with the changed files, until ctx is done.
+// Returns the error of a failed poll, or nil once ctx is done.
+func (w *Watcher) Run(ctx context.Context, interval time.Duration, fn func(changed []string)) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			changed, err := w.Poll()
+			if err != nil {
+				return err
+			}
+			if len(changed) > 0 {
+				fn(changed)
+			}
+		}
+	}
+}
+
+// stat: returns the stamps of the watched files
+func (w *Watcher) stat() (map[string]fileStamp, error) {
+	stamps := make(map[string]fileStamp)
+
+	err := walkPatterns(w.patterns, w.excludes, func(path string) error {
+		info, err := os.Stat(path)
+		if err != nil {
+			// Removed while walking, reported by the next poll
+			return nil
+		}
+		stamps[path] = fileStamp{modTime: info.ModTime(), size: info.Size()}
+		return nil
+	})
+
+	return stamps, err
+}
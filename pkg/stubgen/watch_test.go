@@ -0,0 +1,224 @@
+// Copyright (c) 2024-2025 Thomas Maurice
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+package stubgen
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// scanSource: scans a single file with the given content
+func scanSource(t *testing.T, code string) *Analyzer {
+	t.Helper()
+
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "module.go"), []byte(code), 0644); err != nil {
+		t.Fatalf("Failed to write test file: %v", err)
+	}
+
+	a := NewAnalyzer()
+	if _, err := a.ScanDirectory(dir); err != nil {
+		t.Fatalf("ScanDirectory failed: %v", err)
+	}
+	return a
+}
+
+func TestDiffModules(t *testing.T) {
+	previous := scanSource(t, `package test
+
+// @luamodule alpha
+func Loader(L *lua.LState) int { return 1 }
+
+// @luafunc keep
+// @luaparam s string The input
+func keep(L *lua.LState) int { return 0 }
+
+// @luafunc old
+func old(L *lua.LState) int { return 0 }
+
+// @luamethod alpha.Conn close
+func connClose(L *lua.LState) int { return 0 }
+
+// @luamodule unchanged
+func Loader2(L *lua.LState) int { return 1 }
+
+// @luamodule gone
+func Loader3(L *lua.LState) int { return 1 }
+`)
+
+	current := scanSource(t, `package test
+
+// @luamodule alpha
+func Loader(L *lua.LState) int { return 1 }
+
+// @luafunc keep
+// @luaparam s string The input
+func keep(L *lua.LState) int { return 0 }
+
+// @luafunc new
+func new(L *lua.LState) int { return 0 }
+
+// @luamethod alpha.Conn ping
+func connPing(L *lua.LState) int { return 0 }
+
+// @luamodule unchanged
+func Loader2(L *lua.LState) int { return 1 }
+
+// @luamodule beta
+func Loader3(L *lua.LState) int { return 1 }
+
+// @luafunc run
+func run(L *lua.LState) int { return 0 }
+`)
+
+	var got []string
+	for _, change := range DiffModules(previous, current) {
+		got = append(got, change.String())
+	}
+
+	expected := []string{
+		"alpha: +alpha.Conn:ping +new -alpha.Conn:close -old",
+		"beta: new module +run",
+		"gone: module removed",
+	}
+	if strings.Join(got, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("Expected changes:\n%s\n\nGot:\n%s", strings.Join(expected, "\n"), strings.Join(got, "\n"))
+	}
+
+	// A documentation change is reported without members
+	edited := scanSource(t, `package test
+
+// @luamodule unchanged
+func Loader2(L *lua.LState) int { return 1 }
+
+// @luafunc extra
+// @luaparam n integer A number
+func extra(L *lua.LState) int { return 0 }
+`)
+	again := scanSource(t, `package test
+
+// @luamodule unchanged
+func Loader2(L *lua.LState) int { return 1 }
+
+// @luafunc extra
+// @luaparam n number A number
+func extra(L *lua.LState) int { return 0 }
+`)
+
+	changes := DiffModules(edited, again)
+	if len(changes) != 1 || changes[0].String() != "unchanged: annotations changed" {
+		t.Errorf("Expected an annotation change, got %v", changes)
+	}
+
+	if changes := DiffModules(nil, again); len(changes) != 1 || changes[0].Kind != "added" {
+		t.Errorf("Expected every module to be added before the first scan, got %v", changes)
+	}
+}
+
+func TestWatcher(t *testing.T) {
+	root := t.TempDir()
+	write := func(name, content string) {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+
+	write("a/a.go", "package a\n")
+	write("b/b.go", "package b\n")
+
+	w, err := NewWatcher([]string{root + "/..."}, []string{"ignored"})
+	if err != nil {
+		t.Fatalf("NewWatcher failed: %v", err)
+	}
+
+	if changed, err := w.Poll(); err != nil || len(changed) != 0 {
+		t.Fatalf("Expected no changes, got %v, %v", changed, err)
+	}
+
+	write("a/a.go", "package a\n\nfunc f() {}\n")
+	write("c/new/c.go", "package c\n")
+	write("ignored/i.go", "package ignored\n")
+	write("a/notes.txt", "not Go")
+	if err := os.Remove(filepath.Join(root, "b/b.go")); err != nil {
+		t.Fatalf("Failed to remove file: %v", err)
+	}
+
+	changed, err := w.Poll()
+	if err != nil {
+		t.Fatalf("Poll failed: %v", err)
+	}
+
+	expected := []string{
+		filepath.Join(root, "a/a.go"),
+		filepath.Join(root, "b/b.go"),
+		filepath.Join(root, "c/new/c.go"),
+	}
+	if strings.Join(changed, ",") != strings.Join(expected, ",") {
+		t.Errorf("Expected changes %v, got %v", expected, changed)
+	}
+
+	if changed, err := w.Poll(); err != nil || len(changed) != 0 {
+		t.Errorf("Expected no changes after a poll, got %v, %v", changed, err)
+	}
+}
+
+func TestWatcherRun(t *testing.T) {
+	root := t.TempDir()
+	path := filepath.Join(root, "a.go")
+	if err := os.WriteFile(path, []byte("package a\n"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	w, err := NewWatcher([]string{root}, nil)
+	if err != nil {
+		t.Fatalf("NewWatcher failed: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var calls [][]string
+	done := make(chan error)
+	go func() {
+		done <- w.Run(ctx, 10*time.Millisecond, func(changed []string) {
+			calls = append(calls, changed)
+			cancel()
+		})
+	}()
+
+	if err := os.WriteFile(path, []byte("package a\n\nvar x = 1\n"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	if err := <-done; err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if len(calls) != 1 || len(calls[0]) != 1 || calls[0][0] != path {
+		t.Errorf("Expected one call with %s, got %v", path, calls)
+	}
+}